@@ -0,0 +1,631 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tomiwa-a/git-radar/internal/export"
+	"github.com/tomiwa-a/git-radar/internal/git"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// errUsage is returned after a command has printed its own usage message.
+var errUsage = errors.New("usage")
+
+const tableDate = "2006-01-02 15:04"
+
+// command is a scripting entry point run instead of the TUI when its name is
+// the first argument, e.g. git-radar log -n 20.
+type command struct {
+	name    string
+	args    string
+	summary string
+	formats []string               // accepted -format values, default first; table and json when empty
+	flags   func(fs *flag.FlagSet) // adds the command's own options
+	run     func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{
+		name:    "log",
+		args:    "[<rev>]",
+		summary: "list commits reachable from rev, HEAD by default",
+		flags: func(fs *flag.FlagSet) {
+			fs.Int("n", 0, "maximum number of commits (default radar.commitLimit, 100 unless configured)")
+			fs.String("author", "", "only commits by this author, after .mailmap")
+			fs.String("file", "", "only commits that changed this file or directory")
+		},
+		run: runLog,
+	},
+	{
+		name:    "show",
+		args:    "<rev>",
+		summary: "show one commit with its changed files",
+		run:     runShow,
+	},
+	{
+		name:    "compare",
+		args:    "<target> <source>",
+		summary: "show how source has diverged from target",
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("two-dot", false, "diff the two tips instead of merge base to source")
+		},
+		run: runCompare,
+	},
+	{
+		name:    "export-divergence",
+		args:    "<target> <source>",
+		summary: "write the divergence report as Markdown or self-contained HTML",
+		formats: []string{"markdown", "html"},
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("two-dot", false, "diff the two tips instead of merge base to source")
+			fs.String("o", "", "write to this file instead of stdout; .html picks HTML")
+		},
+		run: runExportDivergence,
+	},
+	{
+		name:    "export-graph",
+		args:    "[<rev>]",
+		summary: "draw the commit graph with branch and tag labels as SVG or Graphviz DOT",
+		formats: []string{"svg", "dot"},
+		flags: func(fs *flag.FlagSet) {
+			fs.Int("n", 0, "maximum number of commits (default radar.commitLimit, 100 unless configured)")
+			fs.String("o", "", "write to this file instead of stdout; .dot or .gv picks DOT")
+		},
+		run: runExportGraph,
+	},
+	{
+		name:    "branches",
+		summary: "list branches with upstream and ahead/behind counts",
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("a", false, "include remote-tracking branches")
+			fs.Bool("tags", false, "include tags")
+		},
+		run: runBranches,
+	},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// cli carries what every command needs once its flags are parsed.
+type cli struct {
+	service *git.Service
+	fs      *flag.FlagSet
+	format  string
+	out     io.Writer
+}
+
+// runCommand parses the command's flags, which may come before or after its
+// arguments, opens the repository and runs it.
+func runCommand(cmd *command, args []string, repoPath string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("git-radar "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	formats := cmd.formats
+	if len(formats) == 0 {
+		formats = []string{"table", "json"}
+	}
+	path := fs.String("path", repoPath, "path to git repository")
+	format := fs.String("format", formats[0], "output format: "+strings.Join(formats, " or "))
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(errOut, "usage: git-radar %s [options] %s\n\n%s\n\noptions:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return errUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if !slices.Contains(formats, *format) {
+		fmt.Fprintf(errOut, "unknown format %q: use %s\n", *format, strings.Join(formats, " or "))
+		return errUsage
+	}
+	if want := len(strings.Fields(cmd.args)); len(positional) > want ||
+		(len(positional) < want && !strings.HasPrefix(cmd.args, "[")) {
+		fs.Usage()
+		return errUsage
+	}
+
+	service, err := git.NewService(*path)
+	if err != nil {
+		return err
+	}
+	// Bad settings and unreadable keys fall back to defaults, so just warn
+	for _, err := range []error{service.ConfigError(), service.SignersError()} {
+		if err == nil {
+			continue
+		}
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(errOut, "git-radar %s: warning: %s\n", cmd.name, line)
+		}
+	}
+	return cmd.run(&cli{service: service, fs: fs, format: *format, out: out}, positional)
+}
+
+// flagSet reports whether the option was given on the command line.
+func (c *cli) flagSet(name string) bool {
+	set := false
+	c.fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+func (c *cli) stringFlag(name string) string {
+	return c.fs.Lookup(name).Value.String()
+}
+
+func (c *cli) boolFlag(name string) bool {
+	return c.fs.Lookup(name).Value.String() == "true"
+}
+
+func (c *cli) intFlag(name string) int {
+	return c.fs.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
+func (c *cli) writeJSON(v any) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+}
+
+// commitJSON is the stable shape commits are printed in, independent of the
+// fields the TUI keeps for layout.
+type commitJSON struct {
+	Hash           string        `json:"hash"`
+	ShortHash      string        `json:"short_hash"`
+	Subject        string        `json:"subject"`
+	Body           string        `json:"body,omitempty"`
+	Author         string        `json:"author"`
+	AuthorEmail    string        `json:"author_email"`
+	AuthorDate     time.Time     `json:"author_date"`
+	Committer      string        `json:"committer"`
+	CommitterEmail string        `json:"committer_email"`
+	CommitDate     time.Time     `json:"commit_date"`
+	Parents        []string      `json:"parents"`
+	Branches       []string      `json:"branches,omitempty"`
+	Trailers       []trailerJSON `json:"trailers,omitempty"`
+	Signature      string        `json:"signature"`
+	Signer         string        `json:"signer,omitempty"`
+	Files          []fileJSON    `json:"files,omitempty"`
+}
+
+type trailerJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type fileJSON struct {
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+func toCommitJSON(c types.GraphCommit) commitJSON {
+	out := commitJSON{
+		Hash:           c.FullHash,
+		ShortHash:      c.Hash,
+		Subject:        c.Message,
+		Body:           c.Body,
+		Author:         c.Author,
+		AuthorEmail:    c.AuthorEmail,
+		AuthorDate:     c.Date,
+		Committer:      c.Committer,
+		CommitterEmail: c.CommitterEmail,
+		CommitDate:     c.CommitDate,
+		Parents:        append([]string{}, c.Parents...),
+		Branches:       c.Branches,
+		Signature:      c.Signature.String(),
+		Signer:         c.Signer,
+		Files:          toFilesJSON(c.Files),
+	}
+	for _, t := range c.Trailers {
+		out.Trailers = append(out.Trailers, trailerJSON{Key: t.Key, Value: t.Value})
+	}
+	return out
+}
+
+func toCommitsJSON(commits []types.GraphCommit) []commitJSON {
+	out := []commitJSON{}
+	for _, c := range commits {
+		out = append(out, toCommitJSON(c))
+	}
+	return out
+}
+
+func toFilesJSON(files []types.FileChange) []fileJSON {
+	var out []fileJSON
+	for _, f := range files {
+		out = append(out, fileJSON{Path: f.Path, Status: f.Status, Additions: f.Additions, Deletions: f.Deletions})
+	}
+	return out
+}
+
+// commits loads up to -n commits, or the configured commit limit, reachable
+// from the optional revision.
+func (c *cli) commits(args []string, filter types.CommitFilter) ([]types.GraphCommit, error) {
+	rev := ""
+	if len(args) > 0 {
+		rev = args[0]
+	}
+	limit := c.service.Config().CommitLimit
+	if c.flagSet("n") {
+		limit = c.intFlag("n")
+	}
+	commits, err := c.service.GetCommits(rev, limit, filter)
+	if err != nil && !errors.Is(err, git.ErrNoCommits) {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// verify checks the signatures the commit listing left unverified.
+func (c *cli) verify(commits []types.GraphCommit) error {
+	for i := range commits {
+		if commits[i].Signature != types.Unverified {
+			continue
+		}
+		var err error
+		if commits[i].Signature, commits[i].Signer, err = c.service.VerifyCommit(commits[i].FullHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// create opens the -o file, or returns stdout when there is none. The
+// format follows the file's extension unless -format was given.
+func (c *cli) create(formatFor func(string) string) (io.WriteCloser, string, error) {
+	path := c.stringFlag("o")
+	if path == "" {
+		return nopCloser{c.out}, c.format, nil
+	}
+	format := c.format
+	if !c.flagSet("format") {
+		format = formatFor(path)
+	}
+	f, err := os.Create(path)
+	return f, format, err
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func runLog(c *cli, args []string) error {
+	commits, err := c.commits(args, types.CommitFilter{Author: c.stringFlag("author"), Path: c.stringFlag("file")})
+	if err != nil {
+		return err
+	}
+
+	if c.format == "json" {
+		if err := c.verify(commits); err != nil {
+			return err
+		}
+		return c.writeJSON(toCommitsJSON(commits))
+	}
+	w := c.table()
+	fmt.Fprintln(w, "HASH\tDATE\tAUTHOR\tSUBJECT")
+	for _, commit := range commits {
+		subject := commit.Message
+		if len(commit.Branches) > 0 {
+			subject = "(" + strings.Join(commit.Branches, ", ") + ") " + subject
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", commit.Hash, commit.Date.Format(tableDate), commit.Author, subject)
+	}
+	return w.Flush()
+}
+
+func runShow(c *cli, args []string) error {
+	commit, err := c.commit(args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.format == "json" {
+		return c.writeJSON(toCommitJSON(commit))
+	}
+	field := func(label, value string) {
+		fmt.Fprintf(c.out, "%-12s%s\n", label, value)
+	}
+	field("commit", commit.FullHash)
+	if len(commit.Parents) > 0 {
+		field("Parents", strings.Join(commit.Parents, " "))
+	}
+	if len(commit.Branches) > 0 {
+		field("Branches", strings.Join(commit.Branches, ", "))
+	}
+	field("Author", fmt.Sprintf("%s <%s>", commit.Author, commit.AuthorEmail))
+	field("AuthorDate", commit.Date.Format(time.RFC3339))
+	field("Commit", fmt.Sprintf("%s <%s>", commit.Committer, commit.CommitterEmail))
+	field("CommitDate", commit.CommitDate.Format(time.RFC3339))
+	if commit.Signature != types.Unsigned {
+		field("Signature", strings.TrimSpace(commit.Signature.String()+" "+commit.Signer))
+	}
+	fmt.Fprintf(c.out, "\n    %s\n", commit.Message)
+	if commit.Body != "" {
+		fmt.Fprintf(c.out, "\n    %s\n", strings.ReplaceAll(commit.Body, "\n", "\n    "))
+	}
+	if len(commit.Files) > 0 {
+		fmt.Fprintln(c.out)
+		return c.writeFiles(commit.Files)
+	}
+	return nil
+}
+
+// commit loads one commit by revision, with its files and merge parents.
+func (c *cli) commit(rev string) (types.GraphCommit, error) {
+	hash, err := c.service.ResolveRevision(rev)
+	if err != nil {
+		return types.GraphCommit{}, err
+	}
+	commits, err := c.service.GetCommits(hash, 1, types.CommitFilter{})
+	if err != nil {
+		return types.GraphCommit{}, err
+	}
+	if len(commits) == 0 {
+		return types.GraphCommit{}, fmt.Errorf("no commit found for %s", rev)
+	}
+	if err := c.verify(commits[:1]); err != nil {
+		return types.GraphCommit{}, err
+	}
+	commit := commits[0]
+	commit.ParentInfos, commit.Files, err = c.service.GetCommitDetails(hash)
+	return commit, err
+}
+
+func (c *cli) writeFiles(files []types.FileChange) error {
+	w := c.table()
+	fmt.Fprintln(w, "STATUS\tADDED\tDELETED\tPATH")
+	for _, f := range files {
+		fmt.Fprintf(w, "%s\t+%d\t-%d\t%s\n", f.Status, f.Additions, f.Deletions, f.Path)
+	}
+	return w.Flush()
+}
+
+// compareJSON mirrors the divergence screen. Incoming commits are on target
+// but not source, outgoing ones on source but not target.
+type compareJSON struct {
+	Target    string       `json:"target"`
+	Source    string       `json:"source"`
+	Range     string       `json:"range"`
+	MergeBase *commitJSON  `json:"merge_base"`
+	Incoming  []commitJSON `json:"incoming"`
+	Outgoing  []commitJSON `json:"outgoing"`
+	Files     []fileJSON   `json:"files"`
+	Additions int          `json:"additions"`
+	Deletions int          `json:"deletions"`
+	Conflicts []string     `json:"conflicts"` // changed differently on both sides
+}
+
+// divergence loads the comparison named by the command's arguments.
+func (c *cli) divergence(args []string) (*types.Divergence, error) {
+	mode := types.ThreeDotCompare
+	if c.boolFlag("two-dot") {
+		mode = types.TwoDotCompare
+	}
+	return c.service.Divergence(args[0], args[1], mode)
+}
+
+func runCompare(c *cli, args []string) error {
+	d, err := c.divergence(args)
+	if err != nil {
+		return err
+	}
+	if c.format == "json" {
+		if err := c.verify(d.Incoming); err != nil {
+			return err
+		}
+		if err := c.verify(d.Outgoing); err != nil {
+			return err
+		}
+	}
+
+	report := compareJSON{
+		Target:    d.Target,
+		Source:    d.Source,
+		Range:     d.Mode.Range(d.Target, d.Source),
+		Incoming:  toCommitsJSON(d.Incoming),
+		Outgoing:  toCommitsJSON(d.Outgoing),
+		Files:     toFilesJSON(d.Files),
+		Conflicts: append([]string{}, d.Conflicts...),
+	}
+	if d.MergeBase != nil {
+		base := toCommitJSON(*d.MergeBase)
+		report.MergeBase = &base
+	}
+	report.Additions, report.Deletions = d.Totals()
+
+	if c.format == "json" {
+		if report.Files == nil {
+			report.Files = []fileJSON{}
+		}
+		return c.writeJSON(report)
+	}
+	fmt.Fprintf(c.out, "Compare %s ← %s (%s)\n", d.Target, d.Source, report.Range)
+	if d.MergeBase != nil {
+		fmt.Fprintf(c.out, "Merge base  %s %s\n", d.MergeBase.Hash, d.MergeBase.Message)
+	} else {
+		fmt.Fprintln(c.out, "Merge base  none: the histories are unrelated")
+	}
+	fmt.Fprintf(c.out, "%d files changed, +%d -%d\n", len(d.Files), report.Additions, report.Deletions)
+	if len(d.Conflicts) > 0 {
+		fmt.Fprintf(c.out, "Potential conflicts in %d files: %s\n", len(d.Conflicts), strings.Join(d.Conflicts, ", "))
+	}
+
+	section := func(title string, commits []types.GraphCommit) {
+		fmt.Fprintf(c.out, "\n%s (%d)\n", title, len(commits))
+		w := c.table()
+		for _, commit := range commits {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", commit.Hash, commit.Date.Format(tableDate), commit.Author, commit.Message)
+		}
+		w.Flush()
+	}
+	section("Incoming", d.Incoming)
+	section("Outgoing", d.Outgoing)
+
+	if len(d.Files) > 0 {
+		fmt.Fprintln(c.out)
+		return c.writeFiles(d.Files)
+	}
+	return nil
+}
+
+func runExportDivergence(c *cli, args []string) error {
+	d, err := c.divergence(args)
+	if err != nil {
+		return err
+	}
+
+	w, format, err := c.create(export.DivergenceFormat)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteDivergence(w, d, format, time.Now()); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func runExportGraph(c *cli, args []string) error {
+	commits, err := c.commits(args, types.CommitFilter{})
+	if err != nil {
+		return err
+	}
+	branches, err := c.service.GetBranches()
+	if err != nil {
+		return err
+	}
+	tags, err := c.service.GetTags()
+	if err != nil {
+		return err
+	}
+
+	w, format, err := c.create(export.GraphFormat)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteGraph(w, commits, append(branches, tags...), format, c.service.Config().Theme); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+type branchJSON struct {
+	Name      string `json:"name"`
+	Ref       string `json:"ref"`
+	Hash      string `json:"hash"`
+	Kind      string `json:"kind"` // local, remote or tag
+	Head      bool   `json:"head,omitempty"`
+	Remote    string `json:"remote,omitempty"`
+	Upstream  string `json:"upstream,omitempty"`
+	Ahead     int    `json:"ahead,omitempty"`
+	Behind    int    `json:"behind,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+func runBranches(c *cli, args []string) error {
+	branches, err := c.service.GetBranches()
+	if err != nil {
+		return err
+	}
+	tracking, err := c.service.GetBranchTracking()
+	if err != nil {
+		return err
+	}
+	byRef := make(map[string]types.Branch)
+	for _, b := range tracking {
+		byRef[b.FullName] = b
+	}
+
+	out := []branchJSON{}
+	for _, b := range branches {
+		if b.IsRemote && !c.boolFlag("a") {
+			continue
+		}
+		kind := "local"
+		if b.IsRemote {
+			kind = "remote"
+		}
+		t := byRef[b.FullName]
+		out = append(out, branchJSON{
+			Name: b.Name, Ref: b.FullName, Hash: b.Hash, Kind: kind, Head: b.IsHead,
+			Remote: b.Remote, Upstream: t.Upstream, Ahead: t.Ahead, Behind: t.Behind,
+		})
+	}
+	if c.boolFlag("tags") {
+		tags, err := c.service.GetTags()
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			sig := ""
+			if t.Signature == types.Unverified {
+				if t.Signature, _, err = c.service.VerifyTag(t.FullName); err != nil {
+					return err
+				}
+			}
+			if t.Signature != types.Unsigned {
+				sig = t.Signature.String()
+			}
+			out = append(out, branchJSON{Name: t.Name, Ref: t.FullName, Hash: t.Hash, Kind: "tag", Signature: sig})
+		}
+	}
+
+	if c.format == "json" {
+		return c.writeJSON(out)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "\tNAME\tHASH\tUPSTREAM\tAHEAD\tBEHIND")
+	for _, b := range out {
+		mark := ""
+		if b.Head {
+			mark = "*"
+		}
+		name := b.Name
+		if b.Kind == "tag" {
+			name = "tag: " + name
+		}
+		upstream, ahead, behind := "", "", ""
+		if b.Upstream != "" {
+			upstream, ahead, behind = b.Upstream, fmt.Sprint(b.Ahead), fmt.Sprint(b.Behind)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", mark, name, b.Hash[:7], upstream, ahead, behind)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// fixtureRepo makes master with three commits and a feature branch from the
+// first one that changes the same file, all with fixed authors and dates so
+// hashes and output are stable.
+func fixtureRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone := time.FixedZone("", 3600)
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, zone)
+	commit := func(name, email, message string, files map[string]string) plumbing.Hash {
+		t.Helper()
+		for path, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := wt.Add(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		at = at.Add(time.Hour)
+		sig := &object.Signature{Name: name, Email: email, When: at}
+		hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	root := commit("Ada", "ada@example.com", "Add readme\n", map[string]string{"README.md": "radar\n"})
+	commit("Ada", "ada@example.com", "Add main\n\nWire up the entry point.\n", map[string]string{"main.go": "package main\n"})
+	commit("Grace", "grace@example.com", "Describe usage\n", map[string]string{"README.md": "radar\n\nusage\n"})
+
+	feature := plumbing.NewBranchReferenceName("feature")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(feature, root)); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: feature}); err != nil {
+		t.Fatal(err)
+	}
+	commit("Grace", "grace@example.com", "Rename project\n", map[string]string{"README.md": "git-radar\n"})
+	commit("Grace", "grace@example.com", "Add license\n", map[string]string{"LICENSE": "MIT\n"})
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// golden compares got with testdata/name, rewriting it under -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func TestCommandsGolden(t *testing.T) {
+	dir := fixtureRepo(t)
+
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"log.txt", []string{"log"}},
+		{"log.json", []string{"log", "-format", "json"}},
+		{"log_feature.txt", []string{"log", "-n", "2", "feature"}},
+		{"log_author.txt", []string{"log", "-author", "Grace"}},
+		{"show.txt", []string{"show", "HEAD~1"}},
+		{"show.json", []string{"show", "-format", "json", "HEAD~1"}},
+		{"compare.txt", []string{"compare", "master", "feature"}},
+		{"compare.json", []string{"compare", "-format", "json", "master", "feature"}},
+		{"compare_two_dot.txt", []string{"compare", "master", "feature", "-two-dot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			cmd := findCommand(tt.args[0])
+			var out, errOut bytes.Buffer
+			if err := runCommand(cmd, tt.args[1:], dir, &out, &errOut); err != nil {
+				t.Fatalf("%s: %v\n%s", strings.Join(tt.args, " "), err, errOut.String())
+			}
+			golden(t, tt.golden, out.Bytes())
+		})
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	dir := fixtureRepo(t)
+	unborn := t.TempDir()
+	if _, err := git.PlainInit(unborn, false); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		args    []string
+		wantErr string
+	}{
+		{"show unknown revision", dir, []string{"show", "nope"}, "nope"},
+		{"show on an unborn branch", unborn, []string{"show", "HEAD"}, "HEAD"},
+		{"compare unknown branch", dir, []string{"compare", "master", "nope"}, "nope"},
+		{"too many arguments", dir, []string{"show", "HEAD", "HEAD~1"}, errUsage.Error()},
+		{"unknown format", dir, []string{"log", "-format", "yaml"}, errUsage.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			err := runCommand(findCommand(tt.args[0]), tt.args[1:], tt.path, &out, &errOut)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s = %v, want an error containing %q", strings.Join(tt.args, " "), err, tt.wantErr)
+			}
+		})
+	}
+
+	// An empty repository lists no commits rather than failing
+	var out bytes.Buffer
+	if err := runCommand(findCommand("log"), nil, unborn, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("log in an empty repository = %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "HASH  DATE  AUTHOR  SUBJECT" {
+		t.Errorf("log in an empty repository = %q, want only the header", got)
+	}
+}
+
+func TestCommandBadConfig(t *testing.T) {
+	dir := fixtureRepo(t)
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("[radar]\n\tcommitLimit = 0\n\ttheme = nope\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The defaults stand in for the bad settings
+	var out, errOut bytes.Buffer
+	if err := runCommand(findCommand("log"), nil, dir, &out, &errOut); err != nil {
+		t.Fatalf("log with bad settings = %v", err)
+	}
+	golden(t, "log.txt", out.Bytes())
+	for _, want := range []string{
+		"git-radar log: warning: repository git config: radar.commitLimit = \"0\": must be at least 1\n",
+		"git-radar log: warning: repository git config: radar.theme = \"nope\": ",
+	} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("stderr = %q, want it to contain %q", errOut.String(), want)
+		}
+	}
+}
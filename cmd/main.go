@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"text/tabwriter"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tomiwa-a/git-radar/internal/ui"
@@ -23,6 +25,7 @@ func main() {
 	flag.BoolVar(&showVersion, "version", false, "Show version")
 	flag.BoolVar(&showVersion, "v", false, "Show version")
 	flag.BoolVar(&doInstall, "install", false, "Install git-radar to PATH")
+	flag.Usage = usage
 	flag.Parse()
 
 	if showVersion {
@@ -39,6 +42,16 @@ func main() {
 	}
 
 	if flag.NArg() > 0 {
+		if cmd := findCommand(flag.Arg(0)); cmd != nil {
+			if err := runCommand(cmd, flag.Args()[1:], repoPath, os.Stdout, os.Stderr); err != nil {
+				if errors.Is(err, errUsage) {
+					os.Exit(2)
+				}
+				fmt.Fprintf(os.Stderr, "git-radar %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
 		repoPath = flag.Arg(0)
 	}
 
@@ -51,6 +64,19 @@ func main() {
 	}
 }
 
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: git-radar [options] [<path>]\n       git-radar [options] <command> [<args>]\n\n")
+	fmt.Fprintf(out, "Without a command, opens the interactive view of the repository at path.\n\ncommands:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", c.name, c.args, c.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nRun git-radar <command> -h for a command's options.\n\noptions:\n")
+	flag.PrintDefaults()
+}
+
 func installSelf() error {
 	execPath, err := os.Executable()
 	if err != nil {
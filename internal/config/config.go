@@ -0,0 +1,224 @@
+// Package config holds the user's settings: a file in the git-radar config
+// directory, overridden by the [radar] section of the global git config and
+// then by that of the repository.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	gitconfig "github.com/go-git/go-git/v6/config"
+	format "github.com/go-git/go-git/v6/plumbing/format/config"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+)
+
+// Section is the config section git-radar reads, in its own file and in
+// git's.
+const Section = "radar"
+
+// Config is every setting, with the defaults filled in for those not set.
+type Config struct {
+	CommitLimit   int           // commits loaded into the graph at a time
+	DetailsDelay  time.Duration // pause on a commit before its details load
+	WatchInterval time.Duration // how often the repository is checked for outside changes
+	ChurnWindow   string        // default window of the hotspot report
+	Theme         theme.Theme   // colours of the TUI
+	SyntaxStyle   string        // chroma style for highlighted code, the theme's unless set
+	DiffCollapse  int           // unchanged runs longer than this are folded; 0 never folds
+	DiffContext   int           // unchanged lines kept either side of a fold
+	Keyring       string        // OpenPGP keyring signatures are checked against
+}
+
+// Default returns the settings used when nothing is configured.
+func Default() Config {
+	return Config{
+		CommitLimit:   100,
+		DetailsDelay:  200 * time.Millisecond,
+		WatchInterval: 2 * time.Second,
+		ChurnWindow:   "3 months ago",
+		Theme:         theme.Dark,
+		SyntaxStyle:   theme.Dark.SyntaxStyle,
+		DiffCollapse:  8,
+		DiffContext:   3,
+	}
+}
+
+// setting parses one key's value into a Config.
+type setting struct {
+	key   string
+	parse func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"commitLimit", func(c *Config, v string) error { return parseInt(v, 1, &c.CommitLimit) }},
+	{"detailsDelay", func(c *Config, v string) error { return parseDuration(v, 0, &c.DetailsDelay) }},
+	{"watchInterval", func(c *Config, v string) error { return parseDuration(v, 100*time.Millisecond, &c.WatchInterval) }},
+	{"churnWindow", func(c *Config, v string) error { return parseString(v, &c.ChurnWindow) }},
+	{"theme", func(c *Config, v string) error {
+		dir, err := Dir()
+		if err != nil {
+			return err
+		}
+		t, err := theme.Load(v, filepath.Join(dir, "themes"))
+		if err != nil {
+			return err
+		}
+		c.Theme = t
+		return nil
+	}},
+	{"syntaxStyle", func(c *Config, v string) error {
+		if _, ok := styles.Registry[v]; !ok {
+			return errors.New("not a chroma style, such as dracula, monokai or github")
+		}
+		c.SyntaxStyle = v
+		return nil
+	}},
+	{"diffCollapse", func(c *Config, v string) error { return parseInt(v, 0, &c.DiffCollapse) }},
+	{"diffContext", func(c *Config, v string) error { return parseInt(v, 0, &c.DiffContext) }},
+	{"keyring", func(c *Config, v string) error { return parseString(v, &c.Keyring) }},
+}
+
+// Dir is the user's config directory: git-radar under $XDG_CONFIG_HOME, or
+// under ~/.config when that is not set. It holds the config file and a
+// themes directory.
+func Dir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "git-radar"), nil
+}
+
+// Path is the user's config file in Dir.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config"), nil
+}
+
+// Load reads the user's config file, then the global git config, then
+// repo, the repository's own config, each overriding the one before. repo
+// may be nil. A missing file is not an error. A malformed file, an unknown
+// key or a bad value is reported, naming where it was found, but the rest
+// still load: the returned settings are always usable, with anything bad
+// left at its earlier value.
+func Load(repo *format.Config) (Config, error) {
+	c := Default()
+	c.SyntaxStyle = "" // follows the theme unless set
+
+	var errs []error
+	if path, err := Path(); err == nil {
+		if file, err := readFile(path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		} else {
+			errs = append(errs, c.apply(file, path)...)
+		}
+	}
+	if global, err := gitconfig.LoadConfig(gitconfig.GlobalScope); err == nil {
+		errs = append(errs, c.apply(global.Raw, "global git config")...)
+	}
+	if repo != nil {
+		errs = append(errs, c.apply(repo, "repository git config")...)
+	}
+
+	if c.SyntaxStyle == "" {
+		c.SyntaxStyle = c.Theme.SyntaxStyle
+	}
+	if c.DiffCollapse > 0 && c.DiffCollapse < 2*c.DiffContext {
+		errs = append(errs, fmt.Errorf("%s.diffCollapse (%d) must be at least twice %s.diffContext (%d)", Section, c.DiffCollapse, Section, c.DiffContext))
+		d := Default()
+		c.DiffCollapse, c.DiffContext = d.DiffCollapse, d.DiffContext
+	}
+	return c, errors.Join(errs...)
+}
+
+func readFile(path string) (*format.Config, error) {
+	cfg := format.New()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := format.NewDecoder(f).Decode(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// apply sets the options in raw's [radar] section, source naming raw in
+// errors. Options that fail to parse are skipped.
+func (c *Config) apply(raw *format.Config, source string) []error {
+	if raw == nil || !raw.HasSection(Section) {
+		return nil
+	}
+	sec := raw.Section(Section)
+	var errs []error
+	if len(sec.Subsections) > 0 {
+		errs = append(errs, fmt.Errorf("%s: [%s %q]: %s takes no subsections", source, Section, sec.Subsections[0].Name, Section))
+	}
+
+	for _, opt := range sec.Options {
+		i := -1
+		for j, s := range settings {
+			if opt.IsKey(s.key) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %s.%s", source, Section, opt.Key))
+			continue
+		}
+		if err := settings[i].parse(c, strings.TrimSpace(opt.Value)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s.%s = %q: %w", source, Section, settings[i].key, opt.Value, err))
+		}
+	}
+	return errs
+}
+
+func parseInt(value string, least int, dst *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("not a whole number")
+	}
+	if n < least {
+		return fmt.Errorf("must be at least %d", least)
+	}
+	*dst = n
+	return nil
+}
+
+func parseDuration(value string, least time.Duration, dst *time.Duration) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.New("not a duration such as 200ms or 2s")
+	}
+	if d < least {
+		return fmt.Errorf("must be at least %s", least)
+	}
+	*dst = d
+	return nil
+}
+
+func parseString(value string, dst *string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	*dst = value
+	return nil
+}
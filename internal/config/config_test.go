@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	format "github.com/go-git/go-git/v6/plumbing/format/config"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string // the user's config file
+		repo    string // the repository's git config
+		want    func(c *Config)
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: func(c *Config) {},
+		},
+		{
+			name: "file settings",
+			file: "[radar]\n\tcommitLimit = 50\n\tdetailsDelay = 1s\n\twatchInterval = 500ms\n\tchurnWindow = 2 weeks ago\n\tkeyring = ~/keys.asc\n",
+			want: func(c *Config) {
+				c.CommitLimit = 50
+				c.DetailsDelay = time.Second
+				c.WatchInterval = 500 * time.Millisecond
+				c.ChurnWindow = "2 weeks ago"
+				c.Keyring = "~/keys.asc"
+			},
+		},
+		{
+			name: "repository overrides the file",
+			file: "[radar]\n\tcommitLimit = 50\n\tdiffContext = 2\n",
+			repo: "[radar]\n\tcommitLimit = 20\n",
+			want: func(c *Config) {
+				c.CommitLimit = 20
+				c.DiffContext = 2
+			},
+		},
+		{
+			name: "keys are case-insensitive",
+			file: "[RADAR]\n\tCommitLimit = 5\n",
+			want: func(c *Config) { c.CommitLimit = 5 },
+		},
+		{
+			name: "syntax style follows the theme",
+			file: "[radar]\n\ttheme = light\n",
+			want: func(c *Config) {
+				c.Theme = theme.Light
+				c.SyntaxStyle = theme.Light.SyntaxStyle
+			},
+		},
+		{
+			name: "syntax style set over the theme",
+			file: "[radar]\n\tsyntaxStyle = monokai\n",
+			repo: "[radar]\n\ttheme = high-contrast\n",
+			want: func(c *Config) {
+				c.Theme = theme.HighContrast
+				c.SyntaxStyle = "monokai"
+			},
+		},
+		{
+			name: "folding off allows any context",
+			file: "[radar]\n\tdiffCollapse = 0\n\tdiffContext = 10\n",
+			want: func(c *Config) {
+				c.DiffCollapse = 0
+				c.DiffContext = 10
+			},
+		},
+		{
+			name: "other sections are ignored",
+			repo: "[core]\n\tbare = false\n[user]\n\tname = Jane\n",
+			want: func(c *Config) {},
+		},
+		{
+			name:    "bad setting keeps its default",
+			file:    "[radar]\n\tcommitLimit = 0\n\tdetailsDelay = 1s\n",
+			want:    func(c *Config) { c.DetailsDelay = time.Second },
+			wantErr: "radar.commitLimit = \"0\": must be at least 1",
+		},
+		{
+			name:    "bad override keeps the earlier value",
+			file:    "[radar]\n\tcommitLimit = 50\n\ttheme = light\n",
+			repo:    "[radar]\n\tcommitLimit = none\n\ttheme = nope\n",
+			want:    func(c *Config) { c.CommitLimit, c.Theme, c.SyntaxStyle = 50, theme.Light, theme.Light.SyntaxStyle },
+			wantErr: "repository git config: radar.theme",
+		},
+		{
+			name:    "every bad setting is reported",
+			file:    "[radar]\n\tcommitLimt = 5\n",
+			repo:    "[radar]\n\tdiffContext = -1\n\tcommitLimit = 7\n",
+			want:    func(c *Config) { c.CommitLimit = 7 },
+			wantErr: "unknown setting radar.commitLimt\nrepository git config: radar.diffContext",
+		},
+		{
+			name:    "collapse shorter than context falls back to both defaults",
+			file:    "[radar]\n\tdiffCollapse = 4\n\tdiffContext = 3\n",
+			want:    func(c *Config) {},
+			wantErr: "must be at least twice",
+		},
+		{
+			name:    "malformed file is skipped",
+			file:    "[radar\n",
+			repo:    "[radar]\n\tcommitLimit = 20\n",
+			want:    func(c *Config) { c.CommitLimit = 20 },
+			wantErr: "config:",
+		},
+		{name: "limit too small", file: "[radar]\n\tcommitLimit = 0\n", wantErr: "radar.commitLimit = \"0\": must be at least 1"},
+		{name: "limit not a number", file: "[radar]\n\tcommitLimit = lots\n", wantErr: "not a whole number"},
+		{name: "bad duration", file: "[radar]\n\tdetailsDelay = soon\n", wantErr: "not a duration"},
+		{name: "interval too short", file: "[radar]\n\twatchInterval = 10ms\n", wantErr: "must be at least 100ms"},
+		{name: "empty window", file: "[radar]\n\tchurnWindow = \"\"\n", wantErr: "must not be empty"},
+		{name: "unknown syntax style", file: "[radar]\n\tsyntaxStyle = nope\n", wantErr: "not a chroma style"},
+		{name: "unknown theme", file: "[radar]\n\ttheme = nope\n", wantErr: "no such theme"},
+		{name: "unknown key", repo: "[radar]\n\tcommitLimt = 5\n", wantErr: "repository git config: unknown setting radar.commitLimt"},
+		{name: "subsection", file: "[radar \"main\"]\n\tcommitLimit = 5\n", wantErr: "takes no subsections"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+			if tt.file != "" {
+				dir, err := Dir()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "config"), []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var repo *format.Config
+			if tt.repo != "" {
+				repo = format.New()
+				if err := format.NewDecoder(strings.NewReader(tt.repo)).Decode(repo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Load(repo)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			// Whatever went wrong, the settings are usable
+			if tt.want == nil {
+				tt.want = func(c *Config) {}
+			}
+			want := Default()
+			tt.want(&want)
+			if got != want {
+				t.Errorf("Load() = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
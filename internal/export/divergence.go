@@ -0,0 +1,201 @@
+// Package export writes what the TUI shows to files that can be shared
+// outside the terminal.
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+const reportDate = "2006-01-02"
+
+// DivergenceFormat picks the report format from a file name: HTML for .html
+// and .htm, Markdown for anything else.
+func DivergenceFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	}
+	return "markdown"
+}
+
+// WriteDivergence writes d as a "markdown" or "html" report.
+func WriteDivergence(w io.Writer, d *types.Divergence, format string, generated time.Time) error {
+	switch format {
+	case "markdown":
+		_, err := io.WriteString(w, DivergenceMarkdown(d, generated))
+		return err
+	case "html":
+		return divergenceHTML.Execute(w, newDivergenceView(d, generated))
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// DivergenceMarkdown renders d for pasting into a pull request description.
+func DivergenceMarkdown(d *types.Divergence, generated time.Time) string {
+	v := newDivergenceView(d, generated)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Divergence: `%s` ← `%s`\n\n", d.Target, d.Source)
+	fmt.Fprintf(&b, "Compared `%s` (%s).\n\n", v.Range, v.ModeLabel)
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- **Incoming:** %d commits on `%s` not in `%s`\n", len(d.Incoming), d.Target, d.Source)
+	fmt.Fprintf(&b, "- **Outgoing:** %d commits on `%s` not in `%s`\n", len(d.Outgoing), d.Source, d.Target)
+	fmt.Fprintf(&b, "- **Changes:** %d files, +%d −%d\n", len(d.Files), v.Additions, v.Deletions)
+	if len(d.Conflicts) > 0 {
+		fmt.Fprintf(&b, "- **Potential conflicts:** %d files changed on both sides\n", len(d.Conflicts))
+	} else {
+		b.WriteString("- **Potential conflicts:** none detected\n")
+	}
+
+	b.WriteString("\n## Merge base\n\n")
+	if base := d.MergeBase; base != nil {
+		fmt.Fprintf(&b, "`%s` %s — %s, %s\n", base.Hash, markdownText(base.Message), markdownText(base.Author), base.Date.Format(reportDate))
+	} else {
+		b.WriteString("None: the histories are unrelated.\n")
+	}
+
+	commits := func(title string, list []types.GraphCommit) {
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", title, len(list))
+		if len(list) == 0 {
+			b.WriteString("None.\n")
+			return
+		}
+		b.WriteString("| Commit | Date | Author | Subject |\n| --- | --- | --- | --- |\n")
+		for _, c := range list {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", c.Hash, c.Date.Format(reportDate), markdownCell(c.Author), markdownCell(c.Message))
+		}
+	}
+	commits("Outgoing commits", d.Outgoing)
+	commits("Incoming commits", d.Incoming)
+
+	fmt.Fprintf(&b, "\n## Files (%d)\n\n", len(d.Files))
+	if len(d.Files) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Status | File | Added | Deleted |\n| --- | --- | ---: | ---: |\n")
+		for _, f := range d.Files {
+			fmt.Fprintf(&b, "| %s | `%s` | +%d | −%d |\n", f.Status, f.Path, f.Additions, f.Deletions)
+		}
+	}
+
+	if len(d.Conflicts) > 0 {
+		fmt.Fprintf(&b, "\n## Potential conflicts (%d)\n\n", len(d.Conflicts))
+		for _, f := range d.Conflicts {
+			fmt.Fprintf(&b, "- `%s`\n", f)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n_Generated by git-radar on %s._\n", generated.Format(reportDate))
+	return b.String()
+}
+
+// markdownText keeps commit text from being read as formatting.
+func markdownText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;").Replace(s)
+}
+
+// markdownCell is markdownText that is also safe inside a table row.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownText(s), "|", `\|`)
+}
+
+// divergenceView is a Divergence with the derived values both formats need.
+type divergenceView struct {
+	*types.Divergence
+	Range     string
+	ModeLabel string
+	Additions int
+	Deletions int
+	Generated string
+}
+
+func newDivergenceView(d *types.Divergence, generated time.Time) divergenceView {
+	v := divergenceView{
+		Divergence: d,
+		Range:      d.Mode.Range(d.Target, d.Source),
+		ModeLabel:  "three-dot: since merge base",
+		Generated:  generated.Format(reportDate),
+	}
+	if d.Mode == types.TwoDotCompare {
+		v.ModeLabel = "two-dot: tip to tip"
+	}
+	v.Additions, v.Deletions = d.Totals()
+	return v
+}
+
+var divergenceHTML = template.Must(template.New("divergence").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(reportDate) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Divergence: {{.Target}} ← {{.Source}}</title>
+<style>
+body { font: 14px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; max-width: 1000px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h2 { font-size: 1.2em; margin-top: 1.6em; }
+code, .hash { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 90%; }
+.hash { color: #9a6700; }
+.summary { display: flex; gap: 1em; flex-wrap: wrap; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: .6em 1em; min-width: 9em; }
+.card b { display: block; font-size: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .3em .6em; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+th { background: #f6f8fa; }
+.num { text-align: right; white-space: nowrap; }
+.add { color: #1a7f37; }
+.del { color: #cf222e; }
+.warn { color: #9a6700; }
+.dim, footer { color: #57606a; }
+</style>
+</head>
+<body>
+<h1>Divergence: <code>{{.Target}}</code> ← <code>{{.Source}}</code></h1>
+<p class="dim">Compared <code>{{.Range}}</code> ({{.ModeLabel}}).</p>
+
+<div class="summary">
+<div class="card"><b>{{len .Incoming}}</b>incoming on {{.Target}}</div>
+<div class="card"><b>{{len .Outgoing}}</b>outgoing on {{.Source}}</div>
+<div class="card"><b>{{len .Files}}</b>files <span class="add">+{{.Additions}}</span> <span class="del">−{{.Deletions}}</span></div>
+<div class="card{{if .Conflicts}} warn{{end}}"><b>{{len .Conflicts}}</b>potential conflicts</div>
+</div>
+
+<h2>Merge base</h2>
+{{with .MergeBase}}<p><span class="hash">{{.Hash}}</span> {{.Message}} <span class="dim">— {{.Author}}, {{date .Date}}</span></p>
+{{else}}<p class="dim">None: the histories are unrelated.</p>
+{{end}}
+{{define "commits"}}{{if .}}<table>
+<tr><th>Commit</th><th>Date</th><th>Author</th><th>Subject</th></tr>
+{{range .}}<tr><td class="hash">{{.Hash}}</td><td>{{date .Date}}</td><td>{{.Author}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{else}}<p class="dim">None.</p>
+{{end}}{{end}}
+<h2>Outgoing commits ({{len .Outgoing}})</h2>
+{{template "commits" .Outgoing}}
+<h2>Incoming commits ({{len .Incoming}})</h2>
+{{template "commits" .Incoming}}
+<h2>Files ({{len .Files}})</h2>
+{{if .Files}}<table>
+<tr><th>Status</th><th>File</th><th class="num">Added</th><th class="num">Deleted</th></tr>
+{{range .Files}}<tr><td>{{.Status}}</td><td><code>{{.Path}}</code></td><td class="num add">+{{.Additions}}</td><td class="num del">−{{.Deletions}}</td></tr>
+{{end}}</table>
+{{else}}<p class="dim">None.</p>
+{{end}}
+<h2>Potential conflicts ({{len .Conflicts}})</h2>
+{{if .Conflicts}}<ul>
+{{range .Conflicts}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{else}}<p class="dim">None detected.</p>
+{{end}}
+<footer><p>Generated by git-radar on {{.Generated}}.</p></footer>
+</body>
+</html>
+`))
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, rewriting it under -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// commit is a commit dated days after 2024-03-01.
+func commit(hash, author, message string, days int) types.GraphCommit {
+	return types.GraphCommit{
+		Hash:     hash[:7],
+		FullHash: hash,
+		Author:   author,
+		Message:  message,
+		Date:     time.Date(2024, 3, 1+days, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func testDivergence() *types.Divergence {
+	base := commit("1111111aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "Ada", "Initial commit", 0)
+	return &types.Divergence{
+		Target:    "main",
+		Source:    "feature/report",
+		Mode:      types.ThreeDotCompare,
+		MergeBase: &base,
+		Incoming: []types.GraphCommit{
+			commit("2222222bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "Grace", "Fix *bold* | pipes in `code`", 2),
+		},
+		Outgoing: []types.GraphCommit{
+			commit("3333333ccccccccccccccccccccccccccccccccc", "Linus <l@example.com>", "Add <script>alert(1)</script> export", 3),
+			commit("4444444ddddddddddddddddddddddddddddddddd", "Ada_L", "Rename report_writer", 1),
+		},
+		Files: []types.FileChange{
+			{Status: "M", Path: "export/report.go", Additions: 12, Deletions: 3},
+			{Status: "A", Path: "export/report_test.go", Additions: 40},
+			{Status: "D", Path: "old.go", Deletions: 7},
+		},
+		Conflicts: []string{"export/report.go"},
+	}
+}
+
+func TestWriteDivergence(t *testing.T) {
+	generated := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	unrelated := &types.Divergence{Target: "main", Source: "orphan", Mode: types.TwoDotCompare}
+
+	tests := []struct {
+		golden string
+		d      *types.Divergence
+		format string
+	}{
+		{"divergence.md", testDivergence(), "markdown"},
+		{"divergence.html", testDivergence(), "html"},
+		{"divergence_unrelated.md", unrelated, "markdown"},
+		{"divergence_unrelated.html", unrelated, "html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteDivergence(&b, tt.d, tt.format, generated); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.golden, b.Bytes())
+		})
+	}
+
+	if err := WriteDivergence(&bytes.Buffer{}, testDivergence(), "pdf", generated); err == nil {
+		t.Error("WriteDivergence() accepted an unknown format")
+	}
+}
+
+func TestDivergenceFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"report.html": "html",
+		"REPORT.HTM":  "html",
+		"report.md":   "markdown",
+		"report":      "markdown",
+	} {
+		if got := DivergenceFormat(path); got != want {
+			t.Errorf("DivergenceFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+const (
+	svgRowHeight = 28
+	svgLaneWidth = 16
+	svgMargin    = 16
+	svgCharWidth = 7 // average advance of the 12px font, to size the canvas
+)
+
+// GraphFormat picks the graph format from a file name: DOT for .dot and .gv,
+// SVG for anything else.
+func GraphFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return "dot"
+	}
+	return "svg"
+}
+
+// WriteGraph draws commits, newest first, as a "dot" or "svg" graph in the
+// colours of t. refs are the branches and tags to label commits with.
+func WriteGraph(w io.Writer, commits []types.GraphCommit, refs []types.Branch, format string, t theme.Theme) error {
+	switch format {
+	case "dot":
+		_, err := io.WriteString(w, GraphDOT(commits, refs, t))
+		return err
+	case "svg":
+		_, err := io.WriteString(w, GraphSVG(commits, refs, t))
+		return err
+	}
+	return fmt.Errorf("unknown graph format %q", format)
+}
+
+// palette is a theme's colours as the hex codes SVG and Graphviz take.
+type palette struct {
+	background, text, muted, hash, merge string
+	local, remote, tag                   string
+	lanes                                []string
+}
+
+func newPalette(t theme.Theme) palette {
+	p := palette{
+		background: hexColor(t.Background),
+		text:       hexColor(t.Text),
+		muted:      hexColor(t.Muted),
+		hash:       hexColor(t.Changed),
+		merge:      hexColor(t.Secondary),
+		local:      hexColor(t.Added),
+		remote:     hexColor(t.Info),
+		tag:        hexColor(t.Special),
+	}
+	for _, c := range t.Lanes() {
+		p.lanes = append(p.lanes, hexColor(c))
+	}
+	return p
+}
+
+func (p palette) lane(lane int) string {
+	return p.lanes[lane%len(p.lanes)]
+}
+
+// hexColor spells out a theme colour, which may be short hex or an ANSI
+// colour number, as six-digit hex.
+func hexColor(c lipgloss.Color) string {
+	s := string(c)
+	if strings.HasPrefix(s, "#") {
+		if len(s) == 4 {
+			return "#" + strings.Repeat(s[1:2], 2) + strings.Repeat(s[2:3], 2) + strings.Repeat(s[3:4], 2)
+		}
+		return s
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 255 {
+		return "#000000"
+	}
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		// 6×6×6 colour cube
+		n -= 16
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		return fmt.Sprintf("#%02X%02X%02X", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		// Greyscale ramp
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02X%02X%02X", v, v, v)
+	}
+}
+
+// ansiColors are the 16 basic colours, as xterm draws them.
+var ansiColors = [16]string{
+	"#000000", "#CD0000", "#00CD00", "#CDCD00", "#0000EE", "#CD00CD", "#00CDCD", "#E5E5E5",
+	"#7F7F7F", "#FF0000", "#00FF00", "#FFFF00", "#5C5CFF", "#FF00FF", "#00FFFF", "#FFFFFF",
+}
+
+// refLabel is a branch or tag name drawn next to a commit.
+type refLabel struct {
+	name  string
+	color string
+}
+
+func refLabels(refs []types.Branch, p palette) map[string][]refLabel {
+	labels := make(map[string][]refLabel)
+	for _, r := range refs {
+		l := refLabel{name: r.Name, color: p.local}
+		switch {
+		case strings.HasPrefix(r.FullName, "refs/tags/"):
+			l = refLabel{name: "tag: " + r.Name, color: p.tag}
+		case r.IsRemote:
+			l.color = p.remote
+		}
+		labels[r.Hash] = append(labels[r.Hash], l)
+	}
+	return labels
+}
+
+// GraphDOT renders the graph for Graphviz. Each node is pinned to its lane
+// and row, so neato -n draws the same layout as the SVG export, while dot
+// keeps lanes together through node groups.
+func GraphDOT(commits []types.GraphCommit, refs []types.Branch, t theme.Theme) string {
+	commits = utils.TopoOrder(commits)
+	edges, _ := utils.AssignLanes(commits)
+	p := newPalette(t)
+	labels := refLabels(refs, p)
+
+	var b strings.Builder
+	b.WriteString("digraph history {\n")
+	b.WriteString("\trankdir=TB;\n")
+	fmt.Fprintf(&b, "\tnode [shape=box, style=\"rounded,filled\", fillcolor=%q, fontcolor=%q, fontname=\"monospace\", fontsize=10];\n", p.background, p.text)
+	b.WriteString("\tedge [arrowhead=none, penwidth=2];\n\n")
+
+	for i, c := range commits {
+		shape := "box"
+		if c.IsMerge {
+			shape = "hexagon"
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q, shape=%s, color=%q, group=\"lane%d\", pos=\"%d,%d!\"];\n",
+			c.FullHash, c.Hash+"\n"+c.Message, shape, p.lane(c.Lane), c.Lane, c.Lane*svgLaneWidth*4, -i*svgRowHeight*2)
+		for j, l := range labels[c.FullHash] {
+			id := fmt.Sprintf("%s:ref%d", c.FullHash, j)
+			fmt.Fprintf(&b, "\t%q [label=%q, shape=note, style=filled, fillcolor=%q, fontcolor=%q];\n", id, l.name, l.color, p.background)
+			fmt.Fprintf(&b, "\t%q -> %q [style=dashed, penwidth=1, color=%q];\n", id, c.FullHash, l.color)
+			fmt.Fprintf(&b, "\t{ rank=same; %q; %q; }\n", id, c.FullHash)
+		}
+	}
+	b.WriteString("\n")
+
+	for _, e := range edges {
+		from := commits[e.From]
+		style := ""
+		if e.Merge {
+			style = ", style=dashed"
+		}
+		if e.To < 0 {
+			// The parent is outside the exported commits
+			id := fmt.Sprintf("%s:more%d", from.FullHash, e.Lane)
+			fmt.Fprintf(&b, "\t%q [label=\"…\", shape=plaintext, style=\"\"];\n", id)
+			fmt.Fprintf(&b, "\t%q -> %q [color=%q%s];\n", from.FullHash, id, p.lane(e.Lane), style)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [color=%q%s];\n", from.FullHash, commits[e.To].FullHash, p.lane(e.Lane), style)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GraphSVG renders the graph as a standalone SVG image: one row per commit
+// with its lane, ref labels, hash, subject, author and date.
+func GraphSVG(commits []types.GraphCommit, refs []types.Branch, t theme.Theme) string {
+	commits = utils.TopoOrder(commits)
+	edges, lanes := utils.AssignLanes(commits)
+	p := newPalette(t)
+	labels := refLabels(refs, p)
+
+	laneX := func(lane int) int { return svgMargin + lane*svgLaneWidth + svgLaneWidth/2 }
+	rowY := func(row int) int { return svgMargin + row*svgRowHeight + svgRowHeight/2 }
+	textX := svgMargin + lanes*svgLaneWidth + svgMargin
+
+	// Size the canvas for the longest row of text
+	textWidth := 0
+	for _, c := range commits {
+		w := utf8.RuneCountInString(c.Hash+c.Message+c.Author+c.Date.Format(reportDate)) + 6
+		for _, l := range labels[c.FullHash] {
+			w += utf8.RuneCountInString(l.name) + 2
+		}
+		textWidth = max(textWidth, w*svgCharWidth)
+	}
+	width := textX + textWidth + svgMargin
+	height := 2*svgMargin + len(commits)*svgRowHeight
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"ui-monospace, Menlo, Consolas, monospace\" font-size=\"12\">\n", width, height, width, height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", p.background)
+
+	// Edges first so the commit dots sit on top
+	b.WriteString("<g fill=\"none\" stroke-width=\"2\">\n")
+	for _, e := range edges {
+		fromX, fromY := laneX(commits[e.From].Lane), rowY(e.From)
+		path := fmt.Sprintf("M%d %d", fromX, fromY)
+		switch {
+		case e.To == e.From+1:
+			toX, toY := laneX(commits[e.To].Lane), rowY(e.To)
+			path += fmt.Sprintf(" C%d %d %d %d %d %d", fromX, fromY+svgRowHeight/2, toX, toY-svgRowHeight/2, toX, toY)
+		default:
+			// Step into the edge's lane, run down it, then step into the parent's
+			x, y := fromX, fromY
+			if lx := laneX(e.Lane); lx != x {
+				y += svgRowHeight
+				path += fmt.Sprintf(" C%d %d %d %d %d %d", x, y-svgRowHeight/2, lx, y-svgRowHeight/2, lx, y)
+				x = lx
+			}
+			if e.To < 0 {
+				path += fmt.Sprintf(" L%d %d", x, height)
+				break
+			}
+			toX, toY := laneX(commits[e.To].Lane), rowY(e.To)
+			if toX != x {
+				path += fmt.Sprintf(" L%d %d C%d %d %d %d %d %d", x, toY-svgRowHeight, x, toY-svgRowHeight/2, toX, toY-svgRowHeight/2, toX, toY)
+			} else {
+				path += fmt.Sprintf(" L%d %d", toX, toY)
+			}
+		}
+		dash := ""
+		if e.Merge {
+			dash = " stroke-dasharray=\"4 3\""
+		}
+		fmt.Fprintf(&b, "<path d=\"%s\" stroke=\"%s\"%s/>\n", path, p.lane(e.Lane), dash)
+	}
+	b.WriteString("</g>\n")
+
+	for i, c := range commits {
+		x, y := laneX(c.Lane), rowY(i)
+		if c.IsMerge {
+			fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"9\" height=\"9\" transform=\"rotate(45 %d %d)\" fill=\"%s\"/>\n", x-4, y-4, x, y, p.merge)
+		} else {
+			fmt.Fprintf(&b, "<circle cx=\"%d\" cy=\"%d\" r=\"5\" fill=\"%s\" stroke=\"%s\" stroke-width=\"2\"/>\n", x, y, p.background, p.lane(c.Lane))
+		}
+
+		tx := textX
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" fill=\"%s\" font-weight=\"bold\">%s</text>\n", tx, y+4, p.hash, html.EscapeString(c.Hash))
+		tx += (utf8.RuneCountInString(c.Hash) + 1) * svgCharWidth
+		for _, l := range labels[c.FullHash] {
+			w := (utf8.RuneCountInString(l.name) + 1) * svgCharWidth
+			fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"16\" rx=\"3\" fill=\"%s\"/>\n", tx, y-8, w, l.color)
+			fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" fill=\"%s\">%s</text>\n", tx+svgCharWidth/2, y+4, p.background, html.EscapeString(l.name))
+			tx += w + svgCharWidth
+		}
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" fill=\"%s\">%s <tspan fill=\"%s\">%s · %s</tspan></text>\n",
+			tx, y+4, p.text, html.EscapeString(c.Message), p.muted, html.EscapeString(c.Author), html.EscapeString(c.Date.Format(reportDate)))
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
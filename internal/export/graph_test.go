@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// testGraph is a merged side branch over a base whose parent was not
+// loaded, labelled with a local branch, a remote branch and a tag.
+func testGraph() ([]types.GraphCommit, []types.Branch) {
+	c := func(hash, message string, days int, parents ...string) types.GraphCommit {
+		gc := commit(strings.Repeat(hash, 40), "Ada", message, days)
+		for _, p := range parents {
+			gc.Parents = append(gc.Parents, strings.Repeat(p, 40))
+		}
+		gc.IsMerge = len(parents) > 1
+		return gc
+	}
+	commits := []types.GraphCommit{
+		c("e", "Merge branch 'topic'", 4, "c", "d"),
+		c("d", "Topic <work> & more", 3, "b"),
+		c("c", "Main work", 2, "b"),
+		c("b", "Base", 1, "a"),
+	}
+	refs := []types.Branch{
+		{Name: "main", FullName: "refs/heads/main", Hash: strings.Repeat("e", 40), IsHead: true},
+		{Name: "origin/topic", FullName: "refs/remotes/origin/topic", Hash: strings.Repeat("d", 40), IsRemote: true},
+		{Name: "v1.0", FullName: "refs/tags/v1.0", Hash: strings.Repeat("b", 40)},
+	}
+	return commits, refs
+}
+
+func TestWriteGraph(t *testing.T) {
+	tests := []struct {
+		golden string
+		format string
+		t      theme.Theme
+	}{
+		{"graph.dot", "dot", theme.Dark},
+		{"graph.svg", "svg", theme.Dark},
+		{"graph_light.svg", "svg", theme.Light},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			commits, refs := testGraph()
+			var b bytes.Buffer
+			if err := WriteGraph(&b, commits, refs, tt.format, tt.t); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.golden, b.Bytes())
+
+			if tt.format == "svg" {
+				// Escaped commit text keeps the image well-formed
+				dec := xml.NewDecoder(bytes.NewReader(b.Bytes()))
+				for {
+					_, err := dec.Token()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					if err != nil {
+						t.Fatalf("SVG is not well-formed XML: %v", err)
+					}
+				}
+			}
+		})
+	}
+
+	commits, refs := testGraph()
+	if err := WriteGraph(&bytes.Buffer{}, commits, refs, "png", theme.Dark); err == nil {
+		t.Error("WriteGraph() accepted an unknown format")
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := []struct {
+		color lipgloss.Color
+		want  string
+	}{
+		{"#FF79C6", "#FF79C6"},
+		{"#f0a", "#ff00aa"},
+		{"1", "#CD0000"},
+		{"15", "#FFFFFF"},
+		{"16", "#000000"},
+		{"196", "#FF0000"},
+		{"231", "#FFFFFF"},
+		{"232", "#080808"},
+		{"255", "#EEEEEE"},
+		{"256", "#000000"},
+		{"red", "#000000"},
+	}
+	for _, tt := range tests {
+		if got := hexColor(tt.color); got != tt.want {
+			t.Errorf("hexColor(%q) = %s, want %s", tt.color, got, tt.want)
+		}
+	}
+}
+
+func TestGraphFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"graph.dot": "dot",
+		"graph.GV":  "dot",
+		"graph.svg": "svg",
+		"graph":     "svg",
+	} {
+		if got := GraphFormat(path); got != want {
+			t.Errorf("GraphFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
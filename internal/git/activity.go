@@ -0,0 +1,22 @@
+package git
+
+import "github.com/tomiwa-a/git-radar/internal/types"
+
+// Activity counts the commits of rng that match filter per day, keyed by
+// types.DayLayout. Days follow the author date in the author's own timezone, so
+// a late-night commit counts for the day its author saw.
+func (s *Service) Activity(rng string, filter types.CommitFilter) (map[string]int, error) {
+	commits, err := s.logRange(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := s.commitMatcher(filter)
+	days := make(map[string]int)
+	for _, c := range commits {
+		if matches(c) {
+			days[c.Author.When.Format(types.DayLayout)]++
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,488 @@
+package git
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/format/diff"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage/filesystem"
+)
+
+const cherryPickHead plumbing.ReferenceName = "CHERRY_PICK_HEAD"
+const origHead plumbing.ReferenceName = "ORIG_HEAD"
+
+var ErrDirtyWorktree = errors.New("worktree has uncommitted changes")
+var ErrNoCherryPickInProgress = errors.New("no cherry-pick in progress")
+
+// CherryPickConflictError is returned when a commit could not be applied
+// cleanly. Conflicted files are left in the worktree with conflict markers,
+// unstaged and without index conflict stages. CHERRY_PICK_HEAD and MERGE_MSG
+// are set, so git commit finishes the commit with its author and message.
+// Remaining are the commits after it, which were not applied.
+type CherryPickConflictError struct {
+	Commit    string
+	Files     []string
+	Remaining []string
+}
+
+func (e *CherryPickConflictError) Error() string {
+	msg := fmt.Sprintf("cherry-pick of %s stopped: conflicts in %d files", e.Commit, len(e.Files))
+	if len(e.Remaining) > 0 {
+		msg += fmt.Sprintf("; %d more not applied: %s", len(e.Remaining), strings.Join(e.Remaining, ", "))
+	}
+	return msg
+}
+
+// CherryPick applies the given commits, oldest first, on top of branch.
+// branch must be the checked-out branch. On conflict the applied commits
+// are kept, the rest are listed in the CherryPickConflictError, and the
+// repository is left mid cherry-pick until AbortCherryPick.
+func (s *Service) CherryPick(branch string, hashes []string) error {
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	if head.Name() != plumbing.NewBranchReferenceName(branch) {
+		return fmt.Errorf("checkout %s before cherry-picking onto it", branch)
+	}
+	if s.CherryPickInProgress() {
+		return errors.New("a cherry-pick is already in progress")
+	}
+
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := checkClean(wt); err != nil {
+		return err
+	}
+
+	committer, err := s.configSignature()
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(origHead, head.Hash())); err != nil {
+		return err
+	}
+
+	for i, h := range hashes {
+		commit, err := s.repo.CommitObject(plumbing.NewHash(h))
+		if err != nil {
+			return err
+		}
+		if err := s.applyCommit(wt, commit, committer); err != nil {
+			var conflict *CherryPickConflictError
+			if errors.As(err, &conflict) {
+				for _, rest := range hashes[i+1:] {
+					conflict.Remaining = append(conflict.Remaining, rest[:min(7, len(rest))])
+				}
+			}
+			return err
+		}
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
+func (s *Service) applyCommit(wt *git.Worktree, commit *object.Commit, committer object.Signature) error {
+	if len(commit.ParentHashes) != 1 {
+		return fmt.Errorf("cannot cherry-pick %s: merge and root commits are not supported", commit.Hash.String()[:7])
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return err
+	}
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return err
+	}
+	commitTree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return err
+	}
+
+	label := commit.Hash.String()[:7] + " (" + firstLine(commit.Message) + ")"
+	conflicts, err := mergeTrees(wt, parentTree, headTree, commitTree, label, true)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		if err := s.repo.Storer.SetReference(plumbing.NewHashReference(cherryPickHead, commit.Hash)); err != nil {
+			return err
+		}
+		// Laid out as git leaves it, for git commit to offer
+		message := strings.TrimRight(commit.Message, "\n") + "\n\n# Conflicts:\n"
+		for _, path := range conflicts {
+			message += "#\t" + path + "\n"
+		}
+		if err := s.writeMergeMsg(message); err != nil {
+			return err
+		}
+		return &CherryPickConflictError{Commit: commit.Hash.String()[:7], Files: conflicts}
+	}
+
+	committer.When = time.Now()
+	_, err = wt.Commit(commit.Message, &git.CommitOptions{
+		Author:    &commit.Author,
+		Committer: &committer,
+	})
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return nil
+	}
+	return err
+}
+
+// AbortCherryPick resets the current branch, index and worktree to where
+// they were before the cherry-pick started.
+func (s *Service) AbortCherryPick() error {
+	orig, err := s.repo.Reference(origHead, true)
+	if err != nil || !s.CherryPickInProgress() {
+		return ErrNoCherryPickInProgress
+	}
+
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := s.resetHard(wt, orig.Hash()); err != nil {
+		return err
+	}
+	if err := s.repo.Storer.RemoveReference(cherryPickHead); err != nil {
+		return err
+	}
+	if err := s.writeMergeMsg(""); err != nil {
+		return err
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
+// writeMergeMsg replaces .git/MERGE_MSG with message, or removes it when
+// message is empty.
+func (s *Service) writeMergeMsg(message string) error {
+	storage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	fs := storage.Filesystem()
+	if message == "" {
+		if err := fs.Remove("MERGE_MSG"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	f, err := fs.Create("MERGE_MSG")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write([]byte(message)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resetHard moves the current branch to target and makes the index and the
+// tracked files match it. Unlike go-git's HardReset it leaves untracked
+// files alone.
+func (s *Service) resetHard(wt *git.Worktree, target plumbing.Hash) error {
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	targetCommit, err := s.repo.CommitObject(target)
+	if err != nil {
+		return err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return err
+	}
+	targetTree, err := targetCommit.Tree()
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]bool)
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+	for path, fs := range status {
+		if fs.Staging != git.Untracked || fs.Worktree != git.Untracked {
+			paths[path] = true
+		}
+	}
+	changes, err := headTree.Diff(targetTree)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		paths[change.From.Name] = true
+		paths[change.To.Name] = true
+	}
+	delete(paths, "")
+
+	for path := range paths {
+		if content, ok := treeFileContent(targetTree, path); ok {
+			if err := writeWorktreeFile(wt, path, content, treeFileMode(targetTree, path)); err != nil {
+				return err
+			}
+		} else if err := removeWorktreeFile(wt, path); err != nil {
+			return err
+		}
+	}
+
+	return wt.Reset(&git.ResetOptions{Commit: target, Mode: git.MixedReset})
+}
+
+// checkClean returns ErrDirtyWorktree if tracked files have staged or
+// unstaged changes. Untracked files are ignored.
+func checkClean(wt *git.Worktree) error {
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+	for _, fs := range status {
+		if fs.Staging != git.Unmodified && fs.Staging != git.Untracked {
+			return ErrDirtyWorktree
+		}
+		if fs.Worktree != git.Unmodified && fs.Worktree != git.Untracked {
+			return ErrDirtyWorktree
+		}
+	}
+	return nil
+}
+
+func (s *Service) CherryPickInProgress() bool {
+	_, err := s.repo.Reference(cherryPickHead, true)
+	return err == nil
+}
+
+func (s *Service) configSignature() (object.Signature, error) {
+	cfg, err := s.repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return object.Signature{}, err
+	}
+	if cfg.Committer.Name != "" && cfg.Committer.Email != "" {
+		return object.Signature{Name: cfg.Committer.Name, Email: cfg.Committer.Email, When: time.Now()}, nil
+	}
+	if cfg.User.Name != "" && cfg.User.Email != "" {
+		return object.Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}, nil
+	}
+	return object.Signature{}, errors.New("user.name and user.email are not configured")
+}
+
+// mergeTrees applies the changes between base and theirs to the worktree,
+// which must match ours. Files changed on both sides are merged line by line.
+// Cleanly merged files are staged when stageAll is set; otherwise only
+// additions and removals are. It returns the files left with conflicts.
+func mergeTrees(wt *git.Worktree, base, ours, theirs *object.Tree, label string, stageAll bool) ([]string, error) {
+	changes, err := base.Diff(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+
+		baseContent, baseOK := treeFileContent(base, path)
+		theirsContent, theirsOK := treeFileContent(theirs, path)
+		oursContent, oursOK := treeFileContent(ours, path)
+
+		// Take theirs' mode if they changed it, as for the content
+		mode := treeFileMode(ours, path)
+		if theirsMode := treeFileMode(theirs, path); theirsMode != treeFileMode(base, path) {
+			mode = theirsMode
+		}
+
+		var merged string
+		switch {
+		case oursOK == theirsOK && oursContent == theirsContent && mode == treeFileMode(ours, path):
+			continue
+		case oursOK == baseOK && oursContent == baseContent:
+			if !theirsOK {
+				if _, err := wt.Remove(path); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			merged = theirsContent
+		case oursOK && theirsOK && baseOK && !isBinary(baseContent) && !isBinary(oursContent) && !isBinary(theirsContent):
+			var conflicted bool
+			merged, conflicted = merge3(baseContent, oursContent, theirsContent, label)
+			if conflicted {
+				if err := writeWorktreeFile(wt, path, merged, mode); err != nil {
+					return nil, err
+				}
+				conflicts = append(conflicts, path)
+				continue
+			}
+		default:
+			conflicts = append(conflicts, path)
+			continue
+		}
+
+		if err := writeWorktreeFile(wt, path, merged, mode); err != nil {
+			return nil, err
+		}
+		if stageAll || !oursOK {
+			if _, err := wt.Add(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+func treeFileContent(tree *object.Tree, path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	f, err := tree.File(path)
+	if err != nil {
+		return "", false
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return "", false
+	}
+	return content, true
+}
+
+// treeFileMode is the mode of path in tree, or 0 if it is not there.
+func treeFileMode(tree *object.Tree, path string) filemode.FileMode {
+	if path == "" {
+		return 0
+	}
+	f, err := tree.File(path)
+	if err != nil {
+		return 0
+	}
+	return f.Mode
+}
+
+// writeWorktreeFile replaces path with content as a file of the given tree
+// mode: a symlink to content, or a regular file, executable or not. A mode
+// of 0 writes a regular file.
+func writeWorktreeFile(wt *git.Worktree, path, content string, mode filemode.FileMode) error {
+	// Remove what is there first so a symlink is replaced, not written through
+	if err := removeWorktreeFile(wt, path); err != nil {
+		return err
+	}
+	if mode == filemode.Symlink {
+		return wt.Filesystem.Symlink(content, path)
+	}
+
+	perm := os.FileMode(0o644)
+	if mode == filemode.Executable {
+		perm = 0o755
+	}
+	f, err := wt.Filesystem.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// removeWorktreeFile removes path if it exists. A symlink is removed itself:
+// the worktree filesystem would resolve it and remove its target.
+func removeWorktreeFile(wt *git.Worktree, path string) error {
+	err := os.Remove(filepath.Join(wt.Filesystem.Root(), path))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func isBinary(content string) bool {
+	return strings.IndexByte(content, 0) != -1
+}
+
+func firstLine(message string) string {
+	return strings.Split(strings.TrimSpace(message), "\n")[0]
+}
+
+// patchID identifies a commit by the changes it introduces, ignoring
+// whitespace, so cherry-picked copies can be matched to their originals.
+func patchID(c *object.Commit) (string, bool) {
+	if len(c.ParentHashes) != 1 {
+		return "", false
+	}
+	parent, err := c.Parent(0)
+	if err != nil {
+		return "", false
+	}
+	patch, err := parent.Patch(c)
+	if err != nil {
+		return "", false
+	}
+
+	h := sha1.New()
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		if from != nil {
+			h.Write([]byte("a/" + from.Path() + "\n"))
+		}
+		if to != nil {
+			h.Write([]byte("b/" + to.Path() + "\n"))
+		}
+		for _, chunk := range fp.Chunks() {
+			var prefix string
+			switch chunk.Type() {
+			case diff.Add:
+				prefix = "+"
+			case diff.Delete:
+				prefix = "-"
+			default:
+				continue
+			}
+			for _, line := range strings.Split(chunk.Content(), "\n") {
+				h.Write([]byte(prefix + strings.Join(strings.Fields(line), "") + "\n"))
+			}
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), true
+}
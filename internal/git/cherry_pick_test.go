@@ -0,0 +1,266 @@
+package git
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// pickRepo makes master with f and g, and a feature branch from it with
+// three commits by Alice: one changing g, one changing f and one adding h.
+// master then changes f too, so the second feature commit conflicts.
+func pickRepo(t *testing.T) (string, *Service, []plumbing.Hash) {
+	t.Helper()
+	dir, s := stashRepo(t, map[string]string{"f": "base\n", "g": "g\n"})
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feature := plumbing.NewBranchReferenceName("feature")
+	master := plumbing.NewBranchReferenceName("master")
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: feature, Create: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := &object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Unix(1700000000, 0)}
+	var picks []plumbing.Hash
+	for _, c := range []struct{ path, content, message string }{
+		{"g", "g feature\n", "change g\n"},
+		{"f", "feature\n", "change f\n"},
+		{"h", "h\n", "add h\n"},
+	} {
+		writeFile(t, dir, c.path, c.content)
+		if _, err := wt.Add(c.path); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := wt.Commit(c.message, &git.CommitOptions{Author: alice, Committer: alice})
+		if err != nil {
+			t.Fatal(err)
+		}
+		picks = append(picks, hash)
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: master}); err != nil {
+		t.Fatal(err)
+	}
+	commitIn(t, dir, map[string]string{"f": "master\n", "g": "g\n"})
+	return dir, s, picks
+}
+
+func hashStrings(hashes ...plumbing.Hash) []string {
+	var out []string
+	for _, h := range hashes {
+		out = append(out, h.String())
+	}
+	return out
+}
+
+func TestCherryPick(t *testing.T) {
+	dir, s, picks := pickRepo(t)
+	before := refHash(t, dir, plumbing.HEAD)
+
+	if err := s.CherryPick("master", hashStrings(picks[0], picks[2])); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"f": "master\n", "g": "g feature\n", "h": "h\n"}
+	if got := readFiles(t, dir); !maps.Equal(got, want) {
+		t.Errorf("worktree = %q, want %q", got, want)
+	}
+
+	head, err := s.repo.CommitObject(refHash(t, dir, plumbing.HEAD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for c := head; c.Hash != before; {
+		if c.Author.Name != "Alice" || c.Committer.Name != "Test" {
+			t.Errorf("%s: author %s, committer %s; want Alice and Test", firstLine(c.Message), c.Author.Name, c.Committer.Name)
+		}
+		messages = append(messages, c.Message)
+		if c, err = c.Parent(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(messages, []string{"add h\n", "change g\n"}) {
+		t.Errorf("picked = %q, want add h on change g", messages)
+	}
+	if s.CherryPickInProgress() {
+		t.Error("clean cherry-pick left CHERRY_PICK_HEAD")
+	}
+}
+
+func TestCherryPickConflict(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(t *testing.T, dir string, s *Service)
+		check  func(t *testing.T, dir string, s *Service, before plumbing.Hash)
+	}{
+		{
+			name: "abort",
+			finish: func(t *testing.T, dir string, s *Service) {
+				if err := s.AbortCherryPick(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, dir string, s *Service, before plumbing.Hash) {
+				if got := refHash(t, dir, plumbing.HEAD); got != before {
+					t.Errorf("HEAD = %s, want it back at %s", got, before)
+				}
+				if got := readFiles(t, dir); got["f"] != "master\n" || got["g"] != "g\n" {
+					t.Errorf("worktree = %q, want master's files", got)
+				}
+				if _, err := os.Stat(filepath.Join(dir, ".git", "MERGE_MSG")); !os.IsNotExist(err) {
+					t.Errorf("MERGE_MSG left behind: %v", err)
+				}
+				if err := s.AbortCherryPick(); !errors.Is(err, ErrNoCherryPickInProgress) {
+					t.Errorf("second abort = %v, want ErrNoCherryPickInProgress", err)
+				}
+			},
+		},
+		{
+			name: "git commit finishes it",
+			finish: func(t *testing.T, dir string, s *Service) {
+				if _, err := exec.LookPath("git"); err != nil {
+					t.Skip("git not installed")
+				}
+				writeFile(t, dir, "f", "resolved\n")
+				for _, args := range [][]string{{"add", "f"}, {"-c", "core.editor=true", "commit"}} {
+					cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+					if out, err := cmd.CombinedOutput(); err != nil {
+						t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+					}
+				}
+			},
+			check: func(t *testing.T, dir string, s *Service, before plumbing.Hash) {
+				head, err := s.repo.CommitObject(refHash(t, dir, plumbing.HEAD))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if head.Message != "change f\n" || head.Author.Name != "Alice" {
+					t.Errorf("HEAD = %q by %s, want change f by Alice", head.Message, head.Author.Name)
+				}
+				if s.CherryPickInProgress() {
+					t.Error("CHERRY_PICK_HEAD left after git commit")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, s, picks := pickRepo(t)
+			before := refHash(t, dir, plumbing.HEAD)
+
+			err := s.CherryPick("master", hashStrings(picks...))
+			var conflict *CherryPickConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("CherryPick() = %v, want a CherryPickConflictError", err)
+			}
+			want := CherryPickConflictError{
+				Commit:    picks[1].String()[:7],
+				Files:     []string{"f"},
+				Remaining: []string{picks[2].String()[:7]},
+			}
+			if conflict.Commit != want.Commit || !slices.Equal(conflict.Files, want.Files) || !slices.Equal(conflict.Remaining, want.Remaining) {
+				t.Errorf("conflict = %+v, want %+v", conflict, want)
+			}
+
+			// The first commit went in; the second waits with markers
+			if !s.CherryPickInProgress() || refHash(t, dir, cherryPickHead) != picks[1] {
+				t.Error("CHERRY_PICK_HEAD should name the conflicting commit")
+			}
+			if got := refHash(t, dir, origHead); got != before {
+				t.Errorf("ORIG_HEAD = %s, want %s", got, before)
+			}
+			files := readFiles(t, dir)
+			if files["g"] != "g feature\n" || !strings.Contains(files["f"], "<<<<<<< HEAD\nmaster\n=======\nfeature\n>>>>>>> ") {
+				t.Errorf("worktree = %q, want g picked and f conflicted", files)
+			}
+			if _, ok := files["h"]; ok {
+				t.Error("h was applied after the conflict")
+			}
+			msg, err := os.ReadFile(filepath.Join(dir, ".git", "MERGE_MSG"))
+			if err != nil || string(msg) != "change f\n\n# Conflicts:\n#\tf\n" {
+				t.Errorf("MERGE_MSG = %q, %v", msg, err)
+			}
+			if err := s.CherryPick("master", hashStrings(picks[2])); err == nil || !strings.Contains(err.Error(), "already in progress") {
+				t.Errorf("CherryPick() during a conflict = %v, want already in progress", err)
+			}
+
+			tt.finish(t, dir, s)
+			tt.check(t, dir, s, before)
+		})
+	}
+}
+
+func TestCherryPickRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string, s *Service, picks []plumbing.Hash) (branch string, hashes []string)
+		wantErr string
+	}{
+		{
+			name: "branch not checked out",
+			setup: func(t *testing.T, dir string, s *Service, picks []plumbing.Hash) (string, []string) {
+				return "feature", hashStrings(picks[0])
+			},
+			wantErr: "checkout feature",
+		},
+		{
+			name: "dirty worktree",
+			setup: func(t *testing.T, dir string, s *Service, picks []plumbing.Hash) (string, []string) {
+				writeFile(t, dir, "g", "local\n")
+				return "master", hashStrings(picks[0])
+			},
+			wantErr: ErrDirtyWorktree.Error(),
+		},
+		{
+			name: "merge commit",
+			setup: func(t *testing.T, dir string, s *Service, picks []plumbing.Hash) (string, []string) {
+				sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+				commit, err := s.repo.CommitObject(picks[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				merge, err := s.writeCommit(&object.Commit{
+					Author:       sig,
+					Committer:    sig,
+					Message:      "merge\n",
+					TreeHash:     commit.TreeHash,
+					ParentHashes: []plumbing.Hash{picks[0], picks[1]},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return "master", hashStrings(merge)
+			},
+			wantErr: "merge and root commits are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, s, picks := pickRepo(t)
+			before := refHash(t, dir, plumbing.HEAD)
+			branch, hashes := tt.setup(t, dir, s, picks)
+
+			if err := s.CherryPick(branch, hashes); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CherryPick() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if got := refHash(t, dir, plumbing.HEAD); got != before {
+				t.Errorf("HEAD moved to %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// Churn ranks the files and directories changed in a window by how many
+// commits touched them, then by lines added and deleted. A window with ".."
+// is a range as accepted by logRange, such as "v1.0..main"; anything else is
+// a date such as "3 months ago" or "2024-01-01", meaning HEAD's commits
+// since then. Merge commits are left out, as in git log --numstat.
+func (s *Service) Churn(window string) ([]types.ChurnEntry, error) {
+	commits, since, err := s.churnCommits(window)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*types.ChurnEntry)
+	touch := func(p string, isDir bool, adds, dels int, when time.Time) {
+		e, ok := entries[p]
+		if !ok {
+			e = &types.ChurnEntry{Path: p, IsDir: isDir}
+			entries[p] = e
+		}
+		e.Commits++
+		e.Additions += adds
+		e.Deletions += dels
+		if when.After(e.Last) {
+			e.Last = when
+		}
+	}
+
+	for _, c := range commits {
+		if c.NumParents() > 1 || c.Author.When.Before(since) {
+			continue
+		}
+		stats, err := c.Stats()
+		if err != nil {
+			return nil, err
+		}
+
+		// A directory counts once per commit, however many of its files changed
+		dirs := make(map[string][2]int)
+		for _, st := range stats {
+			touch(st.Name, false, st.Addition, st.Deletion, c.Author.When)
+			for dir := path.Dir(st.Name); dir != "."; dir = path.Dir(dir) {
+				lines := dirs[dir+"/"]
+				dirs[dir+"/"] = [2]int{lines[0] + st.Addition, lines[1] + st.Deletion}
+			}
+		}
+		for dir, lines := range dirs {
+			touch(dir, true, lines[0], lines[1], c.Author.When)
+		}
+	}
+
+	result := make([]types.ChurnEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return a.Path < b.Path
+	})
+	return result, nil
+}
+
+// churnCommits lists the commits of a churn window, newest first, with the
+// date it starts from, zero for a range. A date window stops walking HEAD's
+// history at the first commit committed before that date.
+func (s *Service) churnCommits(window string) ([]*object.Commit, time.Time, error) {
+	if strings.Contains(window, "..") {
+		commits, err := s.logRange(window)
+		return commits, time.Time{}, err
+	}
+
+	since, err := parseApproxDate(window, time.Now())
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid window %q: expected a date such as 3 months ago or a range such as v1.0..main", window)
+	}
+	head, err := s.resolveRevision("HEAD")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	iter, err := s.repo.Log(&git.LogOptions{From: head, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		// Commits come newest first, so the rest are older still
+		if c.Committer.When.Before(since) {
+			return storer.ErrStop
+		}
+		commits = append(commits, c)
+		return nil
+	})
+	return commits, since, err
+}
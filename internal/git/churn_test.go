@@ -0,0 +1,165 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// churnRepo commits each step's files on master, one per month from
+// 2023-01-01, tags the third commit v1 and returns the commit hashes.
+func churnRepo(t *testing.T, steps ...map[string]string) (string, *Service, []plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []plumbing.Hash
+	for i, files := range steps {
+		for path, content := range files {
+			if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, dir, path, content)
+			if _, err := wt.Add(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Date(2023, time.Month(i+1), 1, 12, 0, 0, 0, time.UTC)}
+		hash, err := wt.Commit("step\n", &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) >= 3 {
+		if _, err := repo.CreateTag("v1", hashes[2], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, &Service{repo: repo}, hashes
+}
+
+func TestChurn(t *testing.T) {
+	steps := []map[string]string{
+		{"README": "a\n"},                                  // Jan
+		{"README": "b\n", "src/a.go": "a\n"},               // Feb
+		{"src/a.go": "a\nb\n"},                             // Mar, tagged v1
+		{"src/a.go": "a\nb\nc\n", "src/sub/b.go": "b\n"},   // Apr
+		{"src/sub/b.go": "b\nc\n", "docs/guide.md": "g\n"}, // May
+		{"README": "c\n"},                                  // Jun
+	}
+
+	tests := []struct {
+		name    string
+		window  string
+		want    []string // path:commits:+adds:-dels, in rank order
+		wantErr string
+	}{
+		{
+			name:   "since a date",
+			window: "2023-04-01",
+			want: []string{
+				"src/:2:+3:-0", "src/sub/:2:+2:-0", "src/sub/b.go:2:+2:-0",
+				"README:1:+1:-1", "docs/:1:+1:-0", "docs/guide.md:1:+1:-0", "src/a.go:1:+1:-0",
+			},
+		},
+		{
+			name:   "range",
+			window: "v1..HEAD~1",
+			want: []string{
+				"src/:2:+3:-0", "src/sub/:2:+2:-0", "src/sub/b.go:2:+2:-0",
+				"docs/:1:+1:-0", "docs/guide.md:1:+1:-0", "src/a.go:1:+1:-0",
+			},
+		},
+		{
+			name:   "range to HEAD",
+			window: "HEAD~1..",
+			want:   []string{"README:1:+1:-1"},
+		},
+		{
+			name:   "date after every commit",
+			window: "2024-01-01",
+			want:   nil,
+		},
+		{
+			// A bare revision is no longer a window
+			name:    "revision",
+			window:  "v1",
+			wantErr: `invalid window "v1"`,
+		},
+		{
+			name:    "unknown revision in a range",
+			window:  "v1..nope",
+			wantErr: "nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, s, _ := churnRepo(t, steps...)
+			entries, err := s.Churn(tt.window)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Churn(%q) = %v, want an error containing %q", tt.window, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, churnString(e))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Churn(%q) =\n  %q\nwant\n  %q", tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChurnStopsAtWindowStart removes the root commit, so walking past the
+// start of a date window would fail to load it.
+func TestChurnStopsAtWindowStart(t *testing.T) {
+	dir, s, hashes := churnRepo(t,
+		map[string]string{"a": "1\n"},
+		map[string]string{"a": "2\n"},
+		map[string]string{"a": "3\n"},
+		map[string]string{"a": "4\n"},
+	)
+	root := hashes[0].String()
+	if err := os.Remove(filepath.Join(dir, ".git", "objects", root[:2], root[2:])); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.Churn("2023-03-15")
+	if err != nil {
+		t.Fatalf("Churn() walked past the window start: %v", err)
+	}
+	if len(entries) != 1 || churnString(entries[0]) != "a:1:+1:-1" {
+		t.Errorf("Churn() = %v, want only April's change to a", entries)
+	}
+
+	if _, err := s.Churn("2022-01-01"); err == nil {
+		t.Error("Churn() over the whole history loaded the missing root commit")
+	}
+}
+
+func churnString(e types.ChurnEntry) string {
+	return fmt.Sprintf("%s:%d:+%d:-%d", e.Path, e.Commits, e.Additions, e.Deletions)
+}
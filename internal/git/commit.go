@@ -0,0 +1,76 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+var trailerRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// graphCommit converts a commit object into the form the UI shows, leaving
+// graph layout and branch labels to the caller.
+func (s *Service) graphCommit(c *object.Commit) types.GraphCommit {
+	var parents []string
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	subject, body := splitMessage(c.Message)
+	commit := types.GraphCommit{
+		Hash:           c.Hash.String()[:7],
+		FullHash:       c.Hash.String(),
+		Message:        subject,
+		Body:           body,
+		Trailers:       parseTrailers(body),
+		Author:         c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		Date:           c.Author.When,
+		Committer:      c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommitDate:     c.Committer.When,
+		Parents:        parents,
+		IsMerge:        len(parents) > 1,
+	}
+	// Verifying is slow, so it waits until the commit is opened
+	if c.PGPSignature != "" {
+		commit.Signature = types.Unverified
+	}
+	return commit
+}
+
+// splitMessage separates the subject line from the rest of the message.
+func splitMessage(message string) (string, string) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body)
+}
+
+// parseTrailers reads the "Key: value" lines of the last paragraph of body,
+// like git interpret-trailers. Indented lines continue the previous value.
+// The paragraph only counts when every line in it is a trailer.
+func parseTrailers(body string) []types.Trailer {
+	if body == "" {
+		return nil
+	}
+	paragraphs := strings.Split(body, "\n\n")
+	last := strings.TrimSpace(paragraphs[len(paragraphs)-1])
+
+	var trailers []types.Trailer
+	for _, line := range strings.Split(last, "\n") {
+		switch {
+		case (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0:
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+		case strings.HasPrefix(line, "(cherry picked from commit "):
+			// Added by git cherry-pick -x alongside real trailers
+		default:
+			m := trailerRe.FindStringSubmatch(line)
+			if m == nil {
+				return nil
+			}
+			trailers = append(trailers, types.Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+		}
+	}
+	return trailers
+}
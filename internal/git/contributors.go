@@ -0,0 +1,64 @@
+package git
+
+import (
+	"sort"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// Contributors summarizes the authors of a range like git shortlog -s: one
+// entry per author name after .mailmap, most commits first. Line counts
+// leave out merge commits, as git log --numstat does.
+func (s *Service) Contributors(rng string) ([]types.Contributor, error) {
+	commits, err := s.logRange(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	mm := s.loadMailmap()
+	byName := make(map[string]*types.Contributor)
+	var order []string
+	for _, c := range commits {
+		name, email := mm.author(c)
+		contributor, ok := byName[name]
+		if !ok {
+			contributor = &types.Contributor{Name: name, Email: email, First: c.Author.When, Last: c.Author.When}
+			byName[name] = contributor
+			order = append(order, name)
+		}
+
+		contributor.Commits++
+		when := c.Author.When
+		if when.Before(contributor.First) {
+			contributor.First = when
+		}
+		if when.After(contributor.Last) {
+			contributor.Last = when
+			contributor.Email = email
+		}
+
+		if c.NumParents() > 1 {
+			continue
+		}
+		stats, err := c.Stats()
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range stats {
+			contributor.Additions += st.Addition
+			contributor.Deletions += st.Deletion
+		}
+	}
+
+	result := make([]types.Contributor, 0, len(order))
+	for _, name := range order {
+		result = append(result, *byName[name])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
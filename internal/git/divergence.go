@@ -0,0 +1,147 @@
+package git
+
+import (
+	"sort"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// Divergence gathers everything the divergence screen and its reports show
+// about two branches.
+func (s *Service) Divergence(target, source string, mode types.CompareMode) (*types.Divergence, error) {
+	d := &types.Divergence{Target: target, Source: source, Mode: mode}
+
+	var err error
+	if d.MergeBase, err = s.GetMergeBase(target, source); err != nil {
+		return nil, err
+	}
+	if d.Incoming, err = s.GetIncomingCommits(target, source); err != nil {
+		return nil, err
+	}
+	if d.Outgoing, err = s.GetOutgoingCommits(target, source); err != nil {
+		return nil, err
+	}
+	if d.Files, err = s.GetBranchDiffStats(source, target, mode); err != nil {
+		return nil, err
+	}
+	if d.MergeBase != nil {
+		if d.Conflicts, err = s.conflictingFiles(target, source, d.MergeBase.FullHash); err != nil {
+			return nil, opError("predict conflicts", err)
+		}
+	}
+	return d, nil
+}
+
+// HideCherryPicked drops the incoming and outgoing commits whose changes
+// the other side already has under another hash, as git log --cherry-pick
+// does, so a picked commit leaves Incoming along with its copy. Every
+// listed commit is diffed for this, so Divergence leaves it to callers.
+func (s *Service) HideCherryPicked(d *types.Divergence) error {
+	incomingIDs, err := s.patchIDs(d.Incoming)
+	if err != nil {
+		return opError("match cherry-picked commits", err)
+	}
+	outgoingIDs, err := s.patchIDs(d.Outgoing)
+	if err != nil {
+		return opError("match cherry-picked commits", err)
+	}
+	d.Incoming = withoutPatchIDs(d.Incoming, incomingIDs, outgoingIDs)
+	d.Outgoing = withoutPatchIDs(d.Outgoing, outgoingIDs, incomingIDs)
+	return nil
+}
+
+// patchIDs returns the patch ID of each commit, "" for merges and roots.
+func (s *Service) patchIDs(commits []types.GraphCommit) ([]string, error) {
+	ids := make([]string, len(commits))
+	for i, c := range commits {
+		commit, err := s.repo.CommitObject(plumbing.NewHash(c.FullHash))
+		if err != nil {
+			return nil, err
+		}
+		ids[i], _ = patchID(commit)
+	}
+	return ids, nil
+}
+
+// withoutPatchIDs keeps the commits whose patch ID, ids[i], is not among
+// others.
+func withoutPatchIDs(commits []types.GraphCommit, ids, others []string) []types.GraphCommit {
+	seen := make(map[string]bool, len(others))
+	for _, id := range others {
+		if id != "" {
+			seen[id] = true
+		}
+	}
+	var kept []types.GraphCommit
+	for i, c := range commits {
+		if !seen[ids[i]] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// conflictingFiles lists the paths both branches changed since base, where
+// the two sides did not end up with the same content. These are the files
+// a merge may stop on.
+func (s *Service) conflictingFiles(target, source, base string) ([]string, error) {
+	baseCommit, err := s.repo.CommitObject(plumbing.NewHash(base))
+	if err != nil {
+		return nil, err
+	}
+	targetChanges, err := s.changedSince(baseCommit, target)
+	if err != nil {
+		return nil, err
+	}
+	sourceChanges, err := s.changedSince(baseCommit, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for path, hash := range sourceChanges {
+		if other, ok := targetChanges[path]; ok && other != hash {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// changedSince maps each path that differs between base and rev to its blob
+// at rev, the zero hash when rev deleted it.
+func (s *Service) changedSince(base *object.Commit, rev string) (map[string]plumbing.Hash, error) {
+	hash, err := s.resolveRevision(rev)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := s.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, err
+	}
+	tipTree, err := tip.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(baseTree, tipTree)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]plumbing.Hash)
+	for _, ch := range changes {
+		if ch.From.Name != "" {
+			changed[ch.From.Name] = plumbing.ZeroHash
+		}
+		if ch.To.Name != "" {
+			changed[ch.To.Name] = ch.To.TreeEntry.Hash
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,190 @@
+package git
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// TestDivergenceUnrelatedHistories compares two branches that each start
+// from their own root commit.
+func TestDivergenceUnrelatedHistories(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, map[string]string{"shared": "main\n", "main-only": "m\n"}, nil)
+
+	// Point HEAD at an unborn branch so the next commit is a second root
+	orphan := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("other"))
+	if err := repo.Storer.SetReference(orphan); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, map[string]string{"shared": "other\n", "other-only": "o\n"}, nil)
+	commitFiles(t, repo, wt, map[string]string{"shared": "other 2\n", "other-only": "o\n"}, nil)
+
+	s := &Service{repo: repo}
+	for _, mode := range []types.CompareMode{types.ThreeDotCompare, types.TwoDotCompare} {
+		t.Run(mode.Range("master", "other"), func(t *testing.T) {
+			d, err := s.Divergence("master", "other", mode)
+			if err != nil {
+				t.Fatalf("Divergence() = %v", err)
+			}
+			if d.MergeBase != nil {
+				t.Errorf("merge base = %s, want none", d.MergeBase.Hash)
+			}
+			if len(d.Incoming) != 1 || len(d.Outgoing) != 2 {
+				t.Errorf("incoming %d, outgoing %d; want 1 and 2", len(d.Incoming), len(d.Outgoing))
+			}
+			if d.Conflicts != nil {
+				t.Errorf("conflicts = %v, want none without a merge base", d.Conflicts)
+			}
+
+			// Without a merge base both modes diff tip to tip
+			want := map[string]string{"shared": "M", "main-only": "D", "other-only": "A"}
+			got := make(map[string]string)
+			for _, f := range d.Files {
+				got[f.Path] = f.Status
+			}
+			if len(got) != len(want) {
+				t.Errorf("files = %v, want %v", got, want)
+			}
+			for path, status := range want {
+				if got[path] != status {
+					t.Errorf("%s status = %q, want %q", path, got[path], status)
+				}
+			}
+		})
+	}
+}
+
+func TestHideCherryPicked(t *testing.T) {
+	_, s, picks := pickRepo(t)
+	if err := s.CherryPick("master", []string{picks[0].String()}); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := s.Divergence("feature", "master", types.ThreeDotCompare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Divergence itself still lists the picked commit and its copy
+	if len(d.Incoming) != 3 || len(d.Outgoing) != 2 {
+		t.Fatalf("incoming %d, outgoing %d; want 3 and 2", len(d.Incoming), len(d.Outgoing))
+	}
+
+	if err := s.HideCherryPicked(d); err != nil {
+		t.Fatal(err)
+	}
+	var incoming, outgoing []string
+	for _, c := range d.Incoming {
+		incoming = append(incoming, c.Message)
+	}
+	for _, c := range d.Outgoing {
+		outgoing = append(outgoing, c.Message)
+	}
+	if !slices.Equal(incoming, []string{"add h", "change f"}) {
+		t.Errorf("incoming = %q, want the two commits not picked", incoming)
+	}
+	if !slices.Equal(outgoing, []string{"commit"}) {
+		t.Errorf("outgoing = %q, want only master's own commit", outgoing)
+	}
+}
+
+func TestDivergenceConflicts(t *testing.T) {
+	base := map[string]string{"both": "base\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n"}
+	tests := []struct {
+		name   string
+		master map[string]string
+		topic  map[string]string
+		want   []string
+	}{
+		{
+			name:   "file modified on both sides",
+			master: map[string]string{"both": "master\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n"},
+			topic:  map[string]string{"both": "topic\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n"},
+			want:   []string{"both"},
+		},
+		{
+			name:   "same change on both sides",
+			master: map[string]string{"both": "base\n", "same": "fixed\n", "one": "base\n", "gone": "base\n", "edited": "base\n"},
+			topic:  map[string]string{"both": "base\n", "same": "fixed\n", "one": "base\n", "gone": "base\n", "edited": "base\n"},
+		},
+		{
+			name:   "each side changes its own files",
+			master: map[string]string{"both": "master\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n"},
+			topic:  map[string]string{"both": "base\n", "same": "base\n", "one": "topic\n", "gone": "base\n", "edited": "base\n"},
+		},
+		{
+			name:   "deleted on both sides",
+			master: map[string]string{"both": "base\n", "same": "base\n", "one": "base\n", "edited": "base\n"},
+			topic:  map[string]string{"both": "base\n", "same": "base\n", "one": "base\n", "edited": "base\n"},
+		},
+		{
+			name:   "deleted on one side, modified on the other",
+			master: map[string]string{"both": "base\n", "same": "base\n", "one": "base\n", "gone": "base\n"},
+			topic:  map[string]string{"both": "base\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "topic\n"},
+			want:   []string{"edited"},
+		},
+		{
+			name:   "added on both sides with different content",
+			master: map[string]string{"both": "master\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n", "new": "master\n"},
+			topic:  map[string]string{"both": "topic\n", "same": "base\n", "one": "base\n", "gone": "base\n", "edited": "base\n", "new": "topic\n"},
+			want:   []string{"both", "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			commitFiles(t, repo, wt, base, nil)
+			head, err := repo.Head()
+			if err != nil {
+				t.Fatal(err)
+			}
+			topic := plumbing.NewBranchReferenceName("topic")
+			if err := repo.Storer.SetReference(plumbing.NewHashReference(topic, head.Hash())); err != nil {
+				t.Fatal(err)
+			}
+			commitFiles(t, repo, wt, tt.master, nil)
+
+			// Commit on topic from master's worktree; commitFiles rewrites every
+			// file. A file of its own keeps topic from matching master's commit.
+			if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, topic)); err != nil {
+				t.Fatal(err)
+			}
+			files := maps.Clone(tt.topic)
+			files["topic-only"] = "topic\n"
+			commitFiles(t, repo, wt, files, nil)
+
+			s := &Service{repo: repo}
+			d, err := s.Divergence("master", "topic", types.ThreeDotCompare)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.MergeBase == nil || d.MergeBase.FullHash != head.Hash().String() {
+				t.Fatalf("merge base = %v, want the base commit", d.MergeBase)
+			}
+			if !slices.Equal(d.Conflicts, tt.want) {
+				t.Errorf("conflicts = %q, want %q", d.Conflicts, tt.want)
+			}
+		})
+	}
+}
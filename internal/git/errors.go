@@ -0,0 +1,58 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ErrNotRepository is returned by NewService when the path is not a git
+// repository.
+var ErrNotRepository = errors.New("not a git repository")
+
+// ErrNoCommits is returned when HEAD does not point at a commit yet, as in a
+// freshly initialised repository.
+var ErrNoCommits = errors.New("repository has no commits yet")
+
+// OpError is returned when reading the repository fails. Op says what was
+// being loaded, for example "list branches".
+type OpError struct {
+	Op  string
+	Err error
+}
+
+func (e *OpError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// opError wraps err with op unless it already names an operation.
+func opError(op string, err error) error {
+	var oe *OpError
+	if err == nil || errors.As(err, &oe) {
+		return err
+	}
+	return &OpError{Op: op, Err: err}
+}
+
+// openError explains why a repository could not be opened.
+func openError(path string, err error) error {
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		err = fmt.Errorf("%w: %s", ErrNotRepository, path)
+	}
+	return &OpError{Op: "open repository", Err: err}
+}
+
+// head resolves HEAD, reporting an unborn branch as ErrNoCommits.
+func (s *Service) head() (*plumbing.Reference, error) {
+	head, err := s.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, ErrNoCommits
+	}
+	return head, err
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// commitMatcher returns a test for the commits filter lets through.
+func (s *Service) commitMatcher(filter types.CommitFilter) func(*object.Commit) bool {
+	var mm mailmap
+	if filter.Author != "" {
+		mm = s.loadMailmap()
+	}
+	until := ""
+	if !filter.Until.IsZero() {
+		until = filter.Until.Format(types.DayLayout)
+	}
+	filePath := strings.Trim(path.Clean("/"+filter.Path), "/")
+
+	return func(c *object.Commit) bool {
+		if filter.Author != "" {
+			if name, _ := mm.author(c); name != filter.Author {
+				return false
+			}
+		}
+		if until != "" && c.Author.When.Format(types.DayLayout) > until {
+			return false
+		}
+		if filter.Path != "" && !touchesPath(c, filePath) {
+			return false
+		}
+		return true
+	}
+}
+
+// touchesPath reports whether c changed the file or directory at p, like
+// git log -- p: a commit matches unless p is the same in one of its parents.
+func touchesPath(c *object.Commit, p string) bool {
+	hash := pathHash(c, p)
+	if c.NumParents() == 0 {
+		return !hash.IsZero()
+	}
+
+	same := false
+	c.Parents().ForEach(func(parent *object.Commit) error {
+		if pathHash(parent, p) == hash {
+			same = true
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return !same
+}
+
+// pathHash is the object id of the blob or tree at p in c, zero when absent.
+func pathHash(c *object.Commit, p string) plumbing.Hash {
+	tree, err := c.Tree()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	if p == "" {
+		return tree.Hash
+	}
+	entry, err := tree.FindEntry(p)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// mailmapEntry rewrites an identity, like one line of a .mailmap file. An
+// empty oldName matches any name with oldEmail; an empty name or email keeps
+// the original.
+type mailmapEntry struct {
+	name     string
+	email    string
+	oldName  string
+	oldEmail string
+}
+
+type mailmap []mailmapEntry
+
+// loadMailmap reads .mailmap from the worktree, or from HEAD in a bare
+// repository, followed by the file named by mailmap.file.
+func (s *Service) loadMailmap() mailmap {
+	var mm mailmap
+	if wt, err := s.repo.Worktree(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(wt.Filesystem.Root(), ".mailmap")); err == nil {
+			mm = append(mm, parseMailmap(string(data))...)
+		}
+	} else if head, err := s.repo.Head(); err == nil {
+		if c, err := s.repo.CommitObject(head.Hash()); err == nil {
+			if tree, err := c.Tree(); err == nil {
+				if content, ok := treeFileContent(tree, ".mailmap"); ok {
+					mm = append(mm, parseMailmap(content)...)
+				}
+			}
+		}
+	}
+
+	if path := s.configValue("mailmap", "", "file"); path != "" {
+		if data, err := os.ReadFile(expandHome(path)); err == nil {
+			mm = append(mm, parseMailmap(string(data))...)
+		}
+	}
+	return mm
+}
+
+// parseMailmap reads the forms git accepts:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func parseMailmap(data string) mailmap {
+	var mm mailmap
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		name1, email1, rest, ok := cutIdentity(line)
+		if !ok {
+			continue
+		}
+		name2, email2, _, ok := cutIdentity(rest)
+		if !ok {
+			mm = append(mm, mailmapEntry{name: name1, oldEmail: email1})
+			continue
+		}
+		mm = append(mm, mailmapEntry{name: name1, email: email1, oldName: name2, oldEmail: email2})
+	}
+	return mm
+}
+
+// cutIdentity splits "Name <email> rest" into its parts.
+func cutIdentity(text string) (name, email, rest string, ok bool) {
+	open := strings.Index(text, "<")
+	if open < 0 {
+		return "", "", "", false
+	}
+	end := strings.Index(text[open:], ">")
+	if end < 0 {
+		return "", "", "", false
+	}
+	name = strings.TrimSpace(text[:open])
+	email = strings.TrimSpace(text[open+1 : open+end])
+	return name, email, text[open+end+1:], true
+}
+
+// lookup maps an identity to its canonical form. Entries naming the old
+// author take precedence over ones matching the email alone, and later
+// entries override earlier ones.
+func (mm mailmap) lookup(name, email string) (string, string) {
+	var byName, byEmail *mailmapEntry
+	for i := range mm {
+		e := &mm[i]
+		switch {
+		case !strings.EqualFold(e.oldEmail, email):
+		case e.oldName == "":
+			byEmail = e
+		case strings.EqualFold(e.oldName, name):
+			byName = e
+		}
+	}
+
+	match := byName
+	if match == nil {
+		match = byEmail
+	}
+	if match == nil {
+		return name, email
+	}
+	if match.name != "" {
+		name = match.name
+	}
+	if match.email != "" {
+		email = match.email
+	}
+	return name, email
+}
+
+// author is the commit's author after .mailmap rewriting.
+func (mm mailmap) author(c *object.Commit) (string, string) {
+	return mm.lookup(c.Author.Name, c.Author.Email)
+}
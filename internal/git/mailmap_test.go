@@ -0,0 +1,98 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMailmap(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want mailmap
+	}{
+		{
+			name: "proper name for an email",
+			data: "Jane Doe <jane@example.com>",
+			want: mailmap{{name: "Jane Doe", oldEmail: "jane@example.com"}},
+		},
+		{
+			name: "proper email for an email",
+			data: "<jane@example.com> <jd@old.example.com>",
+			want: mailmap{{email: "jane@example.com", oldEmail: "jd@old.example.com"}},
+		},
+		{
+			name: "proper name and email for an email",
+			data: "Jane Doe <jane@example.com> <jd@old.example.com>",
+			want: mailmap{{name: "Jane Doe", email: "jane@example.com", oldEmail: "jd@old.example.com"}},
+		},
+		{
+			name: "proper identity for a name and email",
+			data: "Jane Doe <jane@example.com> jd <jd@old.example.com>",
+			want: mailmap{{name: "Jane Doe", email: "jane@example.com", oldName: "jd", oldEmail: "jd@old.example.com"}},
+		},
+		{
+			name: "comments, blank and malformed lines",
+			data: "# maintainers\n\n  # indented comment\nno email here\nJane <unterminated\n" +
+				"Jane Doe <jane@example.com> # trailing comment\n",
+			want: mailmap{{name: "Jane Doe", oldEmail: "jane@example.com"}},
+		},
+		{
+			name: "surrounding whitespace",
+			data: "  Jane Doe   <  jane@example.com >\t<jd@old.example.com>  \r\n",
+			want: mailmap{{name: "Jane Doe", email: "jane@example.com", oldEmail: "jd@old.example.com"}},
+		},
+		{
+			name: "several lines in order",
+			data: "A <a@example.com>\nB <b@example.com> <old-b@example.com>\n",
+			want: mailmap{
+				{name: "A", oldEmail: "a@example.com"},
+				{name: "B", email: "b@example.com", oldEmail: "old-b@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseMailmap(tt.data)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseMailmap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailmapLookup(t *testing.T) {
+	mm := parseMailmap(`
+Jane Doe <jane@example.com>
+Jane Doe <jane@example.com> <jd@old.example.com>
+<ops@example.com> <root@localhost>
+Build Bot <bot@example.com> ci <ci@example.com>
+Release Bot <release@example.com> release <ci@example.com>
+Jane D. <jane@example.com>
+`)
+
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"jane", "jane@example.com", "Jane D.", "jane@example.com"},
+		{"jd", "jd@old.example.com", "Jane Doe", "jane@example.com"},
+		{"jd", "JD@Old.Example.com", "Jane Doe", "jane@example.com"},
+		{"root", "root@localhost", "root", "ops@example.com"},
+		{"ci", "ci@example.com", "Build Bot", "bot@example.com"},
+		{"CI", "ci@example.com", "Build Bot", "bot@example.com"},
+		{"release", "ci@example.com", "Release Bot", "release@example.com"},
+		{"someone", "ci@example.com", "someone", "ci@example.com"},
+		{"stranger", "stranger@example.com", "stranger", "stranger@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.email, func(t *testing.T) {
+			name, email := mm.lookup(tt.name, tt.email)
+			if name != tt.wantName || email != tt.wantEmail {
+				t.Errorf("lookup(%q, %q) = %q, %q; want %q, %q", tt.name, tt.email, name, email, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package git
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v6/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// lineEdit replaces base lines [start, end) with lines.
+type lineEdit struct {
+	start int
+	end   int
+	lines []string
+	ours  bool
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func lineEdits(base, other string, ours bool) []lineEdit {
+	var edits []lineEdit
+	var cur *lineEdit
+	pos := 0
+
+	for _, d := range diff.Do(base, other) {
+		lines := splitLines(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			if cur != nil {
+				edits = append(edits, *cur)
+				cur = nil
+			}
+			pos += len(lines)
+		case diffmatchpatch.DiffDelete:
+			if cur == nil {
+				cur = &lineEdit{start: pos, end: pos, ours: ours}
+			}
+			cur.end += len(lines)
+			pos += len(lines)
+		case diffmatchpatch.DiffInsert:
+			if cur == nil {
+				cur = &lineEdit{start: pos, end: pos, ours: ours}
+			}
+			cur.lines = append(cur.lines, lines...)
+		}
+	}
+	if cur != nil {
+		edits = append(edits, *cur)
+	}
+	return edits
+}
+
+func applyRegion(base []string, edits []lineEdit, start, end int) []string {
+	var out []string
+	pos := start
+	for _, e := range edits {
+		out = append(out, base[pos:e.start]...)
+		out = append(out, e.lines...)
+		pos = e.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+func writeRegion(b *strings.Builder, lines []string) {
+	for _, l := range lines {
+		b.WriteString(l)
+	}
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		b.WriteString("\n")
+	}
+}
+
+// merge3 performs a line-based three-way merge of ours and theirs against
+// base. Overlapping or adjacent changes that differ are written out with
+// conflict markers and reported as conflicted.
+func merge3(base, ours, theirs, theirsLabel string) (string, bool) {
+	baseLines := splitLines(base)
+	oursEdits := lineEdits(base, ours, true)
+	theirsEdits := lineEdits(base, theirs, false)
+
+	var b strings.Builder
+	conflicted := false
+	pos := 0
+	i, j := 0, 0
+
+	for i < len(oursEdits) || j < len(theirsEdits) {
+		var group []lineEdit
+		if j >= len(theirsEdits) || (i < len(oursEdits) && oursEdits[i].start <= theirsEdits[j].start) {
+			group = append(group, oursEdits[i])
+			i++
+		} else {
+			group = append(group, theirsEdits[j])
+			j++
+		}
+		start, end := group[0].start, group[0].end
+
+		for {
+			if i < len(oursEdits) && oursEdits[i].start <= end {
+				group = append(group, oursEdits[i])
+				end = max(end, oursEdits[i].end)
+				i++
+				continue
+			}
+			if j < len(theirsEdits) && theirsEdits[j].start <= end {
+				group = append(group, theirsEdits[j])
+				end = max(end, theirsEdits[j].end)
+				j++
+				continue
+			}
+			break
+		}
+
+		var oursGroup, theirsGroup []lineEdit
+		for _, e := range group {
+			if e.ours {
+				oursGroup = append(oursGroup, e)
+			} else {
+				theirsGroup = append(theirsGroup, e)
+			}
+		}
+
+		for _, l := range baseLines[pos:start] {
+			b.WriteString(l)
+		}
+		pos = end
+
+		oursRegion := applyRegion(baseLines, oursGroup, start, end)
+		theirsRegion := applyRegion(baseLines, theirsGroup, start, end)
+
+		switch {
+		case len(theirsGroup) == 0:
+			for _, l := range oursRegion {
+				b.WriteString(l)
+			}
+		case len(oursGroup) == 0:
+			for _, l := range theirsRegion {
+				b.WriteString(l)
+			}
+		case strings.Join(oursRegion, "") == strings.Join(theirsRegion, ""):
+			for _, l := range oursRegion {
+				b.WriteString(l)
+			}
+		default:
+			conflicted = true
+			b.WriteString("<<<<<<< HEAD\n")
+			writeRegion(&b, oursRegion)
+			b.WriteString("=======\n")
+			writeRegion(&b, theirsRegion)
+			b.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+	}
+
+	for _, l := range baseLines[pos:] {
+		b.WriteString(l)
+	}
+
+	return b.String(), conflicted
+}
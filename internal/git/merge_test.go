@@ -0,0 +1,284 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name       string
+		base       string
+		ours       string
+		theirs     string
+		want       string
+		conflicted bool
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "separate hunks",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:   "theirs appends, ours prepends",
+			base:   "a\nb\n",
+			ours:   "start\na\nb\n",
+			theirs: "a\nb\nend\n",
+			want:   "start\na\nb\nend\n",
+		},
+		{
+			name:   "theirs deletes lines",
+			base:   "a\nb\nc\nd\n",
+			ours:   "A\nb\nc\nd\n",
+			theirs: "a\nb\n",
+			want:   "A\nb\n",
+		},
+		{
+			name:       "conflicting change",
+			base:       "a\nb\nc\n",
+			ours:       "a\nours\nc\n",
+			theirs:     "a\ntheirs\nc\n",
+			want:       "a\n<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> abc1234\nc\n",
+			conflicted: true,
+		},
+		{
+			name:       "adjacent changes conflict",
+			base:       "a\nb\nc\n",
+			ours:       "a\nB\nc\n",
+			theirs:     "a\nb\nC\n",
+			want:       "a\n<<<<<<< HEAD\nB\nc\n=======\nb\nC\n>>>>>>> abc1234\n",
+			conflicted: true,
+		},
+		{
+			name:       "change against delete",
+			base:       "a\nb\nc\n",
+			ours:       "a\nB\nc\n",
+			theirs:     "a\nc\n",
+			want:       "a\n<<<<<<< HEAD\nB\n=======\n>>>>>>> abc1234\nc\n",
+			conflicted: true,
+		},
+		{
+			name:       "missing final newline",
+			base:       "a",
+			ours:       "ours",
+			theirs:     "theirs",
+			want:       "<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> abc1234\n",
+			conflicted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicted := merge3(tt.base, tt.ours, tt.theirs, "abc1234")
+			if got != tt.want || conflicted != tt.conflicted {
+				t.Errorf("merge3() = %q, %v; want %q, %v", got, conflicted, tt.want, tt.conflicted)
+			}
+		})
+	}
+}
+
+func TestMergeTrees(t *testing.T) {
+	binary := "\x00\x01bin"
+
+	tests := []struct {
+		name       string
+		base       map[string]string
+		ours       map[string]string
+		theirs     map[string]string
+		theirsExec []string
+		want       map[string]string // worktree after the merge; nil for no file
+		wantMode   map[string]filemode.FileMode
+		conflicts  []string
+	}{
+		{
+			name:   "theirs adds a file",
+			base:   map[string]string{"a": "a\n"},
+			ours:   map[string]string{"a": "a\n"},
+			theirs: map[string]string{"a": "a\n", "new": "new\n"},
+			want:   map[string]string{"a": "a\n", "new": "new\n"},
+		},
+		{
+			name:   "theirs deletes an unchanged file",
+			base:   map[string]string{"a": "a\n", "gone": "gone\n"},
+			ours:   map[string]string{"a": "a\n", "gone": "gone\n"},
+			theirs: map[string]string{"a": "a\n"},
+			want:   map[string]string{"a": "a\n"},
+		},
+		{
+			name:      "theirs deletes a file ours changed",
+			base:      map[string]string{"a": "a\n"},
+			ours:      map[string]string{"a": "ours\n"},
+			theirs:    map[string]string{},
+			want:      map[string]string{"a": "ours\n"},
+			conflicts: []string{"a"},
+		},
+		{
+			name:      "both add a file differently",
+			base:      map[string]string{},
+			ours:      map[string]string{"a": "ours\n"},
+			theirs:    map[string]string{"a": "theirs\n"},
+			want:      map[string]string{"a": "ours\n"},
+			conflicts: []string{"a"},
+		},
+		{
+			name:   "theirs changes a binary file",
+			base:   map[string]string{"b": binary},
+			ours:   map[string]string{"b": binary},
+			theirs: map[string]string{"b": binary + "2"},
+			want:   map[string]string{"b": binary + "2"},
+		},
+		{
+			name:      "both change a binary file",
+			base:      map[string]string{"b": binary},
+			ours:      map[string]string{"b": binary + "ours"},
+			theirs:    map[string]string{"b": binary + "theirs"},
+			want:      map[string]string{"b": binary + "ours"},
+			conflicts: []string{"b"},
+		},
+		{
+			name:       "theirs makes a file executable",
+			base:       map[string]string{"run": "echo\n", "a": "a\n"},
+			ours:       map[string]string{"run": "echo\n", "a": "A\n"},
+			theirs:     map[string]string{"run": "echo\n", "a": "a\n"},
+			theirsExec: []string{"run"},
+			want:       map[string]string{"run": "echo\n", "a": "A\n"},
+			wantMode:   map[string]filemode.FileMode{"run": filemode.Executable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			base := commitFiles(t, repo, wt, tt.base, nil)
+			theirs := commitFiles(t, repo, wt, tt.theirs, tt.theirsExec)
+			ours := commitFiles(t, repo, wt, tt.ours, nil)
+
+			conflicts, err := mergeTrees(wt, base, ours, theirs, "theirs", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+
+			got := readFiles(t, dir)
+			for path, content := range tt.want {
+				if got[path] != content {
+					t.Errorf("%s = %q, want %q", path, got[path], content)
+				}
+			}
+			for path := range got {
+				if _, ok := tt.want[path]; !ok {
+					t.Errorf("%s should not exist", path)
+				}
+			}
+			for path, mode := range tt.wantMode {
+				fi, err := os.Lstat(filepath.Join(dir, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, _ := filemode.NewFromOSFileMode(fi.Mode()); got != mode {
+					t.Errorf("%s mode = %s, want %s", path, got, mode)
+				}
+			}
+		})
+	}
+}
+
+// commitFiles makes the worktree hold exactly files, with exec executable,
+// commits it and returns the commit's tree.
+func commitFiles(t *testing.T, repo *git.Repository, wt *git.Worktree, files map[string]string, exec []string) *object.Tree {
+	t.Helper()
+	root := wt.Filesystem.Root()
+	for path := range readFiles(t, root) {
+		if err := os.Remove(filepath.Join(root, path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range files {
+		perm := os.FileMode(0o644)
+		if slices.Contains(exec, path) {
+			perm = 0o755
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	hash, err := wt.Commit("commit", &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// readFiles returns the files at the top of dir by name.
+func readFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(data)
+	}
+	return files
+}
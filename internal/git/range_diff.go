@@ -0,0 +1,302 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// Two patches are paired when their diff is smaller than this percentage of
+// their combined size, like git range-diff's default creation factor.
+const rangeDiffCreationFactor = 60
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+\d+(?:,\d+)? @@ ?`)
+
+type rangeCommit struct {
+	commit *object.Commit
+	patch  []string
+}
+
+// RangeDiff pairs up the commits of two ranges, like git range-diff, and
+// returns them in the order of the new range with dropped commits kept in
+// place. Ranges are written <base>..<tip>. With an empty newRange, oldRange
+// may be the symmetric form <rev1>...<rev2>, meaning rev2..rev1 and rev1..rev2.
+func (s *Service) RangeDiff(oldRange, newRange string) ([]types.RangeDiffPair, error) {
+	if newRange == "" {
+		rev1, rev2, ok := strings.Cut(oldRange, "...")
+		if !ok {
+			return nil, fmt.Errorf("invalid range %q: expected <rev1>...<rev2> or two ranges", oldRange)
+		}
+		if rev1 == "" {
+			rev1 = "HEAD"
+		}
+		if rev2 == "" {
+			rev2 = "HEAD"
+		}
+		oldRange, newRange = rev2+".."+rev1, rev1+".."+rev2
+	}
+
+	olds, err := s.rangeCommits(oldRange)
+	if err != nil {
+		return nil, err
+	}
+	news, err := s.rangeCommits(newRange)
+	if err != nil {
+		return nil, err
+	}
+
+	oldMatch := make([]int, len(olds))
+	newMatch := make([]int, len(news))
+	for i := range oldMatch {
+		oldMatch[i] = -1
+	}
+	for i := range newMatch {
+		newMatch[i] = -1
+	}
+
+	// Identical patches first, so a reordered series still pairs exactly
+	for j, n := range news {
+		for i, o := range olds {
+			if oldMatch[i] == -1 && equalLines(o.patch, n.patch) {
+				oldMatch[i], newMatch[j] = j, i
+				break
+			}
+		}
+	}
+
+	// Then the cheapest remaining pairs that are cheaper than treating both
+	// commits as dropped and added
+	type candidate struct{ i, j, cost int }
+	var candidates []candidate
+	for i, o := range olds {
+		if oldMatch[i] != -1 {
+			continue
+		}
+		for j, n := range news {
+			if newMatch[j] != -1 {
+				continue
+			}
+			cost := diffSize(o.patch, n.patch)
+			if cost*100 < (len(o.patch)+len(n.patch))*rangeDiffCreationFactor {
+				candidates = append(candidates, candidate{i, j, cost})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].cost < candidates[b].cost
+	})
+	for _, c := range candidates {
+		if oldMatch[c.i] == -1 && newMatch[c.j] == -1 {
+			oldMatch[c.i], newMatch[c.j] = c.j, c.i
+		}
+	}
+
+	var pairs []types.RangeDiffPair
+	i := 0
+	emitDropped := func(limit int) {
+		for ; i < limit; i++ {
+			if oldMatch[i] == -1 {
+				pairs = append(pairs, types.RangeDiffPair{
+					Status:   "dropped",
+					OldIndex: i + 1,
+					Old:      s.graphCommit(olds[i].commit),
+					Diff:     patchDiffLines(olds[i].patch, "del"),
+				})
+			}
+		}
+	}
+
+	for j, n := range news {
+		k := newMatch[j]
+		if k == -1 {
+			pairs = append(pairs, types.RangeDiffPair{
+				Status:   "added",
+				NewIndex: j + 1,
+				New:      s.graphCommit(n.commit),
+				Diff:     patchDiffLines(n.patch, "add"),
+			})
+			continue
+		}
+
+		// Dropped commits are shown just before the next surviving old one
+		emitDropped(k)
+		if i == k {
+			i++
+		}
+
+		pair := types.RangeDiffPair{
+			Status:   "equal",
+			OldIndex: k + 1,
+			NewIndex: j + 1,
+			Old:      s.graphCommit(olds[k].commit),
+			New:      s.graphCommit(n.commit),
+			Diff:     diffOfDiffs(olds[k].patch, n.patch),
+		}
+		if !equalLines(olds[k].patch, n.patch) {
+			pair.Status = "modified"
+		}
+		pairs = append(pairs, pair)
+	}
+	emitDropped(len(olds))
+
+	return pairs, nil
+}
+
+// rangeCommits lists the non-merge commits of <base>..<tip>, oldest first.
+func (s *Service) rangeCommits(rng string) ([]rangeCommit, error) {
+	base, tip, ok := strings.Cut(rng, "..")
+	if !ok || strings.HasPrefix(tip, ".") {
+		return nil, fmt.Errorf("invalid range %q: expected <base>..<tip>", rng)
+	}
+	if base == "" {
+		base = "HEAD"
+	}
+	if tip == "" {
+		tip = "HEAD"
+	}
+
+	baseHash, err := s.resolveRevision(base)
+	if err != nil {
+		return nil, err
+	}
+	tipHash, err := s.resolveRevision(tip)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := s.uniqueCommits(tipHash, baseHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []rangeCommit
+	for k := len(commits) - 1; k >= 0; k-- {
+		c := commits[k]
+		if len(c.ParentHashes) > 1 {
+			continue
+		}
+		patch, err := rangePatch(c)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rangeCommit{commit: c, patch: patch})
+	}
+	return result, nil
+}
+
+// rangePatch renders a commit as comparable text: the indented message
+// followed by its diff, with hashes and line numbers stripped so that a
+// rebased commit compares equal to the original.
+func rangePatch(c *object.Commit) ([]string, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(c.Message), "\n") {
+		lines = append(lines, strings.TrimRight("    "+line, " \t"))
+	}
+	lines = append(lines, "")
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if len(c.ParentHashes) == 1 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+
+	inHeader := false
+	for _, line := range strings.Split(strings.TrimSuffix(patch.String(), "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader = true
+			path := line[strings.LastIndex(line, " b/")+3:]
+			lines = append(lines, "## "+path+" ##")
+		case inHeader && strings.HasPrefix(line, "@@"):
+			inHeader = false
+			lines = append(lines, strings.TrimRight("@@ "+hunkHeaderRe.ReplaceAllString(line, ""), " "))
+		case inHeader && (strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ")):
+			continue
+		case inHeader:
+			lines = append(lines, line)
+		case hunkHeaderRe.MatchString(line):
+			lines = append(lines, strings.TrimRight("@@ "+hunkHeaderRe.ReplaceAllString(line, ""), " "))
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diffSize counts the lines added and removed between two patches.
+func diffSize(a, b []string) int {
+	size := 0
+	for _, d := range diff.Do(joinLines(a), joinLines(b)) {
+		if d.Type != diffmatchpatch.DiffEqual {
+			size += len(splitLines(d.Text))
+		}
+	}
+	return size
+}
+
+func diffOfDiffs(a, b []string) []types.DiffLine {
+	var lines []types.DiffLine
+	for _, d := range diff.Do(joinLines(a), joinLines(b)) {
+		lineType := "equal"
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			lineType = "add"
+		case diffmatchpatch.DiffDelete:
+			lineType = "del"
+		}
+		for _, line := range splitLines(d.Text) {
+			lines = append(lines, types.DiffLine{Type: lineType, Content: strings.TrimSuffix(line, "\n")})
+		}
+	}
+	return lines
+}
+
+func patchDiffLines(patch []string, lineType string) []types.DiffLine {
+	lines := make([]types.DiffLine, len(patch))
+	for i, line := range patch {
+		lines[i] = types.DiffLine{Type: lineType, Content: line}
+	}
+	return lines
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,223 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// seriesCommit is one commit of a patch series: it sets file to content.
+type seriesCommit struct {
+	message string
+	file    string
+	content string
+}
+
+func TestRangeDiff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	a := seriesCommit{"Change a", "a", tenLines("a", 3)}
+	b := seriesCommit{"Change b", "b", tenLines("b", 5)}
+	c := seriesCommit{"Change c", "c", tenLines("c", 8)}
+	aReworked := seriesCommit{"Change a", "a", tenLines("a", 3, 7)}
+	aReworded := seriesCommit{"Change a differently", "a", tenLines("a", 3)}
+	unrelated := seriesCommit{"Add notes", "notes", "nothing like the others\n"}
+
+	type pair struct {
+		status   string
+		old, new int
+	}
+
+	tests := []struct {
+		name string
+		old  []seriesCommit
+		new  []seriesCommit
+		want []pair
+	}{
+		{
+			name: "same series",
+			old:  []seriesCommit{a, b},
+			new:  []seriesCommit{a, b},
+			want: []pair{{"equal", 1, 1}, {"equal", 2, 2}},
+		},
+		{
+			name: "reordered",
+			old:  []seriesCommit{a, b},
+			new:  []seriesCommit{b, a},
+			want: []pair{{"equal", 2, 1}, {"equal", 1, 2}},
+		},
+		{
+			name: "patch reworked",
+			old:  []seriesCommit{a, b},
+			new:  []seriesCommit{aReworked, b},
+			want: []pair{{"modified", 1, 1}, {"equal", 2, 2}},
+		},
+		{
+			name: "message reworded",
+			old:  []seriesCommit{a},
+			new:  []seriesCommit{aReworded},
+			want: []pair{{"modified", 1, 1}},
+		},
+		{
+			name: "middle commit dropped",
+			old:  []seriesCommit{a, b, c},
+			new:  []seriesCommit{a, c},
+			want: []pair{{"equal", 1, 1}, {"dropped", 2, 0}, {"equal", 3, 2}},
+		},
+		{
+			name: "first commit dropped",
+			old:  []seriesCommit{a, b},
+			new:  []seriesCommit{b},
+			want: []pair{{"dropped", 1, 0}, {"equal", 2, 1}},
+		},
+		{
+			name: "commit added",
+			old:  []seriesCommit{a},
+			new:  []seriesCommit{a, c},
+			want: []pair{{"equal", 1, 1}, {"added", 0, 2}},
+		},
+		{
+			name: "commit replaced",
+			old:  []seriesCommit{a},
+			new:  []seriesCommit{unrelated},
+			want: []pair{{"added", 0, 1}, {"dropped", 1, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			base := commitSeries(t, wt, []seriesCommit{
+				{"Base", "a", tenLines("a")},
+				{"Base", "b", tenLines("b")},
+				{"Base", "c", tenLines("c")},
+			})
+			oldTip := commitSeries(t, wt, tt.old)
+			resetBranch(t, wt, base)
+			newTip := commitSeries(t, wt, tt.new)
+
+			for name, hash := range map[string]plumbing.Hash{"base": base, "old": oldTip, "new": newTip} {
+				ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash)
+				if err := repo.Storer.SetReference(ref); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s, err := NewService(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pairs, err := s.RangeDiff("base..old", "base..new")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []pair
+			for _, p := range pairs {
+				got = append(got, pair{p.Status, p.OldIndex, p.NewIndex})
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("RangeDiff() = %v, want %v", got, tt.want)
+			}
+			for _, p := range pairs {
+				if p.Status == "equal" && hasChangedLines(p.Diff) {
+					t.Errorf("equal pair %d-%d has a non-empty diff", p.OldIndex, p.NewIndex)
+				}
+				if p.Status == "modified" && !hasChangedLines(p.Diff) {
+					t.Errorf("modified pair %d-%d has an empty diff", p.OldIndex, p.NewIndex)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeDiffInvalidRange(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{repo: repo}
+
+	tests := []struct{ oldRange, newRange string }{
+		{"main", ""},
+		{"main..feature", ""},
+		{"main", "main..feature"},
+		{"main...feature", "main..feature"},
+	}
+	for _, tt := range tests {
+		if _, err := s.RangeDiff(tt.oldRange, tt.newRange); err == nil || !strings.Contains(err.Error(), "invalid range") {
+			t.Errorf("RangeDiff(%q, %q) error = %v, want an invalid range", tt.oldRange, tt.newRange, err)
+		}
+	}
+}
+
+// tenLines returns a ten-line file for name with the given lines changed.
+func tenLines(name string, changed ...int) string {
+	var sb strings.Builder
+	for i := 1; i <= 10; i++ {
+		line := fmt.Sprintf("%s%d", name, i)
+		for _, c := range changed {
+			if c == i {
+				line = strings.ToUpper(line)
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// commitSeries commits each change on top of HEAD and returns the last
+// commit.
+func commitSeries(t *testing.T, wt *git.Worktree, series []seriesCommit) plumbing.Hash {
+	t.Helper()
+	var hash plumbing.Hash
+	for _, c := range series {
+		if err := os.WriteFile(filepath.Join(wt.Filesystem.Root(), c.file), []byte(c.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(c.file); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+		var err error
+		if hash, err = wt.Commit(c.message, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hash
+}
+
+// resetBranch moves the checked-out branch, index and worktree to hash.
+func resetBranch(t *testing.T, wt *git.Worktree, hash plumbing.Hash) {
+	t.Helper()
+	if err := wt.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasChangedLines(lines []types.DiffLine) bool {
+	for _, l := range lines {
+		if l.Type != "equal" {
+			return true
+		}
+	}
+	return false
+}
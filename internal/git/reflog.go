@@ -0,0 +1,191 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/storage/filesystem"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// GetReflog returns the reflog of HEAD, or of a local branch, newest first.
+func (s *Service) GetReflog(ref string) ([]types.ReflogEntry, error) {
+	refName := plumbing.HEAD
+	if ref != "" && ref != "HEAD" {
+		refName = plumbing.NewBranchReferenceName(ref)
+	}
+
+	entries, err := s.readReflog(refName)
+	if err != nil {
+		return nil, err
+	}
+
+	var reflog []types.ReflogEntry
+	for i, e := range entries {
+		// "reset: moving to HEAD~1" -> action "reset"
+		action, message, found := strings.Cut(e.Message, ": ")
+		if !found {
+			action, message = e.Message, ""
+		}
+
+		entry := types.ReflogEntry{
+			Selector:    fmt.Sprintf("%s@{%d}", refName.Short(), i),
+			NewHash:     e.New.String()[:7],
+			FullNewHash: e.New.String(),
+			Action:      action,
+			Message:     message,
+			Date:        e.When,
+		}
+		if !e.Old.IsZero() {
+			entry.OldHash = e.Old.String()[:7]
+		}
+		reflog = append(reflog, entry)
+	}
+	return reflog, nil
+}
+
+// reflogEntry is one line of a .git/logs file
+type reflogEntry struct {
+	Old       plumbing.Hash
+	New       plumbing.Hash
+	Committer string
+	Email     string
+	When      time.Time
+	Message   string
+}
+
+// readReflog returns the reflog of ref, newest entry first. A ref without a
+// reflog returns no entries and no error.
+func (s *Service) readReflog(ref plumbing.ReferenceName) ([]reflogEntry, error) {
+	storage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errors.New("reflog is only available for on-disk repositories")
+	}
+
+	f, err := storage.Filesystem().Open(storage.Filesystem().Join("logs", ref.String()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []reflogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if entry, ok := parseReflogLine(scanner.Text()); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// parseReflogLine parses "<old> <new> <name> <<email>> <unix> <tz>\t<message>"
+func parseReflogLine(line string) (reflogEntry, bool) {
+	header, message, _ := strings.Cut(line, "\t")
+
+	fields := strings.SplitN(header, " ", 3)
+	if len(fields) < 3 {
+		return reflogEntry{}, false
+	}
+	oldHash, ok1 := plumbing.FromHex(fields[0])
+	newHash, ok2 := plumbing.FromHex(fields[1])
+	if !ok1 || !ok2 {
+		return reflogEntry{}, false
+	}
+
+	ident := fields[2]
+	emailStart := strings.LastIndex(ident, "<")
+	emailEnd := strings.LastIndex(ident, ">")
+	if emailStart == -1 || emailEnd < emailStart {
+		return reflogEntry{}, false
+	}
+
+	entry := reflogEntry{
+		Old:       oldHash,
+		New:       newHash,
+		Committer: strings.TrimSpace(ident[:emailStart]),
+		Email:     ident[emailStart+1 : emailEnd],
+		Message:   message,
+	}
+
+	stamp := strings.Fields(ident[emailEnd+1:])
+	if len(stamp) >= 1 {
+		if secs, err := strconv.ParseInt(stamp[0], 10, 64); err == nil {
+			entry.When = time.Unix(secs, 0)
+			if len(stamp) >= 2 {
+				if tz, ok := parseTimezone(stamp[1]); ok {
+					entry.When = entry.When.In(tz)
+				}
+			}
+		}
+	}
+
+	return entry, true
+}
+
+// parseTimezone turns a "+hhmm" or "-hhmm" offset into a fixed zone
+func parseTimezone(offset string) (*time.Location, bool) {
+	if len(offset) != 5 || (offset[0] != '+' && offset[0] != '-') {
+		return nil, false
+	}
+	hours, err1 := strconv.Atoi(offset[1:3])
+	minutes, err2 := strconv.Atoi(offset[3:5])
+	if err1 != nil || err2 != nil || minutes >= 60 {
+		return nil, false
+	}
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return time.FixedZone("", seconds), true
+}
+
+// writeReflog replaces the reflog of ref with entries, given newest first.
+func (s *Service) writeReflog(ref plumbing.ReferenceName, entries []reflogEntry) error {
+	storage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return errors.New("reflog is only available for on-disk repositories")
+	}
+	fs := storage.Filesystem()
+	path := fs.Join("logs", ref.String())
+
+	if len(entries) == 0 {
+		if err := fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, err := f.Write([]byte(formatReflogLine(entries[i]))); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func formatReflogLine(e reflogEntry) string {
+	return fmt.Sprintf("%s %s %s <%s> %d %s\t%s\n",
+		e.Old, e.New, e.Committer, e.Email, e.When.Unix(), e.When.Format("-0700"), e.Message)
+}
@@ -0,0 +1,246 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+const (
+	reflogOld = "1111111111111111111111111111111111111111"
+	reflogNew = "2222222222222222222222222222222222222222"
+)
+
+func TestParseReflogLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantOK     bool
+		wantName   string
+		wantEmail  string
+		wantUnix   int64
+		wantOffset int
+		wantLocal  bool
+		wantMsg    string
+	}{
+		{
+			name:       "commit",
+			line:       reflogOld + " " + reflogNew + " Jane Doe <jane@example.com> 1700000000 +0100\tcommit: add f",
+			wantOK:     true,
+			wantName:   "Jane Doe",
+			wantEmail:  "jane@example.com",
+			wantUnix:   1700000000,
+			wantOffset: 3600,
+			wantMsg:    "commit: add f",
+		},
+		{
+			name:       "negative offset with minutes",
+			line:       reflogOld + " " + reflogNew + " Jane <jane@example.com> 1700000000 -0930\tcheckout: moving from a to b",
+			wantOK:     true,
+			wantName:   "Jane",
+			wantEmail:  "jane@example.com",
+			wantUnix:   1700000000,
+			wantOffset: -(9*3600 + 30*60),
+			wantMsg:    "checkout: moving from a to b",
+		},
+		{
+			name:      "no message",
+			line:      reflogOld + " " + reflogNew + " Jane <jane@example.com> 1700000000 +0000",
+			wantOK:    true,
+			wantName:  "Jane",
+			wantEmail: "jane@example.com",
+			wantUnix:  1700000000,
+		},
+		{
+			name:      "empty message",
+			line:      reflogOld + " " + reflogNew + " Jane <jane@example.com> 1700000000 +0000\t",
+			wantOK:    true,
+			wantName:  "Jane",
+			wantEmail: "jane@example.com",
+			wantUnix:  1700000000,
+		},
+		{
+			name:      "message with tabs",
+			line:      reflogOld + " " + reflogNew + " Jane <jane@example.com> 1700000000 +0000\tWIP\ton master",
+			wantOK:    true,
+			wantName:  "Jane",
+			wantEmail: "jane@example.com",
+			wantUnix:  1700000000,
+			wantMsg:   "WIP\ton master",
+		},
+		{
+			name:      "bad timezone keeps local time",
+			line:      reflogOld + " " + reflogNew + " Jane <jane@example.com> 1700000000 0100\tmsg",
+			wantOK:    true,
+			wantLocal: true,
+			wantName:  "Jane",
+			wantEmail: "jane@example.com",
+			wantUnix:  1700000000,
+			wantMsg:   "msg",
+		},
+		{
+			name:      "no timestamp",
+			line:      reflogOld + " " + reflogNew + " Jane <jane@example.com>\tmsg",
+			wantOK:    true,
+			wantName:  "Jane",
+			wantEmail: "jane@example.com",
+			wantMsg:   "msg",
+		},
+		{
+			name: "empty line",
+			line: "",
+		},
+		{
+			name: "truncated",
+			line: reflogOld + " " + reflogNew,
+		},
+		{
+			name: "bad old hash",
+			line: "not-a-hash " + reflogNew + " Jane <jane@example.com> 1700000000 +0000\tmsg",
+		},
+		{
+			name: "short new hash",
+			line: reflogOld + " 2222222 Jane <jane@example.com> 1700000000 +0000\tmsg",
+		},
+		{
+			name: "no email",
+			line: reflogOld + " " + reflogNew + " Jane 1700000000 +0000\tmsg",
+		},
+		{
+			name: "unclosed email",
+			line: reflogOld + " " + reflogNew + " Jane <jane@example.com 1700000000 +0000\tmsg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := parseReflogLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseReflogLine() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if entry.Old.String() != reflogOld || entry.New.String() != reflogNew {
+				t.Errorf("hashes = %s %s", entry.Old, entry.New)
+			}
+			if entry.Committer != tt.wantName || entry.Email != tt.wantEmail {
+				t.Errorf("ident = %q <%q>, want %q <%q>", entry.Committer, entry.Email, tt.wantName, tt.wantEmail)
+			}
+			if entry.Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", entry.Message, tt.wantMsg)
+			}
+			if tt.wantUnix == 0 {
+				if !entry.When.IsZero() {
+					t.Errorf("when = %v, want zero", entry.When)
+				}
+				return
+			}
+			if entry.When.Unix() != tt.wantUnix {
+				t.Errorf("unix = %d, want %d", entry.When.Unix(), tt.wantUnix)
+			}
+			if tt.wantLocal {
+				if entry.When.Location() != time.Local {
+					t.Errorf("location = %v, want local", entry.When.Location())
+				}
+			} else if _, offset := entry.When.Zone(); offset != tt.wantOffset {
+				t.Errorf("offset = %d, want %d", offset, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestReflogLineRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 3600, -(4*3600 + 30*60), 14 * 3600} {
+		entry := reflogEntry{
+			Old:       plumbing.NewHash(reflogOld),
+			New:       plumbing.NewHash(reflogNew),
+			Committer: "Jane Doe",
+			Email:     "jane@example.com",
+			When:      time.Unix(1700000000, 0).In(time.FixedZone("", offset)),
+			Message:   "On master: WIP",
+		}
+		line := formatReflogLine(entry)
+		got, ok := parseReflogLine(line[:len(line)-1])
+		if !ok {
+			t.Fatalf("parseReflogLine(%q) failed", line)
+		}
+		if got.Old != entry.Old || got.New != entry.New || got.Committer != entry.Committer ||
+			got.Email != entry.Email || got.Message != entry.Message || !got.When.Equal(entry.When) {
+			t.Errorf("round trip of %q = %+v", line, got)
+		}
+		if got.When.Format("-0700") != entry.When.Format("-0700") {
+			t.Errorf("zone = %s, want %s", got.When.Format("-0700"), entry.When.Format("-0700"))
+		}
+	}
+}
+
+func TestWriteReflog(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{repo: repo}
+	ref := plumbing.ReferenceName("refs/stash")
+	path := filepath.Join(dir, ".git", "logs", "refs", "stash")
+
+	if entries, err := s.readReflog(ref); err != nil || entries != nil {
+		t.Fatalf("readReflog() without a log = %v, %v; want nothing", entries, err)
+	}
+
+	tz := time.FixedZone("", -5*3600)
+	entries := []reflogEntry{
+		{Old: plumbing.NewHash(reflogNew), New: plumbing.NewHash(reflogOld), Committer: "B", Email: "b@example.com", When: time.Unix(1700000100, 0).In(tz), Message: "On master: second"},
+		{Old: plumbing.ZeroHash, New: plumbing.NewHash(reflogNew), Committer: "A", Email: "a@example.com", When: time.Unix(1700000000, 0).In(tz)},
+	}
+	if err := s.writeReflog(ref, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file holds the oldest entry first, as git writes it
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := formatReflogLine(entries[1]) + formatReflogLine(entries[0])
+	if string(data) != want {
+		t.Errorf("log file = %q, want %q", data, want)
+	}
+
+	got, err := s.readReflog(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(entries) {
+		t.Fatalf("readReflog() = %d entries, want %d", len(got), len(entries))
+	}
+	for i := range entries {
+		if got[i].Old != entries[i].Old || got[i].New != entries[i].New || got[i].Message != entries[i].Message ||
+			!got[i].When.Equal(entries[i].When) || got[i].When.Format("-0700") != "-0500" {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], entries[i])
+		}
+	}
+
+	// Malformed lines are skipped rather than failing the read
+	if err := os.WriteFile(path, append([]byte("garbage\n"), data...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.readReflog(ref); err != nil || len(got) != 2 {
+		t.Errorf("readReflog() with a bad line = %d entries, %v; want 2", len(got), err)
+	}
+
+	if err := s.writeReflog(ref, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("empty reflog left the file: %v", err)
+	}
+	if err := s.writeReflog(ref, nil); err != nil {
+		t.Errorf("removing a missing reflog = %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+var ErrDetachedHead = errors.New("HEAD is detached")
+var ErrNotFastForward = errors.New("branch has diverged from its upstream; pull can only fast-forward")
+
+// PushRejectedError is returned when the remote branch has commits the local
+// branch does not, so pushing would discard them.
+type PushRejectedError struct {
+	Branch string
+	Remote string
+}
+
+func (e *PushRejectedError) Error() string {
+	return fmt.Sprintf("push of %s to %s rejected: non-fast-forward, pull first", e.Branch, e.Remote)
+}
+
+// Fetch updates the remote-tracking branches and tags of every remote.
+func (s *Service) Fetch(progress io.Writer) error {
+	remotes, err := s.repo.Remotes()
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		return errors.New("no remotes configured")
+	}
+
+	for _, remote := range remotes {
+		err := remote.Fetch(&git.FetchOptions{Progress: progress})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("fetch %s: %w", remote.Config().Name, err)
+		}
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
+// Pull fetches the upstream of the checked-out branch and fast-forwards the
+// branch, index and worktree to it. Diverged branches are left untouched.
+func (s *Service) Pull(progress io.Writer) error {
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	if !head.Name().IsBranch() {
+		return ErrDetachedHead
+	}
+	branch := head.Name().Short()
+
+	upstream, err := s.upstreamRef(branch)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := s.repo.Config()
+	if err != nil {
+		return err
+	}
+	if remoteName := cfg.Branches[branch].Remote; remoteName != "." {
+		remote, err := s.repo.Remote(remoteName)
+		if err != nil {
+			return err
+		}
+		err = remote.Fetch(&git.FetchOptions{Progress: progress})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("fetch %s: %w", remoteName, err)
+		}
+	}
+	s.BuildBranchMap()
+
+	ref, err := s.repo.Reference(upstream, true)
+	if err != nil {
+		return fmt.Errorf("upstream %s does not exist", upstream.Short())
+	}
+	if ref.Hash() == head.Hash() {
+		return nil
+	}
+
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	upstreamCommit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return err
+	}
+	if ahead, err := upstreamCommit.IsAncestor(headCommit); err != nil {
+		return err
+	} else if ahead {
+		return nil
+	}
+	if ff, err := headCommit.IsAncestor(upstreamCommit); err != nil {
+		return err
+	} else if !ff {
+		return ErrNotFastForward
+	}
+
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := checkClean(wt); err != nil {
+		return err
+	}
+	// Only the files that differ between the two commits are touched
+	if err := wt.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.MergeReset}); err != nil {
+		return err
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
+// Push pushes the checked-out branch to its upstream. A branch without an
+// upstream is pushed to a branch of the same name on origin (or the only
+// remote) and that becomes its upstream.
+func (s *Service) Push(progress io.Writer) error {
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	if !head.Name().IsBranch() {
+		return ErrDetachedHead
+	}
+	branch := head.Name().Short()
+
+	cfg, err := s.repo.Config()
+	if err != nil {
+		return err
+	}
+
+	remoteName, dst := "", head.Name()
+	setUpstream := false
+	if b, ok := cfg.Branches[branch]; ok && b.Remote != "" && b.Remote != "." && b.Merge != "" {
+		remoteName, dst = b.Remote, b.Merge
+	} else {
+		remoteName, err = s.defaultRemote(cfg)
+		if err != nil {
+			return err
+		}
+		setUpstream = true
+	}
+
+	remote, err := s.repo.Remote(remoteName)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPushFastForward(remote, dst, head.Hash()); err != nil {
+		if errors.Is(err, errPushNotFastForward) {
+			return &PushRejectedError{Branch: branch, Remote: remoteName}
+		}
+		return fmt.Errorf("push %s to %s: %w", branch, remoteName, err)
+	}
+
+	spec := config.RefSpec(head.Name().String() + ":" + dst.String())
+	err = s.repo.Push(&git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{spec},
+		Progress:   progress,
+	})
+	switch {
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		err = nil
+	case err != nil && pushRejected(err):
+		return &PushRejectedError{Branch: branch, Remote: remoteName}
+	case err != nil:
+		return fmt.Errorf("push %s to %s: %w", branch, remoteName, err)
+	}
+
+	if setUpstream {
+		cfg.Branches[branch] = &config.Branch{Name: branch, Remote: remoteName, Merge: dst}
+		if err := s.repo.SetConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
+var errPushNotFastForward = errors.New("remote branch is not an ancestor of the local one")
+
+// checkPushFastForward lists the remote's refs and fails with
+// errPushNotFastForward when dst exists there and is not an ancestor of
+// local, including when its commit has not been fetched.
+func (s *Service) checkPushFastForward(remote *git.Remote, dst plumbing.ReferenceName, local plumbing.Hash) error {
+	refs, err := remote.List(&git.ListOptions{})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.Name() != dst || ref.Hash() == local {
+			continue
+		}
+		remoteCommit, err := s.repo.CommitObject(ref.Hash())
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return errPushNotFastForward
+		}
+		if err != nil {
+			return err
+		}
+		localCommit, err := s.repo.CommitObject(local)
+		if err != nil {
+			return err
+		}
+		if ok, err := remoteCommit.IsAncestor(localCommit); err != nil {
+			return err
+		} else if !ok {
+			return errPushNotFastForward
+		}
+	}
+	return nil
+}
+
+// pushRejected reports whether the remote refused the update because its
+// branch moved on since the check above.
+func pushRejected(err error) bool {
+	var status packp.CommandStatusErr
+	if errors.As(err, &status) {
+		return strings.Contains(status.Status, "non-fast-forward") || strings.Contains(status.Status, "fetch first")
+	}
+	return errors.Is(err, git.ErrNonFastForwardUpdate)
+}
+
+func (s *Service) defaultRemote(cfg *config.Config) (string, error) {
+	if _, ok := cfg.Remotes["origin"]; ok {
+		return "origin", nil
+	}
+	if len(cfg.Remotes) == 1 {
+		for name := range cfg.Remotes {
+			return name, nil
+		}
+	}
+	if len(cfg.Remotes) == 0 {
+		return "", errors.New("no remotes configured")
+	}
+	return "", errors.New("branch has no upstream and there is no origin remote")
+}
@@ -0,0 +1,291 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/protocol/packp"
+)
+
+// remoteURLs are the two ways a remote on the same machine is named.
+var remoteURLs = []struct {
+	name string
+	url  func(path string) string
+}{
+	{"local path", func(path string) string { return path }},
+	{"file URL", func(path string) string { return "file://" + path }},
+}
+
+// clonePair makes a bare repository with one commit on master and two
+// clones of it reached through url, each with master tracking origin.
+func clonePair(t *testing.T, url func(string) string) (bare, a, b string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	bare = t.TempDir()
+	if _, err := git.PlainInit(bare, true); err != nil {
+		t.Fatal(err)
+	}
+
+	seed := t.TempDir()
+	repo, err := git.PlainInit(seed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, map[string]string{"f": "one\n"}, nil)
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url(bare)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Push(&git.PushOptions{RemoteName: "origin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b = t.TempDir(), t.TempDir()
+	for _, dir := range []string{a, b} {
+		if _, err := git.PlainClone(dir, &git.CloneOptions{URL: url(bare)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bare, a, b
+}
+
+// commitAndPush commits files in the clone at dir and pushes master.
+func commitAndPush(t *testing.T, dir string, files map[string]string) plumbing.Hash {
+	t.Helper()
+	hash := commitIn(t, dir, files)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Push(&git.PushOptions{RemoteName: "origin"}); err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// commitIn commits files in the repository at dir and returns the commit.
+func commitIn(t *testing.T, dir string, files map[string]string) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, files, nil)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return head.Hash()
+}
+
+func refHash(t *testing.T, dir string, name plumbing.ReferenceName) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.Reference(name, true)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return ref.Hash()
+}
+
+func openService(t *testing.T, dir string) *Service {
+	t.Helper()
+	s, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFetch(t *testing.T) {
+	for _, remote := range remoteURLs {
+		t.Run(remote.name, func(t *testing.T) {
+			_, a, b := clonePair(t, remote.url)
+			pushed := commitAndPush(t, a, map[string]string{"f": "two\n"})
+
+			if err := openService(t, b).Fetch(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := refHash(t, b, plumbing.NewRemoteReferenceName("origin", "master")); got != pushed {
+				t.Errorf("origin/master = %s, want %s", got, pushed)
+			}
+			if got := refHash(t, b, plumbing.NewBranchReferenceName("master")); got == pushed {
+				t.Error("fetch moved master")
+			}
+		})
+	}
+}
+
+func TestPull(t *testing.T) {
+	for _, remote := range remoteURLs {
+		t.Run(remote.name, func(t *testing.T) {
+			_, a, b := clonePair(t, remote.url)
+			pushed := commitAndPush(t, a, map[string]string{"f": "two\n", "g": "new\n"})
+
+			if err := openService(t, b).Pull(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := refHash(t, b, plumbing.HEAD); got != pushed {
+				t.Errorf("HEAD = %s, want %s", got, pushed)
+			}
+			files := readFiles(t, b)
+			if files["f"] != "two\n" || files["g"] != "new\n" {
+				t.Errorf("worktree = %q, want the pulled files", files)
+			}
+		})
+	}
+}
+
+func TestPullDirtyWorktree(t *testing.T) {
+	_, a, b := clonePair(t, remoteURLs[0].url)
+	commitAndPush(t, a, map[string]string{"f": "two\n"})
+	before := refHash(t, b, plumbing.HEAD)
+	if err := os.WriteFile(filepath.Join(b, "f"), []byte("local edit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := openService(t, b).Pull(nil)
+	if !errors.Is(err, ErrDirtyWorktree) {
+		t.Fatalf("Pull() = %v, want ErrDirtyWorktree", err)
+	}
+	if got := refHash(t, b, plumbing.HEAD); got != before {
+		t.Errorf("HEAD moved to %s", got)
+	}
+	if got := readFiles(t, b)["f"]; got != "local edit\n" {
+		t.Errorf("f = %q, want the local edit kept", got)
+	}
+}
+
+func TestPullDiverged(t *testing.T) {
+	_, a, b := clonePair(t, remoteURLs[0].url)
+	commitAndPush(t, a, map[string]string{"f": "two\n"})
+	local := commitIn(t, b, map[string]string{"f": "mine\n"})
+
+	if err := openService(t, b).Pull(nil); !errors.Is(err, ErrNotFastForward) {
+		t.Fatalf("Pull() = %v, want ErrNotFastForward", err)
+	}
+	if got := refHash(t, b, plumbing.HEAD); got != local {
+		t.Errorf("HEAD moved to %s", got)
+	}
+}
+
+func TestPushSetsUpstream(t *testing.T) {
+	for _, remote := range remoteURLs {
+		t.Run(remote.name, func(t *testing.T) {
+			bare, _, b := clonePair(t, remote.url)
+			repo, err := git.PlainOpen(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			topic := plumbing.NewBranchReferenceName("topic")
+			if err := wt.Checkout(&git.CheckoutOptions{Branch: topic, Create: true}); err != nil {
+				t.Fatal(err)
+			}
+			local := commitIn(t, b, map[string]string{"f": "topic\n"})
+
+			if err := openService(t, b).Push(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := refHash(t, bare, topic); got != local {
+				t.Errorf("remote topic = %s, want %s", got, local)
+			}
+			cfg, err := repo.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			branch := cfg.Branches["topic"]
+			if branch == nil || branch.Remote != "origin" || branch.Merge != topic {
+				t.Errorf("topic's upstream = %+v, want origin's refs/heads/topic", branch)
+			}
+
+			// A second push goes to the upstream it set
+			again := commitIn(t, b, map[string]string{"f": "topic 2\n"})
+			if err := openService(t, b).Push(nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := refHash(t, bare, topic); got != again {
+				t.Errorf("remote topic = %s, want %s", got, again)
+			}
+		})
+	}
+}
+
+func TestPushRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		fetch bool // whether the clone has the remote's new commit
+	}{
+		{"remote commit not fetched", false},
+		{"remote commit fetched", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bare, a, b := clonePair(t, remoteURLs[0].url)
+			pushed := commitAndPush(t, a, map[string]string{"f": "theirs\n"})
+			commitIn(t, b, map[string]string{"f": "mine\n"})
+			s := openService(t, b)
+			if tt.fetch {
+				if err := s.Fetch(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := s.Push(nil)
+			var rejected *PushRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("Push() = %v, want a PushRejectedError", err)
+			}
+			if rejected.Branch != "master" || rejected.Remote != "origin" {
+				t.Errorf("rejected = %+v, want master to origin", rejected)
+			}
+			if got := refHash(t, bare, plumbing.NewBranchReferenceName("master")); got != pushed {
+				t.Errorf("remote master = %s, want it left at %s", got, pushed)
+			}
+		})
+	}
+}
+
+func TestPushRejectedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"remote refused non-fast-forward", packp.CommandStatusErr{ReferenceName: "refs/heads/master", Status: "non-fast-forward"}, true},
+		{"remote refused fetch first", packp.CommandStatusErr{ReferenceName: "refs/heads/master", Status: "fetch first"}, true},
+		{"remote refused for another reason", packp.CommandStatusErr{ReferenceName: "refs/heads/master", Status: "pre-receive hook declined"}, false},
+		{"wrapped sentinel", fmt.Errorf("push: %w", git.ErrNonFastForwardUpdate), true},
+		{"unrelated error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pushRejected(tt.err); got != tt.want {
+				t.Errorf("pushRejected(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package git
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// AmbiguousRevisionError is returned when a name matches more than one
+// commit, for example a branch and a tag of the same name.
+type AmbiguousRevisionError struct {
+	Revision   string
+	Candidates []string
+}
+
+func (e *AmbiguousRevisionError) Error() string {
+	return fmt.Sprintf("ambiguous revision %q: could be %s", e.Revision, strings.Join(e.Candidates, ", "))
+}
+
+// ResolveRevision resolves a git revision expression to a full commit hash.
+func (s *Service) ResolveRevision(rev string) (string, error) {
+	hash, err := s.resolveRevision(rev)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// resolveRevision understands the common gitrevisions(7) forms: full and
+// short hashes, branch, tag and remote names on any remote, HEAD and @,
+// <rev>~n, <rev>^n, <rev>^{/text}, <branch>@{upstream}, @{-n},
+// <ref>@{n} and <ref>@{date}.
+func (s *Service) resolveRevision(rev string) (plumbing.Hash, error) {
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return plumbing.ZeroHash, errors.New("revision is empty")
+	}
+
+	base, modifier, suffix, err := splitRevision(rev)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	var hash plumbing.Hash
+	if modifier == "" {
+		hash, err = s.resolveName(base)
+	} else {
+		hash, err = s.resolveAt(base, modifier)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if suffix == "" {
+		return s.peelToCommit(hash)
+	}
+
+	// go-git handles the ~ and ^ navigation once the base is a plain hash
+	resolved, err := s.repo.ResolveRevision(plumbing.Revision(hash.String() + suffix))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision: %s", rev)
+	}
+	return *resolved, nil
+}
+
+// splitRevision splits "main@{1}~2" into "main", "1" and "~2".
+func splitRevision(rev string) (base, modifier, suffix string, err error) {
+	end := len(rev)
+	if i := strings.IndexAny(rev, "~^"); i != -1 {
+		end = i
+	}
+	if i := strings.Index(rev, "@{"); i != -1 && i < end {
+		end = i
+	}
+	if strings.Contains(rev[:end], ":") {
+		return "", "", "", fmt.Errorf("path revisions are not supported: %s", rev)
+	}
+
+	base, rest := rev[:end], rev[end:]
+	if base == "@" {
+		base = "HEAD"
+	}
+
+	if strings.HasPrefix(rest, "@{") {
+		closing := strings.Index(rest, "}")
+		if closing == -1 {
+			return "", "", "", fmt.Errorf("unterminated @{ in revision: %s", rev)
+		}
+		modifier = rest[2:closing]
+		rest = rest[closing+1:]
+		if modifier == "" {
+			return "", "", "", fmt.Errorf("empty @{} in revision: %s", rev)
+		}
+	} else if base == "" {
+		return "", "", "", fmt.Errorf("invalid revision: %s", rev)
+	}
+
+	if strings.Contains(rest, "@{") {
+		return "", "", "", fmt.Errorf("only one @{...} is supported: %s", rev)
+	}
+
+	return base, modifier, rest, nil
+}
+
+// resolveName resolves a bare name the way git's ref lookup rules do, and
+// reports an error instead of guessing when several candidates disagree.
+func (s *Service) resolveName(name string) (plumbing.Hash, error) {
+	type candidate struct {
+		label string
+		hash  plumbing.Hash
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+
+	for _, rule := range plumbing.RefRevParseRules {
+		refName := plumbing.ReferenceName(fmt.Sprintf(rule, name))
+		if seen[refName.String()] {
+			continue
+		}
+		ref, err := s.repo.Reference(refName, true)
+		if err != nil {
+			continue
+		}
+		hash, err := s.peelToCommit(ref.Hash())
+		if err != nil {
+			continue
+		}
+		seen[refName.String()] = true
+		candidates = append(candidates, candidate{label: refName.String(), hash: hash})
+	}
+
+	for _, h := range s.commitsWithPrefix(name) {
+		candidates = append(candidates, candidate{label: "commit " + h.String()[:12], hash: h})
+	}
+
+	// Branches on any remote, e.g. "feature" for "upstream/feature"
+	if len(candidates) == 0 {
+		remotes, err := s.repo.Remotes()
+		if err == nil {
+			for _, remote := range remotes {
+				refName := plumbing.NewRemoteReferenceName(remote.Config().Name, name)
+				ref, err := s.repo.Reference(refName, true)
+				if err != nil {
+					continue
+				}
+				candidates = append(candidates, candidate{label: refName.String(), hash: ref.Hash()})
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision: %s", name)
+	}
+
+	first := candidates[0]
+	var labels []string
+	for _, c := range candidates {
+		if c.hash != first.hash {
+			for _, c := range candidates {
+				labels = append(labels, c.label)
+			}
+			return plumbing.ZeroHash, &AmbiguousRevisionError{Revision: name, Candidates: labels}
+		}
+	}
+	return first.hash, nil
+}
+
+// commitsWithPrefix returns commits (or tags pointing at commits) whose hash
+// starts with prefix.
+func (s *Service) commitsWithPrefix(prefix string) []plumbing.Hash {
+	if len(prefix) < 4 || len(prefix) > plumbing.ZeroHash.HexSize() {
+		return nil
+	}
+	prefix = strings.ToLower(prefix)
+
+	lookup, ok := s.repo.Storer.(interface {
+		HashesWithPrefix(prefix []byte) ([]plumbing.Hash, error)
+	})
+	if !ok {
+		return nil
+	}
+
+	even := prefix[:len(prefix)&^1]
+	raw, err := hex.DecodeString(even)
+	if err != nil {
+		return nil
+	}
+	hashes, err := lookup.HashesWithPrefix(raw)
+	if err != nil {
+		return nil
+	}
+
+	var commits []plumbing.Hash
+	seen := make(map[plumbing.Hash]bool)
+	for _, h := range hashes {
+		if !strings.HasPrefix(h.String(), prefix) {
+			continue
+		}
+		commit, err := s.peelToCommit(h)
+		if err != nil || seen[commit] {
+			continue
+		}
+		seen[commit] = true
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+func (s *Service) peelToCommit(hash plumbing.Hash) (plumbing.Hash, error) {
+	if _, err := s.repo.CommitObject(hash); err == nil {
+		return hash, nil
+	}
+	tag, err := s.repo.TagObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%s is not a commit", hash.String()[:7])
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return commit.Hash, nil
+}
+
+// resolveAt handles the @{...} forms.
+func (s *Service) resolveAt(base, modifier string) (plumbing.Hash, error) {
+	switch {
+	case modifier == "upstream" || modifier == "u":
+		branch, err := s.branchForAt(base)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		upstream, err := s.upstreamRef(branch)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		ref, err := s.repo.Reference(upstream, true)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("upstream %s of %s does not exist", upstream.Short(), branch)
+		}
+		return ref.Hash(), nil
+
+	case strings.HasPrefix(modifier, "-"):
+		if base != "" {
+			return plumbing.ZeroHash, fmt.Errorf("@{%s} cannot follow a ref name", modifier)
+		}
+		n, err := strconv.Atoi(modifier[1:])
+		if err != nil || n < 1 {
+			return plumbing.ZeroHash, fmt.Errorf("invalid @{%s}", modifier)
+		}
+		branch, err := s.previousCheckout(n)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		// @{-n} names a branch, so a tag of the same name must not shadow it
+		if ref, err := s.repo.Reference(plumbing.NewBranchReferenceName(branch), true); err == nil {
+			return ref.Hash(), nil
+		}
+		return s.resolveName(branch)
+	}
+
+	refName, err := s.refForAt(base)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	entries, err := s.readReflog(refName)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("no reflog for %s", refName.Short())
+	}
+
+	if n, err := strconv.Atoi(modifier); err == nil {
+		if n < 0 || n >= len(entries) {
+			return plumbing.ZeroHash, fmt.Errorf("reflog for %s has only %d entries", refName.Short(), len(entries))
+		}
+		return entries[n].New, nil
+	}
+
+	date, err := parseApproxDate(modifier, time.Now())
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	for _, entry := range entries {
+		if !entry.When.After(date) {
+			return entry.New, nil
+		}
+	}
+	// Older than the whole reflog: git falls back to the oldest entry
+	oldest := entries[len(entries)-1]
+	if oldest.Old.IsZero() {
+		return oldest.New, nil
+	}
+	return oldest.Old, nil
+}
+
+// branchForAt returns the local branch an @{...} applies to, using the
+// checked-out branch when base is empty or HEAD.
+func (s *Service) branchForAt(base string) (string, error) {
+	if base != "" && base != "HEAD" {
+		return strings.TrimPrefix(base, "refs/heads/"), nil
+	}
+	head, err := s.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", errors.New("HEAD is detached")
+	}
+	return head.Name().Short(), nil
+}
+
+func (s *Service) refForAt(base string) (plumbing.ReferenceName, error) {
+	if base == "" {
+		branch, err := s.branchForAt("")
+		if err != nil {
+			return plumbing.HEAD, nil
+		}
+		return plumbing.NewBranchReferenceName(branch), nil
+	}
+	if base == "HEAD" {
+		return plumbing.HEAD, nil
+	}
+	for _, rule := range plumbing.RefRevParseRules {
+		refName := plumbing.ReferenceName(fmt.Sprintf(rule, base))
+		if _, err := s.repo.Reference(refName, false); err == nil {
+			return refName, nil
+		}
+	}
+	return "", fmt.Errorf("unknown ref: %s", base)
+}
+
+// upstreamRef maps branch.<name>.remote and branch.<name>.merge to the
+// remote-tracking ref using the remote's fetch refspecs.
+func (s *Service) upstreamRef(branch string) (plumbing.ReferenceName, error) {
+	cfg, err := s.repo.Config()
+	if err != nil {
+		return "", err
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return "", fmt.Errorf("no upstream configured for branch %s", branch)
+	}
+
+	if b.Remote == "." {
+		return b.Merge, nil
+	}
+
+	if remote, ok := cfg.Remotes[b.Remote]; ok {
+		for _, spec := range remote.Fetch {
+			if spec.Match(b.Merge) {
+				return spec.Dst(b.Merge), nil
+			}
+		}
+	}
+	return plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short()), nil
+}
+
+var checkoutMessage = regexp.MustCompile(`^checkout: moving from (\S+) to \S+`)
+
+// previousCheckout returns the branch checked out n switches ago.
+func (s *Service) previousCheckout(n int) (string, error) {
+	entries, err := s.readReflog(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		match := checkoutMessage.FindStringSubmatch(entry.Message)
+		if match == nil {
+			continue
+		}
+		n--
+		if n == 0 {
+			return match[1], nil
+		}
+	}
+	return "", errors.New("not enough checkouts in the HEAD reflog")
+}
+
+var relativeDate = regexp.MustCompile(`^(\d+)[ .]*(second|minute|hour|day|week|month|year)s?[ .]*ago$`)
+
+// parseApproxDate understands the date forms people actually type:
+// "now", "yesterday", "3 days ago", "2.weeks.ago" and absolute dates.
+func parseApproxDate(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	lower := strings.ToLower(text)
+
+	switch lower {
+	case "now":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+
+	if m := relativeDate.FindStringSubmatch(lower); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "second":
+			return now.Add(-time.Duration(n) * time.Second), nil
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), nil
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "day":
+			return now.AddDate(0, 0, -n), nil
+		case "week":
+			return now.AddDate(0, 0, -7*n), nil
+		case "month":
+			return now.AddDate(0, -n, 0), nil
+		case "year":
+			return now.AddDate(-n, 0, 0), nil
+		}
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, text, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date: %s", text)
+}
@@ -0,0 +1,263 @@
+package git
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+func TestSplitRevision(t *testing.T) {
+	tests := []struct {
+		rev      string
+		base     string
+		modifier string
+		suffix   string
+		wantErr  bool
+	}{
+		{rev: "main", base: "main"},
+		{rev: "@", base: "HEAD"},
+		{rev: "HEAD~2", base: "HEAD", suffix: "~2"},
+		{rev: "main^2~1", base: "main", suffix: "^2~1"},
+		{rev: "main^{/fix}", base: "main", suffix: "^{/fix}"},
+		{rev: "main@{1}", base: "main", modifier: "1"},
+		{rev: "main@{1}~2", base: "main", modifier: "1", suffix: "~2"},
+		{rev: "@{upstream}", modifier: "upstream"},
+		{rev: "@{-1}", modifier: "-1"},
+		{rev: "HEAD@{2 days ago}", base: "HEAD", modifier: "2 days ago"},
+		{rev: "feature/login", base: "feature/login"},
+		{rev: "main:README.md", wantErr: true},
+		{rev: "main@{1", wantErr: true},
+		{rev: "main@{}", wantErr: true},
+		{rev: "main@{1}@{2}", wantErr: true},
+		{rev: "~1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rev, func(t *testing.T) {
+			base, modifier, suffix, err := splitRevision(tt.rev)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("splitRevision(%q) = %q, %q, %q; want an error", tt.rev, base, modifier, suffix)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitRevision(%q): %v", tt.rev, err)
+			}
+			if base != tt.base || modifier != tt.modifier || suffix != tt.suffix {
+				t.Errorf("splitRevision(%q) = %q, %q, %q; want %q, %q, %q",
+					tt.rev, base, modifier, suffix, tt.base, tt.modifier, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestParseApproxDate(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text    string
+		want    time.Time
+		wantErr bool
+	}{
+		{text: "now", want: now},
+		{text: " Now ", want: now},
+		{text: "yesterday", want: time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)},
+		{text: "30 seconds ago", want: now.Add(-30 * time.Second)},
+		{text: "1 minute ago", want: now.Add(-time.Minute)},
+		{text: "5 hours ago", want: now.Add(-5 * time.Hour)},
+		{text: "3 days ago", want: time.Date(2024, 3, 12, 12, 0, 0, 0, time.UTC)},
+		{text: "2.weeks.ago", want: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{text: "1 month ago", want: time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC)},
+		{text: "2 years ago", want: time.Date(2022, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{text: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{text: "2024-01-02 10:30:00", want: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)},
+		{text: "2024-01-02T10:30:00", want: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)},
+		{text: "2024-01-02T10:30:00+01:00", want: time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)},
+		{text: "2024-01-02T10:30:00Z", want: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)},
+		{text: "last tuesday", wantErr: true},
+		{text: "3 fortnights ago", wantErr: true},
+		{text: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseApproxDate(tt.text, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseApproxDate(%q) = %v; want an error", tt.text, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseApproxDate(%q): %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseApproxDate(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRevision(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commits []plumbing.Hash
+	for _, content := range []string{"one\n", "two\n", "three\n"} {
+		commitFiles(t, repo, wt, map[string]string{"f": content}, nil)
+		head, err := repo.Head()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, head.Hash())
+	}
+	first, second, third := commits[0], commits[1], commits[2]
+
+	refs := map[plumbing.ReferenceName]plumbing.Hash{
+		plumbing.NewBranchReferenceName("feature"):          second,
+		plumbing.NewTagReferenceName("v1"):                  first,
+		plumbing.NewBranchReferenceName("same"):             third,
+		plumbing.NewTagReferenceName("same"):                third,
+		plumbing.NewBranchReferenceName("dup"):              first,
+		plumbing.NewTagReferenceName("dup"):                 second,
+		plumbing.NewRemoteReferenceName("upstream", "only"): first,
+	}
+	for name, hash := range refs {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "upstream", URLs: []string{"https://example.com/repo.git"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rev       string
+		want      plumbing.Hash
+		ambiguous bool
+		wantErr   bool
+	}{
+		{rev: "HEAD", want: third},
+		{rev: "@", want: third},
+		{rev: "master", want: third},
+		{rev: "refs/heads/master", want: third},
+		{rev: "HEAD~1", want: second},
+		{rev: "@^", want: second},
+		{rev: "master~2", want: first},
+		{rev: "feature", want: second},
+		{rev: "feature^", want: first},
+		{rev: "v1", want: first},
+		{rev: "same", want: third},
+		{rev: "only", want: first},
+		{rev: "upstream/only", want: first},
+		{rev: second.String(), want: second},
+		{rev: second.String()[:7], want: second},
+		{rev: "  " + first.String()[:10] + "  ", want: first},
+		{rev: "dup", ambiguous: true},
+		{rev: "missing", wantErr: true},
+		{rev: "master~5", wantErr: true},
+		{rev: "", wantErr: true},
+		{rev: "master:f", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rev, func(t *testing.T) {
+			got, err := s.resolveRevision(tt.rev)
+			var ambiguous *AmbiguousRevisionError
+			switch {
+			case tt.ambiguous:
+				if !errors.As(err, &ambiguous) {
+					t.Fatalf("resolveRevision(%q) = %v, %v; want an AmbiguousRevisionError", tt.rev, got, err)
+				}
+				if len(ambiguous.Candidates) != 2 {
+					t.Errorf("candidates = %v, want the branch and the tag", ambiguous.Candidates)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Fatalf("resolveRevision(%q) = %v; want an error", tt.rev, got)
+				}
+			case err != nil:
+				t.Fatalf("resolveRevision(%q): %v", tt.rev, err)
+			case got != tt.want:
+				t.Errorf("resolveRevision(%q) = %v, want %v", tt.rev, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitsRevision(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetCommits("", 10, types.CommitFilter{}); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("GetCommits() on an unborn branch = %v, want ErrNoCommits", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, map[string]string{"f": "one\n"}, nil)
+	commitFiles(t, repo, wt, map[string]string{"f": "two\n"}, nil)
+
+	tests := []struct {
+		rev     string
+		want    int
+		wantErr string
+	}{
+		{rev: "", want: 2},
+		{rev: "HEAD~1", want: 1},
+		{rev: "missing", wantErr: "unknown revision: missing"},
+		{rev: "HEAD~5", wantErr: "HEAD~5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rev, func(t *testing.T) {
+			commits, err := s.GetCommits(tt.rev, 10, types.CommitFilter{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetCommits(%q) = %d commits, %v; want an error containing %q", tt.rev, len(commits), err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(commits) != tt.want {
+				t.Errorf("GetCommits(%q) = %d commits, want %d", tt.rev, len(commits), tt.want)
+			}
+		})
+	}
+}
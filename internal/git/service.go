@@ -1,56 +1,116 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
 	"github.com/go-git/go-git/v6/plumbing/format/diff"
 	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+	"github.com/tomiwa-a/git-radar/internal/config"
 	"github.com/tomiwa-a/git-radar/internal/types"
 	"github.com/tomiwa-a/git-radar/utils"
 )
 
 type Service struct {
-	repo      *git.Repository
+	repo    *git.Repository
+	signers signers
+	config  config.Config
+
+	// branchMap is rebuilt from Cmd goroutines while others read it
+	branchMu  sync.RWMutex
 	branchMap map[string][]string
+
+	signersErr error
+	configErr  error
 }
 
 func NewService(path string) (*Service, error) {
 	repo, err := git.PlainOpen(path)
 	if err != nil {
-		return nil, err
+		return nil, openError(path, err)
 	}
 	s := &Service{repo: repo}
-	s.BuildBranchMap()
+	local, err := repo.Config()
+	if err != nil {
+		return nil, opError("read config", err)
+	}
+	s.config, s.configErr = config.Load(local.Raw)
+	if err := s.BuildBranchMap(); err != nil {
+		return nil, err
+	}
+	s.signersErr = s.loadSigners()
 	return s, nil
 }
 
-func (s *Service) BuildBranchMap() {
-	s.branchMap = make(map[string][]string)
-	branches, _ := s.GetBranches()
+// SignersError reports the keys that could not be read when the service
+// was opened. Signatures by those keys show as made with an unknown key.
+func (s *Service) SignersError() error {
+	return s.signersErr
+}
+
+// ConfigError reports the settings that could not be read when the service
+// was opened. Those settings keep their defaults.
+func (s *Service) ConfigError() error {
+	return s.configErr
+}
+
+// Config returns the settings in effect for this repository.
+func (s *Service) Config() config.Config {
+	return s.config
+}
+
+// BuildBranchMap refreshes the branch labels shown next to commits. Callers
+// that have just moved a ref may ignore the error; the old labels remain.
+func (s *Service) BuildBranchMap() error {
+	branches, err := s.GetBranches()
+	if err != nil {
+		return err
+	}
+	branchMap := make(map[string][]string)
 	for _, b := range branches {
-		s.branchMap[b.Hash] = append(s.branchMap[b.Hash], b.Name)
+		branchMap[b.Hash] = append(branchMap[b.Hash], b.Name)
 	}
+
+	s.branchMu.Lock()
+	s.branchMap = branchMap
+	s.branchMu.Unlock()
+	return nil
+}
+
+// branchesAt returns the names of the branches pointing at hash.
+func (s *Service) branchesAt(hash string) []string {
+	s.branchMu.RLock()
+	defer s.branchMu.RUnlock()
+	return s.branchMap[hash]
 }
 
 func (s *Service) GetBranches() ([]types.Branch, error) {
 	var branches []types.Branch
 
-	head, err := s.repo.Head()
-	if err != nil {
-		return nil, err
+	// An unborn HEAD has no branches yet, which is not an error
+	headBranch := ""
+	head, err := s.head()
+	switch {
+	case err == nil:
+		headBranch = head.Name().String()
+	case !errors.Is(err, ErrNoCommits):
+		return nil, opError("list branches", err)
 	}
-	headBranch := head.Name().String()
 
 	// Get local branches
 	branchIter, err := s.repo.Branches()
 	if err != nil {
-		return nil, err
+		return nil, opError("list branches", err)
 	}
-	branchIter.ForEach(func(ref *plumbing.Reference) error {
+	err = branchIter.ForEach(func(ref *plumbing.Reference) error {
 		branch := types.Branch{
 			Name:     ref.Name().Short(),
 			FullName: ref.Name().String(),
@@ -61,45 +121,202 @@ func (s *Service) GetBranches() ([]types.Branch, error) {
 		branches = append(branches, branch)
 		return nil
 	})
+	if err != nil {
+		return nil, opError("list branches", err)
+	}
 
 	// Get remote branches
+	remotes, err := s.GetRemotes()
+	if err != nil {
+		return nil, opError("list remotes", err)
+	}
 	remoteIter, err := s.repo.References()
 	if err != nil {
-		return nil, err
+		return nil, opError("list branches", err)
 	}
-	remoteIter.ForEach(func(ref *plumbing.Reference) error {
-		if ref.Name().IsRemote() {
+	err = remoteIter.ForEach(func(ref *plumbing.Reference) error {
+		// Skip symbolic refs such as origin/HEAD
+		if ref.Name().IsRemote() && ref.Type() == plumbing.HashReference {
 			branch := types.Branch{
 				Name:     ref.Name().Short(),
 				FullName: ref.Name().String(),
 				Hash:     ref.Hash().String(),
 				IsRemote: true,
 				IsHead:   false,
+				Remote:   remoteOf(ref.Name(), remotes),
 			}
 			branches = append(branches, branch)
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, opError("list remote branches", err)
+	}
 
-	// Sort: local first, then remote, alphabetically within each group
+	// Sort: local first, then remote grouped by remote, alphabetically within each group
 	sort.Slice(branches, func(i, j int) bool {
 		if branches[i].IsRemote != branches[j].IsRemote {
 			return !branches[i].IsRemote
 		}
+		if branches[i].Remote != branches[j].Remote {
+			return branches[i].Remote < branches[j].Remote
+		}
 		return branches[i].Name < branches[j].Name
 	})
 
 	return branches, nil
 }
 
+// GetRemotes returns the remotes configured for the repository, sorted by name.
+func (s *Service) GetRemotes() ([]types.Remote, error) {
+	cfg, err := s.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []types.Remote
+	for name, rc := range cfg.Remotes {
+		remotes = append(remotes, types.Remote{Name: name, URLs: rc.URLs})
+	}
+	sort.Slice(remotes, func(i, j int) bool {
+		return remotes[i].Name < remotes[j].Name
+	})
+
+	return remotes, nil
+}
+
+// remoteOf finds which remote a remote-tracking ref belongs to. Remote names
+// may contain slashes, so the longest matching name wins.
+func remoteOf(ref plumbing.ReferenceName, remotes []types.Remote) string {
+	short := strings.TrimPrefix(ref.String(), "refs/remotes/")
+	best := ""
+	for _, r := range remotes {
+		if strings.HasPrefix(short, r.Name+"/") && len(r.Name) > len(best) {
+			best = r.Name
+		}
+	}
+	if best == "" {
+		// Left behind by a remote that is no longer configured
+		best, _, _ = strings.Cut(short, "/")
+	}
+	return best
+}
+
+// GetTags returns tags in the same shape as branches so they can share the
+// ref pickers. Hash is the commit the tag points to.
+func (s *Service) GetTags() ([]types.Branch, error) {
+	var tags []types.Branch
+
+	tagIter, err := s.repo.Tags()
+	if err != nil {
+		return nil, opError("list tags", err)
+	}
+	err = tagIter.ForEach(func(ref *plumbing.Reference) error {
+		hash, err := s.repo.ResolveRevision(plumbing.Revision(ref.Name().String()))
+		if err != nil {
+			return nil
+		}
+		tag := types.Branch{
+			Name:     ref.Name().Short(),
+			FullName: ref.Name().String(),
+			Hash:     hash.String(),
+		}
+		if t, err := s.repo.TagObject(ref.Hash()); err == nil && t.PGPSignature != "" {
+			tag.Signature = types.Unverified
+		}
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, opError("list tags", err)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+// CreateBranch creates a local branch at the given commit without checking
+// it out.
+func (s *Service) CreateBranch(name, hash string) error {
+	refName := plumbing.NewBranchReferenceName(name)
+	if err := refName.Validate(); err != nil {
+		return fmt.Errorf("invalid branch name: %s", name)
+	}
+	if _, err := s.repo.Reference(refName, false); err == nil {
+		return fmt.Errorf("branch %s already exists", name)
+	}
+	if _, err := s.repo.CommitObject(plumbing.NewHash(hash)); err != nil {
+		return err
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, plumbing.NewHash(hash))); err != nil {
+		return err
+	}
+
+	s.BuildBranchMap()
+	return nil
+}
+
 func (s *Service) GetCurrentBranch() (string, error) {
-	head, err := s.repo.Head()
+	head, err := s.head()
+	if errors.Is(err, ErrNoCommits) {
+		// Still name the unborn branch HEAD points at
+		if ref, err := s.repo.Reference(plumbing.HEAD, false); err == nil {
+			return ref.Target().Short(), nil
+		}
+	}
 	if err != nil {
-		return "", err
+		return "", opError("read HEAD", err)
 	}
 	return head.Name().Short(), nil
 }
 
+// GetTree lists the directory dir ("" for the root) of a commit's tree,
+// directories first, then files, each alphabetically.
+func (s *Service) GetTree(commitHash, dir string) ([]types.TreeEntry, error) {
+	commit, err := s.repo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		tree, err = tree.Tree(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []types.TreeEntry
+	for _, e := range tree.Entries {
+		entry := types.TreeEntry{
+			Name:        e.Name,
+			Path:        path.Join(dir, e.Name),
+			IsDir:       e.Mode == filemode.Dir,
+			IsSubmodule: e.Mode == filemode.Submodule,
+		}
+		if !entry.IsDir && !entry.IsSubmodule {
+			if size, err := s.repo.Storer.EncodedObjectSize(e.Hash); err == nil {
+				entry.Size = size
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
 func (s *Service) GetFileContent(commitHash, filePath string) (string, error) {
 	hash := plumbing.NewHash(commitHash)
 	commit, err := s.repo.CommitObject(hash)
@@ -167,55 +384,77 @@ func (s *Service) GetFileDiff(commitHash, filePath string) ([]types.DiffLine, er
 			continue
 		}
 
-		var lines []types.DiffLine
-		for _, chunk := range fp.Chunks() {
-			content := chunk.Content()
-			chunkLines := strings.Split(content, "\n")
+		return filePatchLines(fp), nil
+	}
 
-			for i, line := range chunkLines {
-				if i == len(chunkLines)-1 && line == "" {
-					continue
-				}
-				var lineType string
-				switch chunk.Type() {
-				case diff.Add:
-					lineType = "add"
-				case diff.Delete:
-					lineType = "del"
-				default:
-					lineType = "equal"
-				}
-				lines = append(lines, types.DiffLine{Type: lineType, Content: line})
-			}
+	return nil, nil
+}
+
+// GetBranchFileDiff returns the diff of a single file between two branches,
+// from branch2 (or the merge base in three-dot mode) to branch1.
+func (s *Service) GetBranchFileDiff(branch1, branch2, filePath string, mode types.CompareMode) ([]types.DiffLine, error) {
+	patch, err := s.branchPatch(branch1, branch2, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		var patchPath string
+		if to != nil {
+			patchPath = to.Path()
+		} else if from != nil {
+			patchPath = from.Path()
+		}
+
+		if patchPath == filePath {
+			return filePatchLines(fp), nil
 		}
-		return lines, nil
 	}
 
 	return nil, nil
 }
 
-func (s *Service) GetCommits(branch string, limit int) ([]types.GraphCommit, error) {
+func filePatchLines(fp diff.FilePatch) []types.DiffLine {
+	var lines []types.DiffLine
+	for _, chunk := range fp.Chunks() {
+		content := chunk.Content()
+		chunkLines := strings.Split(content, "\n")
+
+		for i, line := range chunkLines {
+			if i == len(chunkLines)-1 && line == "" {
+				continue
+			}
+			var lineType string
+			switch chunk.Type() {
+			case diff.Add:
+				lineType = "add"
+			case diff.Delete:
+				lineType = "del"
+			default:
+				lineType = "equal"
+			}
+			lines = append(lines, types.DiffLine{Type: lineType, Content: line})
+		}
+	}
+	return lines
+}
+
+// GetCommits lists up to limit commits of branch, or of HEAD when branch is
+// empty, newest first but always children before parents, keeping only
+// those that match filter.
+func (s *Service) GetCommits(branch string, limit int, filter types.CommitFilter) ([]types.GraphCommit, error) {
 	var fromHash plumbing.Hash
 	if branch != "" {
-		ref, err := s.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		hash, err := s.resolveRevision(branch)
 		if err != nil {
-			ref, err = s.repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
-			if err != nil {
-				head, headErr := s.repo.Head()
-				if headErr != nil {
-					return nil, headErr
-				}
-				fromHash = head.Hash()
-			} else {
-				fromHash = ref.Hash()
-			}
-		} else {
-			fromHash = ref.Hash()
+			return nil, opError("load commits", err)
 		}
+		fromHash = hash
 	} else {
-		head, err := s.repo.Head()
+		head, err := s.head()
 		if err != nil {
-			return nil, err
+			return nil, opError("load commits", err)
 		}
 		fromHash = head.Hash()
 	}
@@ -225,41 +464,27 @@ func (s *Service) GetCommits(branch string, limit int) ([]types.GraphCommit, err
 		Order: git.LogOrderCommitterTime,
 	})
 	if err != nil {
-		return nil, err
+		return nil, opError("load commits", err)
 	}
 
+	matches := s.commitMatcher(filter)
+
 	var commits []types.GraphCommit
 	count := 0
 
 	err = commitIter.ForEach(func(c *object.Commit) error {
 		if count >= limit {
-			return nil
+			return storer.ErrStop
 		}
-
-		var parents []string
-		for _, p := range c.ParentHashes {
-			parents = append(parents, p.String())
-		}
-
-		graphChars := "* "
-		isMerge := len(parents) > 1
-		if isMerge {
-			graphChars = "*─┐"
+		if !matches(c) {
+			return nil
 		}
 
-		branchLabels := s.branchMap[c.Hash.String()]
-		message := strings.Split(strings.TrimSpace(c.Message), "\n")[0]
-
-		commit := types.GraphCommit{
-			Hash:       c.Hash.String()[:7],
-			FullHash:   c.Hash.String(),
-			Message:    message,
-			Author:     c.Author.Name,
-			Date:       utils.FormatRelativeTime(c.Author.When),
-			Parents:    parents,
-			Branches:   branchLabels,
-			GraphChars: graphChars,
-			IsMerge:    isMerge,
+		commit := s.graphCommit(c)
+		commit.Branches = s.branchesAt(c.Hash.String())
+		commit.GraphChars = "* "
+		if commit.IsMerge {
+			commit.GraphChars = "*─┐"
 		}
 
 		commits = append(commits, commit)
@@ -268,10 +493,11 @@ func (s *Service) GetCommits(branch string, limit int) ([]types.GraphCommit, err
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, opError("load commits", err)
 	}
 
-	return commits, nil
+	// Children before parents, so the graph's lanes join up
+	return utils.TopoOrder(commits), nil
 }
 
 func (s *Service) GetCommitDetails(fullHash string) ([]types.ParentInfo, []types.FileChange, error) {
@@ -287,7 +513,7 @@ func (s *Service) GetCommitDetails(fullHash string) ([]types.ParentInfo, []types
 			parentCommit, perr := s.repo.CommitObject(ph)
 			if perr == nil {
 				parentMsg := strings.Split(strings.TrimSpace(parentCommit.Message), "\n")[0]
-				parentBranches := s.branchMap[ph.String()]
+				parentBranches := s.branchesAt(ph.String())
 				branchName := ""
 				if len(parentBranches) > 0 {
 					branchName = parentBranches[0]
@@ -309,11 +535,16 @@ func (s *Service) GetCommitDetails(fullHash string) ([]types.ParentInfo, []types
 			if perr == nil {
 				for _, fp := range patch.FilePatches() {
 					from, to := fp.Files()
-					name := ""
-					if to != nil {
-						name = to.Path()
-					} else if from != nil {
+					var name, status string
+					if to == nil && from != nil {
 						name = from.Path()
+						status = "D"
+					} else if from == nil && to != nil {
+						name = to.Path()
+						status = "A"
+					} else if to != nil {
+						name = to.Path()
+						status = "M"
 					}
 					adds, dels := 0, 0
 					for _, chunk := range fp.Chunks() {
@@ -329,7 +560,7 @@ func (s *Service) GetCommitDetails(fullHash string) ([]types.ParentInfo, []types
 							dels += lines
 						}
 					}
-					files = append(files, types.FileChange{Status: "M", Path: name, Additions: adds, Deletions: dels})
+					files = append(files, types.FileChange{Status: status, Path: name, Additions: adds, Deletions: dels})
 				}
 			}
 		}
@@ -353,78 +584,33 @@ func (s *Service) GetCommitDetails(fullHash string) ([]types.ParentInfo, []types
 	return parentInfos, files, nil
 }
 
-func (s *Service) resolveBranchHash(branch string) (plumbing.Hash, error) {
-	if branch == "" {
-		return plumbing.ZeroHash, fmt.Errorf("branch name is empty")
-	}
-
-	// 1. Try exact match (could be full ref or hash)
-	ref, err := s.repo.Reference(plumbing.ReferenceName(branch), true)
-	if err == nil {
-		return ref.Hash(), nil
-	}
-
-	// 2. Try as local branch
-	ref, err = s.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
-	if err == nil {
-		return ref.Hash(), nil
-	}
-
-	// 3. Handle origin/ prefix
-	if strings.HasPrefix(branch, "origin/") {
-		shortName := branch[len("origin/"):]
-		ref, err = s.repo.Reference(plumbing.NewRemoteReferenceName("origin", shortName), true)
-		if err == nil {
-			return ref.Hash(), nil
-		}
-	}
-
-	// 4. Try as remote branch (implied origin)
-	ref, err = s.repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
-	if err == nil {
-		return ref.Hash(), nil
-	}
-
-	// 5. Try resolving as a short hash or tag maybe?
-	// But let's stick to branches for now as per requirements.
-
-	return plumbing.ZeroHash, fmt.Errorf("could not resolve branch: %s", branch)
-}
-
 func (s *Service) GetMergeBase(branch1, branch2 string) (*types.GraphCommit, error) {
-	hash1, err := s.resolveBranchHash(branch1)
+	hash1, err := s.resolveRevision(branch1)
 	if err != nil {
-		return nil, err
+		return nil, opError("find merge base", err)
 	}
-	hash2, err := s.resolveBranchHash(branch2)
+	hash2, err := s.resolveRevision(branch2)
 	if err != nil {
-		return nil, err
+		return nil, opError("find merge base", err)
 	}
 
 	commit1, err := s.repo.CommitObject(hash1)
 	if err != nil {
-		return nil, err
+		return nil, opError("find merge base", err)
 	}
 	commit2, err := s.repo.CommitObject(hash2)
 	if err != nil {
-		return nil, err
+		return nil, opError("find merge base", err)
 	}
 
+	// Unrelated histories have no merge base, which is not an error
 	bases, err := commit1.MergeBase(commit2)
 	if err != nil || len(bases) == 0 {
-		return nil, err
+		return nil, opError("find merge base", err)
 	}
 
-	base := bases[0]
-	message := strings.Split(strings.TrimSpace(base.Message), "\n")[0]
-
-	return &types.GraphCommit{
-		Hash:     base.Hash.String()[:7],
-		FullHash: base.Hash.String(),
-		Message:  message,
-		Author:   base.Author.Name,
-		Date:     utils.FormatRelativeTime(base.Author.When),
-	}, nil
+	base := s.graphCommit(bases[0])
+	return &base, nil
 }
 
 func (s *Service) GetIncomingCommits(target, source string) ([]types.GraphCommit, error) {
@@ -436,66 +622,117 @@ func (s *Service) GetOutgoingCommits(target, source string) ([]types.GraphCommit
 }
 
 func (s *Service) getCommitsBetween(branch1, branch2 string) ([]types.GraphCommit, error) {
-	hash1, err := s.resolveBranchHash(branch1)
+	op := "compare " + branch2 + " with " + branch1
+	hash1, err := s.resolveRevision(branch1)
 	if err != nil {
-		return nil, err
+		return nil, opError(op, err)
 	}
-	hash2, err := s.resolveBranchHash(branch2)
+	hash2, err := s.resolveRevision(branch2)
 	if err != nil {
-		return nil, err
+		return nil, opError(op, err)
 	}
 
-	commit2, err := s.repo.CommitObject(hash2)
+	unique, err := s.uniqueCommits(hash1, hash2)
+	if err != nil {
+		return nil, opError(op, err)
+	}
+
+	var commits []types.GraphCommit
+	for _, c := range unique {
+		commits = append(commits, s.graphCommit(c))
+	}
+
+	return commits, nil
+}
+
+// uniqueCommits returns the commits reachable from hash but not from exclude.
+func (s *Service) uniqueCommits(hash, exclude plumbing.Hash) ([]*object.Commit, error) {
+	reachable := make(map[plumbing.Hash]bool)
+	excludeIter, err := s.repo.Log(&git.LogOptions{From: exclude})
+	if err != nil {
+		return nil, err
+	}
+	err = excludeIter.ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	iter, err := s.repo.Log(&git.LogOptions{From: hash1})
+	iter, err := s.repo.Log(&git.LogOptions{From: hash})
 	if err != nil {
 		return nil, err
 	}
 
-	reachableFromBranch2 := make(map[string]bool)
-	iter2, _ := s.repo.Log(&git.LogOptions{From: hash2})
-	iter2.ForEach(func(c *object.Commit) error {
-		reachableFromBranch2[c.Hash.String()] = true
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !reachable[c.Hash] {
+			commits = append(commits, c)
+		}
 		return nil
 	})
-	_ = commit2
+	if err != nil {
+		return nil, err
+	}
 
-	var commits []types.GraphCommit
-	iter.ForEach(func(c *object.Commit) error {
-		if reachableFromBranch2[c.Hash.String()] {
-			return nil
-		}
+	return commits, nil
+}
 
-		message := strings.Split(strings.TrimSpace(c.Message), "\n")[0]
-		var parents []string
-		for _, p := range c.ParentHashes {
-			parents = append(parents, p.String())
+// logRange lists the commits of a range, newest first: <base>..<tip> for the
+// commits of tip that base lacks, a single revision for its whole history,
+// or HEAD's history when rng is empty.
+func (s *Service) logRange(rng string) ([]*object.Commit, error) {
+	if base, tip, ok := strings.Cut(rng, ".."); ok {
+		if strings.HasPrefix(tip, ".") {
+			return nil, fmt.Errorf("invalid range %q: expected <base>..<tip>", rng)
+		}
+		if base == "" {
+			base = "HEAD"
 		}
+		if tip == "" {
+			tip = "HEAD"
+		}
+		baseHash, err := s.resolveRevision(base)
+		if err != nil {
+			return nil, err
+		}
+		tipHash, err := s.resolveRevision(tip)
+		if err != nil {
+			return nil, err
+		}
+		return s.uniqueCommits(tipHash, baseHash)
+	}
 
-		commits = append(commits, types.GraphCommit{
-			Hash:     c.Hash.String()[:7],
-			FullHash: c.Hash.String(),
-			Message:  message,
-			Author:   c.Author.Name,
-			Date:     utils.FormatRelativeTime(c.Author.When),
-			Parents:  parents,
-			IsMerge:  len(parents) > 1,
-		})
+	if rng == "" {
+		rng = "HEAD"
+	}
+	hash, err := s.resolveRevision(rng)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := s.repo.Log(&git.LogOptions{From: hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	var commits []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
 		return nil
 	})
-
-	return commits, nil
+	return commits, err
 }
 
-func (s *Service) GetBranchDiffStats(branch1, branch2 string) ([]types.FileChange, error) {
-	hash1, err := s.resolveBranchHash(branch1)
+// branchPatch diffs branch2 against branch1. In three-dot mode the diff
+// starts from their merge base instead of branch2's tip, so changes made on
+// branch2 after the branch point are not shown as reverted. Unrelated
+// histories have no merge base, so they are diffed tip to tip.
+func (s *Service) branchPatch(branch1, branch2 string, mode types.CompareMode) (*object.Patch, error) {
+	hash1, err := s.resolveRevision(branch1)
 	if err != nil {
 		return nil, err
 	}
-	hash2, err := s.resolveBranchHash(branch2)
+	hash2, err := s.resolveRevision(branch2)
 	if err != nil {
 		return nil, err
 	}
@@ -509,9 +746,24 @@ func (s *Service) GetBranchDiffStats(branch1, branch2 string) ([]types.FileChang
 		return nil, err
 	}
 
-	patch, err := commit2.Patch(commit1)
+	from := commit2
+	if mode == types.ThreeDotCompare {
+		bases, err := commit1.MergeBase(commit2)
+		if err != nil {
+			return nil, err
+		}
+		if len(bases) > 0 {
+			from = bases[0]
+		}
+	}
+
+	return from.Patch(commit1)
+}
+
+func (s *Service) GetBranchDiffStats(branch1, branch2 string, mode types.CompareMode) ([]types.FileChange, error) {
+	patch, err := s.branchPatch(branch1, branch2, mode)
 	if err != nil {
-		return nil, err
+		return nil, opError("diff "+branch2+" and "+branch1, err)
 	}
 
 	var files []types.FileChange
@@ -0,0 +1,360 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// signers holds the keys signatures are checked against: an OpenPGP keyring
+// from the keyring setting (or ~/.gnupg/pubring.gpg) and SSH keys from git's
+// gpg.ssh.allowedSignersFile.
+type signers struct {
+	keyring openpgp.EntityList
+	ssh     []allowedSigner
+}
+
+type allowedSigner struct {
+	principals string
+	key        ssh.PublicKey
+}
+
+// sshSignature is the SSHSIG blob inside an "SSH SIGNATURE" armor.
+type sshSignature struct {
+	Version   uint32
+	PublicKey []byte
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Signature []byte
+}
+
+// loadSigners reads the keys signatures are checked against. Keys it cannot
+// read are left out, so their signatures show as unknown, and the reasons
+// are returned together. Only the default keyring may be missing.
+func (s *Service) loadSigners() error {
+	s.signers = signers{}
+	var errs []error
+
+	if path := s.config.Keyring; path != "" {
+		keyring, err := loadKeyRing(expandHome(path))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("keyring %s: %w", path, err))
+		}
+		s.signers.keyring = keyring
+	} else if home, err := os.UserHomeDir(); err == nil {
+		// Keybox files used by newer GnuPG cannot be read, only the legacy keyring
+		path := filepath.Join(home, ".gnupg", "pubring.gpg")
+		keyring, err := loadKeyRing(path)
+		if err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("keyring %s: %w", path, err))
+		}
+		s.signers.keyring = keyring
+	}
+
+	if path := s.configValue("gpg", "ssh", "allowedSignersFile"); path != "" {
+		data, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("gpg.ssh.allowedSignersFile: %w", err))
+		} else {
+			s.signers.ssh = parseAllowedSigners(data)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func loadKeyRing(path string) (openpgp.EntityList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readKeyRing(f)
+}
+
+// configValue reads a key from the repository config, falling back to the
+// user's global config.
+func (s *Service) configValue(section, subsection, key string) string {
+	var scopes []*config.Config
+	if cfg, err := s.repo.Config(); err == nil {
+		scopes = append(scopes, cfg)
+	}
+	if cfg, err := config.LoadConfig(config.GlobalScope); err == nil {
+		scopes = append(scopes, cfg)
+	}
+
+	for _, cfg := range scopes {
+		sec := cfg.Raw.Section(section)
+		if subsection != "" {
+			if !sec.HasSubsection(subsection) {
+				continue
+			}
+			if v := sec.Subsection(subsection).Option(key); v != "" {
+				return v
+			}
+			continue
+		}
+		if v := sec.Option(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// readKeyRing accepts both armored and binary keyrings.
+func readKeyRing(r io.Reader) (openpgp.EntityList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// parseAllowedSigners reads git's allowed signers format:
+// "principals [options] keytype base64-key [comment]".
+func parseAllowedSigners(data []byte) []allowedSigner {
+	var result []allowedSigner
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		principals, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil {
+			continue
+		}
+		if !allowsGitNamespace(options) {
+			continue
+		}
+		result = append(result, allowedSigner{principals: principals, key: key})
+	}
+	return result
+}
+
+func allowsGitNamespace(options []string) bool {
+	for _, opt := range options {
+		if value, ok := strings.CutPrefix(opt, "namespaces="); ok {
+			for _, ns := range strings.Split(strings.Trim(value, `"`), ",") {
+				if ns == "git" || ns == "*" {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// verifySignature checks signature over payload, returning the status and
+// who signed it: the key's identity when trusted, otherwise its key ID or
+// fingerprint.
+func (s *Service) verifySignature(signature string, payload []byte) (types.SignatureStatus, string) {
+	switch {
+	case signature == "":
+		return types.Unsigned, ""
+	case strings.HasPrefix(signature, "-----BEGIN PGP"):
+		return s.verifyPGP(signature, payload)
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		return s.verifySSH(signature, payload)
+	default:
+		// X.509 signatures need a certificate store we do not have
+		return types.UnknownKey, "x509"
+	}
+}
+
+func (s *Service) verifyPGP(signature string, payload []byte) (types.SignatureStatus, string) {
+	block, err := armor.Decode(strings.NewReader(signature))
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return types.BadSignature, ""
+	}
+	keyID := ""
+	if sig.IssuerKeyId != nil {
+		keyID = fmt.Sprintf("%016X", *sig.IssuerKeyId)
+	}
+
+	// Judge expiry at signing time, so keys that expired since still count
+	cfg := &packet.Config{Time: func() time.Time { return sig.CreationTime }}
+	entity, err := openpgp.CheckArmoredDetachedSignature(s.signers.keyring, bytes.NewReader(payload), strings.NewReader(signature), cfg)
+	switch {
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		return types.UnknownKey, keyID
+	case err != nil:
+		return types.BadSignature, keyID
+	}
+
+	if id := entity.PrimaryIdentity(); id != nil {
+		return types.GoodSignature, id.Name
+	}
+	return types.GoodSignature, keyID
+}
+
+func (s *Service) verifySSH(signature string, payload []byte) (types.SignatureStatus, string) {
+	var encoded strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(signature), "\n") {
+		if !strings.HasPrefix(line, "-----") {
+			encoded.WriteString(strings.TrimSpace(line))
+		}
+	}
+	blob, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	body, ok := bytes.CutPrefix(blob, []byte("SSHSIG"))
+	if !ok {
+		return types.BadSignature, ""
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(body, &sig); err != nil || sig.Version != 1 || sig.Namespace != "git" {
+		return types.BadSignature, ""
+	}
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+
+	var digest []byte
+	switch sig.HashAlg {
+	case "sha512":
+		sum := sha512.Sum512(payload)
+		digest = sum[:]
+	case "sha256":
+		sum := sha256.Sum256(payload)
+		digest = sum[:]
+	default:
+		return types.BadSignature, fingerprint
+	}
+
+	signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlg, digest})...)
+
+	var wire ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &wire); err != nil {
+		return types.BadSignature, fingerprint
+	}
+	if err := key.Verify(signed, &wire); err != nil {
+		return types.BadSignature, fingerprint
+	}
+
+	for _, allowed := range s.signers.ssh {
+		if bytes.Equal(allowed.key.Marshal(), key.Marshal()) {
+			return types.GoodSignature, allowed.principals
+		}
+	}
+	return types.UnknownKey, fingerprint
+}
+
+// VerifyCommit checks the signature of the commit hash. Listings only mark
+// signed commits Unverified, as checking every one is slow.
+func (s *Service) VerifyCommit(hash string) (types.SignatureStatus, string, error) {
+	c, err := s.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return types.Unsigned, "", opError("read commit", err)
+	}
+	status, signer := s.commitSignature(c)
+	return status, signer, nil
+}
+
+// VerifyTag checks the signature of the tag called name, which may be the
+// short name or the full ref. Lightweight tags are unsigned.
+func (s *Service) VerifyTag(name string) (types.SignatureStatus, string, error) {
+	ref, err := s.repo.Reference(plumbing.NewTagReferenceName(strings.TrimPrefix(name, "refs/tags/")), false)
+	if err != nil {
+		return types.Unsigned, "", opError("read tag", err)
+	}
+	t, err := s.repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return types.Unsigned, "", nil
+	}
+	if err != nil {
+		return types.Unsigned, "", opError("read tag", err)
+	}
+	status, signer := s.tagSignature(t)
+	return status, signer, nil
+}
+
+// commitSignature verifies the signature of a commit.
+func (s *Service) commitSignature(c *object.Commit) (types.SignatureStatus, string) {
+	if c.PGPSignature == "" {
+		return types.Unsigned, ""
+	}
+	payload, err := signedPayload(c.EncodeWithoutSignature)
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	return s.verifySignature(c.PGPSignature, payload)
+}
+
+// tagSignature verifies an annotated tag.
+func (s *Service) tagSignature(t *object.Tag) (types.SignatureStatus, string) {
+	if t.PGPSignature == "" {
+		return types.Unsigned, ""
+	}
+	payload, err := signedPayload(t.EncodeWithoutSignature)
+	if err != nil {
+		return types.BadSignature, ""
+	}
+	return s.verifySignature(t.PGPSignature, payload)
+}
+
+// signedPayload returns the bytes a signature covers: the object encoded
+// without its signature.
+func signedPayload(encode func(plumbing.EncodedObject) error) ([]byte, error) {
+	obj := &plumbing.MemoryObject{}
+	if err := encode(obj); err != nil {
+		return nil, err
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
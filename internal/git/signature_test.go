@@ -0,0 +1,435 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// signFunc signs the payload of a commit or tag and returns the armored
+// signature git stores in the object.
+type signFunc func(t *testing.T, payload []byte) string
+
+func TestVerifySignature(t *testing.T) {
+	alice := newPGPEntity(t, "Alice", "alice@example.com")
+	mallory := newPGPEntity(t, "Mallory", "mallory@example.com")
+	aliceSSH, aliceKey := newSSHSigner(t)
+	strangerSSH, _ := newSSHSigner(t)
+	fileOnlySSH, _ := newSSHSigner(t)
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{repo: repo}
+	s.signers = signers{
+		keyring: openpgp.EntityList{alice},
+		ssh: parseAllowedSigners([]byte(
+			"alice@example.com " + authorizedKey(aliceSSH) + "\n" +
+				`files@example.com namespaces="file" ` + authorizedKey(fileOnlySSH) + "\n",
+		)),
+	}
+
+	tests := []struct {
+		name       string
+		sign       signFunc
+		tamper     bool // change the message after signing
+		want       types.SignatureStatus
+		wantSigner string
+	}{
+		{
+			name:       "PGP good",
+			sign:       pgpSign(alice),
+			want:       types.GoodSignature,
+			wantSigner: alice.PrimaryIdentity().Name,
+		},
+		{
+			name:       "PGP tampered payload",
+			sign:       pgpSign(alice),
+			tamper:     true,
+			want:       types.BadSignature,
+			wantSigner: keyID(alice),
+		},
+		{
+			name:       "PGP unknown key",
+			sign:       pgpSign(mallory),
+			want:       types.UnknownKey,
+			wantSigner: keyID(mallory),
+		},
+		{
+			name: "PGP garbage",
+			sign: func(*testing.T, []byte) string {
+				return "-----BEGIN PGP SIGNATURE-----\n\nbm90IGEgc2lnbmF0dXJl\n-----END PGP SIGNATURE-----\n"
+			},
+			want: types.BadSignature,
+		},
+		{
+			name:       "SSH good",
+			sign:       sshSign(aliceSSH, 1, "git", "sha512"),
+			want:       types.GoodSignature,
+			wantSigner: "alice@example.com",
+		},
+		{
+			name:       "SSH good with sha256",
+			sign:       sshSign(aliceSSH, 1, "git", "sha256"),
+			want:       types.GoodSignature,
+			wantSigner: "alice@example.com",
+		},
+		{
+			name:       "SSH tampered payload",
+			sign:       sshSign(aliceSSH, 1, "git", "sha512"),
+			tamper:     true,
+			want:       types.BadSignature,
+			wantSigner: ssh.FingerprintSHA256(aliceSSH.PublicKey()),
+		},
+		{
+			name:       "SSH unknown key",
+			sign:       sshSign(strangerSSH, 1, "git", "sha512"),
+			want:       types.UnknownKey,
+			wantSigner: ssh.FingerprintSHA256(strangerSSH.PublicKey()),
+		},
+		{
+			name:       "SSH key not allowed for git",
+			sign:       sshSign(fileOnlySSH, 1, "git", "sha512"),
+			want:       types.UnknownKey,
+			wantSigner: ssh.FingerprintSHA256(fileOnlySSH.PublicKey()),
+		},
+		{
+			name: "SSH wrong namespace",
+			sign: sshSign(aliceSSH, 1, "file", "sha512"),
+			want: types.BadSignature,
+		},
+		{
+			name:       "SSH unsupported hash algorithm",
+			sign:       sshSign(aliceSSH, 1, "git", "md5"),
+			want:       types.BadSignature,
+			wantSigner: ssh.FingerprintSHA256(aliceSSH.PublicKey()),
+		},
+		{
+			name: "SSH unknown version",
+			sign: sshSign(aliceSSH, 2, "git", "sha512"),
+			want: types.BadSignature,
+		},
+		{
+			name:       "SSH good from ssh-keygen",
+			sign:       sshKeygenSign(aliceKey, "git"),
+			want:       types.GoodSignature,
+			wantSigner: "alice@example.com",
+		},
+		{
+			name: "SSH wrong namespace from ssh-keygen",
+			sign: sshKeygenSign(aliceKey, "file"),
+			want: types.BadSignature,
+		},
+		{
+			name: "SSH not base64",
+			sign: func(*testing.T, []byte) string {
+				return "-----BEGIN SSH SIGNATURE-----\n!!!\n-----END SSH SIGNATURE-----\n"
+			},
+			want: types.BadSignature,
+		},
+		{
+			name: "SSH missing magic",
+			sign: func(*testing.T, []byte) string {
+				return armorSSH(base64.StdEncoding.EncodeToString([]byte("NOTSIG-and-more")))
+			},
+			want: types.BadSignature,
+		},
+		{
+			name: "X.509",
+			sign: func(*testing.T, []byte) string {
+				return "-----BEGIN SIGNED MESSAGE-----\nMIIB\n-----END SIGNED MESSAGE-----\n"
+			},
+			want:       types.UnknownKey,
+			wantSigner: "x509",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("commit", func(t *testing.T) {
+				hash := storeSignedCommit(t, repo, tt.sign, tt.tamper)
+				status, signer, err := s.VerifyCommit(hash.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if status != tt.want || signer != tt.wantSigner {
+					t.Errorf("VerifyCommit() = %v, %q; want %v, %q", status, signer, tt.want, tt.wantSigner)
+				}
+			})
+			t.Run("tag", func(t *testing.T) {
+				name := strings.ReplaceAll(tt.name, " ", "-")
+				storeSignedTag(t, repo, name, tt.sign, tt.tamper)
+				status, signer, err := s.VerifyTag(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if status != tt.want || signer != tt.wantSigner {
+					t.Errorf("VerifyTag() = %v, %q; want %v, %q", status, signer, tt.want, tt.wantSigner)
+				}
+			})
+		})
+	}
+}
+
+// Listing commits and tags marks signed ones without checking them.
+func TestSignaturesVerifiedLazily(t *testing.T) {
+	alice := newPGPEntity(t, "Alice", "alice@example.com")
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{repo: repo, signers: signers{keyring: openpgp.EntityList{alice}}}
+
+	signed := storeSignedCommit(t, repo, pgpSign(alice), false)
+	unsigned := storeSignedCommit(t, repo, nil, false)
+	storeSignedTag(t, repo, "signed", pgpSign(alice), false)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("light"), signed)); err != nil {
+		t.Fatal(err)
+	}
+
+	for hash, want := range map[plumbing.Hash]types.SignatureStatus{signed: types.Unverified, unsigned: types.Unsigned} {
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.graphCommit(c).Signature; got != want {
+			t.Errorf("graphCommit(%s).Signature = %v, want %v", hash.String()[:7], got, want)
+		}
+	}
+
+	tags, err := s.GetTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]types.SignatureStatus)
+	for _, tag := range tags {
+		got[tag.Name] = tag.Signature
+	}
+	if got["signed"] != types.Unverified || got["light"] != types.Unsigned {
+		t.Errorf("GetTags() signatures = %v, want signed unverified and light unsigned", got)
+	}
+
+	if status, _, err := s.VerifyTag("light"); err != nil || status != types.Unsigned {
+		t.Errorf("VerifyTag(light) = %v, %v; want unsigned", status, err)
+	}
+	if _, _, err := s.VerifyTag("missing"); err == nil {
+		t.Error("VerifyTag(missing) should fail")
+	}
+}
+
+func newPGPEntity(t *testing.T, name, email string) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "", email, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func keyID(e *openpgp.Entity) string {
+	return fmt.Sprintf("%016X", e.PrimaryKey.KeyId)
+}
+
+func pgpSign(e *openpgp.Entity) signFunc {
+	return func(t *testing.T, payload []byte) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := openpgp.ArmoredDetachSign(&buf, e, bytes.NewReader(payload), nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+}
+
+func newSSHSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, key
+}
+
+func authorizedKey(signer ssh.Signer) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
+
+// sshSign builds an SSHSIG signature as described in OpenSSH's
+// PROTOCOL.sshsig, with the given version, namespace and hash algorithm.
+// An algorithm other than sha256 is hashed with sha512.
+func sshSign(signer ssh.Signer, version uint32, namespace, hashAlg string) signFunc {
+	return func(t *testing.T, payload []byte) string {
+		t.Helper()
+		var digest []byte
+		if hashAlg == "sha256" {
+			sum := sha256.Sum256(payload)
+			digest = sum[:]
+		} else {
+			sum := sha512.Sum512(payload)
+			digest = sum[:]
+		}
+		signed := append([]byte("SSHSIG"), ssh.Marshal(struct {
+			Namespace string
+			Reserved  string
+			HashAlg   string
+			Hash      []byte
+		}{namespace, "", hashAlg, digest})...)
+		sig, err := signer.Sign(rand.Reader, signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob := append([]byte("SSHSIG"), ssh.Marshal(sshSignature{
+			Version:   version,
+			PublicKey: signer.PublicKey().Marshal(),
+			Namespace: namespace,
+			HashAlg:   hashAlg,
+			Signature: ssh.Marshal(sig),
+		})...)
+		return armorSSH(base64.StdEncoding.EncodeToString(blob))
+	}
+}
+
+func armorSSH(encoded string) string {
+	var b strings.Builder
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		b.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	b.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+	return b.String()
+}
+
+// sshKeygenSign signs with OpenSSH itself, as git does.
+func sshKeygenSign(key ed25519.PrivateKey, namespace string) signFunc {
+	return func(t *testing.T, payload []byte) string {
+		t.Helper()
+		if _, err := exec.LookPath("ssh-keygen"); err != nil {
+			t.Skip("ssh-keygen not installed")
+		}
+		block, err := ssh.MarshalPrivateKey(key, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "id_ed25519")
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("ssh-keygen", "-Y", "sign", "-f", path, "-n", namespace)
+		cmd.Stdin = bytes.NewReader(payload)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("ssh-keygen: %v", err)
+		}
+		return string(out)
+	}
+}
+
+// storeSignedCommit writes a commit signed by sign, or unsigned when sign
+// is nil, and returns its hash.
+func storeSignedCommit(t *testing.T, repo *git.Repository, sign signFunc, tamper bool) plumbing.Hash {
+	t.Helper()
+	who := object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Unix(1700000000, 0).UTC()}
+	c := &object.Commit{Author: who, Committer: who, Message: "Signed change\n", TreeHash: emptyTree(t, repo)}
+	if sign != nil {
+		c.PGPSignature = sign(t, encodedPayload(t, repo, c.EncodeWithoutSignature))
+	}
+	if tamper {
+		c.Message = "Tampered change\n"
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// storeSignedTag writes an annotated tag of a new commit, signed by sign.
+func storeSignedTag(t *testing.T, repo *git.Repository, name string, sign signFunc, tamper bool) {
+	t.Helper()
+	who := object.Signature{Name: "Alice", Email: "alice@example.com", When: time.Unix(1700000000, 0).UTC()}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     who,
+		Message:    "Release\n",
+		TargetType: plumbing.CommitObject,
+		Target:     storeSignedCommit(t, repo, nil, false),
+	}
+	if sign != nil {
+		tag.PGPSignature = sign(t, encodedPayload(t, repo, tag.EncodeWithoutSignature))
+	}
+	if tamper {
+		tag.Message = "Tampered release\n"
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func encodedPayload(t *testing.T, repo *git.Repository, encode func(plumbing.EncodedObject) error) []byte {
+	t.Helper()
+	obj := repo.Storer.NewEncodedObject()
+	if err := encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func emptyTree(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	obj := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
@@ -0,0 +1,528 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+const stashRef plumbing.ReferenceName = "refs/stash"
+
+var ErrNoLocalChanges = errors.New("no local changes to stash")
+
+// StashConflictError is returned when a stash could not be applied cleanly.
+// Conflicted files are left in the worktree with conflict markers and the
+// stash is kept. Untracked files saved in the stash are not restored, so
+// resetting the conflicted files leaves the worktree as it was.
+type StashConflictError struct {
+	Stash     string
+	Files     []string
+	Untracked int // untracked files left in the stash
+}
+
+func (e *StashConflictError) Error() string {
+	msg := fmt.Sprintf("%s applied with conflicts in %d files", e.Stash, len(e.Files))
+	if e.Untracked > 0 {
+		msg += fmt.Sprintf(", its %d untracked files not restored", e.Untracked)
+	}
+	return msg
+}
+
+// GetStashes lists the stash, newest first, the way git stash list does.
+func (s *Service) GetStashes() ([]types.Stash, error) {
+	entries, err := s.stashEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []types.Stash
+	for i, entry := range entries {
+		stashes = append(stashes, types.Stash{
+			Index:    i,
+			Name:     fmt.Sprintf("stash@{%d}", i),
+			Hash:     entry.New.String()[:7],
+			FullHash: entry.New.String(),
+			Message:  entry.Message,
+			Date:     entry.When,
+		})
+	}
+	return stashes, nil
+}
+
+// stashEntries reads the stash reflog. A refs/stash written without a
+// reflog still counts as a single entry.
+func (s *Service) stashEntries() ([]reflogEntry, error) {
+	entries, err := s.readReflog(stashRef)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	ref, err := s.repo.Reference(stashRef, true)
+	if err != nil {
+		return nil, nil
+	}
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return []reflogEntry{{
+		New:       ref.Hash(),
+		Committer: commit.Committer.Name,
+		Email:     commit.Committer.Email,
+		When:      commit.Committer.When,
+		Message:   firstLine(commit.Message),
+	}}, nil
+}
+
+func (s *Service) stashEntry(index int) (reflogEntry, []reflogEntry, error) {
+	entries, err := s.stashEntries()
+	if err != nil {
+		return reflogEntry{}, nil, err
+	}
+	if index < 0 || index >= len(entries) {
+		return reflogEntry{}, nil, fmt.Errorf("stash@{%d} does not exist", index)
+	}
+	return entries[index], entries, nil
+}
+
+// CreateStash records the staged and unstaged changes to tracked files as a
+// new stash and resets the worktree to HEAD. Untracked files are stashed and
+// removed too when untracked is set, as git stash -u does; otherwise they are
+// left alone.
+func (s *Service) CreateStash(message string, untracked bool) error {
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+	var untrackedPaths []string
+	if untracked {
+		for path, fs := range status {
+			if fs.Worktree == git.Untracked {
+				untrackedPaths = append(untrackedPaths, path)
+			}
+		}
+	}
+	if checkClean(wt) == nil && len(untrackedPaths) == 0 {
+		return ErrNoLocalChanges
+	}
+
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	indexFiles := make(map[string]treeEntry)
+	for _, e := range idx.Entries {
+		if e.Stage != 0 {
+			return errors.New("resolve merge conflicts before stashing")
+		}
+		indexFiles[e.Name] = treeEntry{mode: e.Mode, hash: e.Hash}
+	}
+
+	worktreeFiles := make(map[string]treeEntry, len(indexFiles))
+	for path, e := range indexFiles {
+		worktreeFiles[path] = e
+	}
+	for path, fs := range status {
+		switch fs.Worktree {
+		case git.Deleted:
+			delete(worktreeFiles, path)
+		case git.Modified:
+			entry, err := s.worktreeBlob(wt, path)
+			if err != nil {
+				return err
+			}
+			worktreeFiles[path] = entry
+		}
+	}
+
+	indexTree, err := s.writeTree(indexFiles)
+	if err != nil {
+		return err
+	}
+	worktreeTree, err := s.writeTree(worktreeFiles)
+	if err != nil {
+		return err
+	}
+
+	committer, err := s.configSignature()
+	if err != nil {
+		return err
+	}
+
+	branch := "(no branch)"
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	subject := head.Hash().String()[:7] + " " + firstLine(headCommit.Message)
+	if message == "" {
+		message = "WIP on " + branch + ": " + subject
+	} else {
+		message = "On " + branch + ": " + message
+	}
+
+	indexCommit, err := s.writeCommit(&object.Commit{
+		Author:       committer,
+		Committer:    committer,
+		Message:      "index on " + branch + ": " + subject + "\n",
+		TreeHash:     indexTree,
+		ParentHashes: []plumbing.Hash{head.Hash()},
+	})
+	if err != nil {
+		return err
+	}
+	parents := []plumbing.Hash{head.Hash(), indexCommit}
+
+	// Untracked files go in a third, parentless commit
+	if len(untrackedPaths) > 0 {
+		untrackedFiles := make(map[string]treeEntry, len(untrackedPaths))
+		for _, path := range untrackedPaths {
+			if untrackedFiles[path], err = s.worktreeBlob(wt, path); err != nil {
+				return err
+			}
+		}
+		untrackedTree, err := s.writeTree(untrackedFiles)
+		if err != nil {
+			return err
+		}
+		untrackedCommit, err := s.writeCommit(&object.Commit{
+			Author:    committer,
+			Committer: committer,
+			Message:   "untracked files on " + branch + ": " + subject + "\n",
+			TreeHash:  untrackedTree,
+		})
+		if err != nil {
+			return err
+		}
+		parents = append(parents, untrackedCommit)
+	}
+
+	stashCommit, err := s.writeCommit(&object.Commit{
+		Author:       committer,
+		Committer:    committer,
+		Message:      message + "\n",
+		TreeHash:     worktreeTree,
+		ParentHashes: parents,
+	})
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.stashEntries()
+	if err != nil {
+		return err
+	}
+	old := plumbing.ZeroHash
+	if len(entries) > 0 {
+		old = entries[0].New
+	}
+	entries = append([]reflogEntry{{
+		Old:       old,
+		New:       stashCommit,
+		Committer: committer.Name,
+		Email:     committer.Email,
+		When:      committer.When,
+		Message:   message,
+	}}, entries...)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(stashRef, stashCommit)); err != nil {
+		return err
+	}
+	if err := s.writeReflog(stashRef, entries); err != nil {
+		return err
+	}
+
+	if err := s.resetHard(wt, head.Hash()); err != nil {
+		return err
+	}
+	for _, path := range untrackedPaths {
+		if err := removeWorktreeFile(wt, path); err != nil {
+			return err
+		}
+		removeEmptyDirs(wt, path)
+	}
+	return nil
+}
+
+// removeEmptyDirs removes the directories above path that are left empty.
+func removeEmptyDirs(wt *git.Worktree, path string) {
+	for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+		if os.Remove(filepath.Join(wt.Filesystem.Root(), dir)) != nil {
+			return
+		}
+	}
+}
+
+// ApplyStash applies the worktree changes of stash@{index} on top of HEAD,
+// then restores any untracked files it saved if that applied cleanly. The
+// stash is kept.
+func (s *Service) ApplyStash(index int) error {
+	entry, _, err := s.stashEntry(index)
+	if err != nil {
+		return err
+	}
+	stash, err := s.repo.CommitObject(entry.New)
+	if err != nil {
+		return err
+	}
+	if len(stash.ParentHashes) < 2 {
+		return fmt.Errorf("stash@{%d} is not a stash commit", index)
+	}
+
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := checkClean(wt); err != nil {
+		return err
+	}
+
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	base, err := stash.Parent(0)
+	if err != nil {
+		return err
+	}
+
+	baseTree, err := base.Tree()
+	if err != nil {
+		return err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return err
+	}
+	stashTree, err := stash.Tree()
+	if err != nil {
+		return err
+	}
+
+	// Check the untracked files can be restored before touching anything
+	name := fmt.Sprintf("stash@{%d}", index)
+	var untracked []*object.File
+	if len(stash.ParentHashes) > 2 {
+		if untracked, err = s.untrackedFiles(wt, stash.ParentHashes[2], name); err != nil {
+			return err
+		}
+	}
+
+	conflicts, err := mergeTrees(wt, baseTree, headTree, stashTree, "Stashed changes", false)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &StashConflictError{Stash: name, Files: conflicts, Untracked: len(untracked)}
+	}
+
+	for _, f := range untracked {
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		if err := writeWorktreeFile(wt, f.Name, content, f.Mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PopStash applies stash@{index} and drops it if it applied cleanly.
+func (s *Service) PopStash(index int) error {
+	if err := s.ApplyStash(index); err != nil {
+		return err
+	}
+	return s.DropStash(index)
+}
+
+// DropStash removes stash@{index}. Later entries move up by one.
+func (s *Service) DropStash(index int) error {
+	_, entries, err := s.stashEntry(index)
+	if err != nil {
+		return err
+	}
+	entries = append(entries[:index], entries[index+1:]...)
+
+	if len(entries) == 0 {
+		if err := s.repo.Storer.RemoveReference(stashRef); err != nil {
+			return err
+		}
+		return s.writeReflog(stashRef, nil)
+	}
+
+	// Keep the chain of old/new hashes consistent across the gap
+	for i := range entries {
+		entries[i].Old = plumbing.ZeroHash
+		if i+1 < len(entries) {
+			entries[i].Old = entries[i+1].New
+		}
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(stashRef, entries[0].New)); err != nil {
+		return err
+	}
+	return s.writeReflog(stashRef, entries)
+}
+
+// untrackedFiles returns the files in the untracked commit hash of a stash,
+// failing if any of them is already in the worktree.
+func (s *Service) untrackedFiles(wt *git.Worktree, hash plumbing.Hash, name string) ([]*object.File, error) {
+	commit, err := s.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := commit.Files()
+	if err != nil {
+		return nil, err
+	}
+	var files []*object.File
+	err = iter.ForEach(func(f *object.File) error {
+		if _, err := wt.Filesystem.Lstat(f.Name); err == nil {
+			return fmt.Errorf("%s already exists, not restoring untracked files from %s", f.Name, name)
+		}
+		files = append(files, f)
+		return nil
+	})
+	return files, err
+}
+
+type treeEntry struct {
+	mode filemode.FileMode
+	hash plumbing.Hash
+}
+
+// worktreeBlob stores the current worktree content of path as a blob, with
+// the mode the file has on disk so a chmod is kept.
+func (s *Service) worktreeBlob(wt *git.Worktree, path string) (treeEntry, error) {
+	fi, err := wt.Filesystem.Lstat(path)
+	if err != nil {
+		return treeEntry{}, err
+	}
+	mode, err := filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return treeEntry{}, err
+	}
+
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := wt.Filesystem.Readlink(path)
+		if err != nil {
+			return treeEntry{}, err
+		}
+		content = []byte(target)
+	} else {
+		f, err := wt.Filesystem.Open(path)
+		if err != nil {
+			return treeEntry{}, err
+		}
+		content, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return treeEntry{}, err
+		}
+	}
+
+	obj := s.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return treeEntry{}, err
+	}
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		return treeEntry{}, err
+	}
+	if err := w.Close(); err != nil {
+		return treeEntry{}, err
+	}
+	hash, err := s.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return treeEntry{}, err
+	}
+	return treeEntry{mode: mode, hash: hash}, nil
+}
+
+// writeTree stores a tree, and its subtrees, for a flat map of file paths.
+func (s *Service) writeTree(files map[string]treeEntry) (plumbing.Hash, error) {
+	entries := make(map[string]treeEntry)
+	subdirs := make(map[string]map[string]treeEntry)
+	for path, e := range files {
+		dir, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			entries[path] = e
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]treeEntry)
+		}
+		subdirs[dir][rest] = e
+	}
+	for dir, sub := range subdirs {
+		hash, err := s.writeTree(sub)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries[dir] = treeEntry{mode: filemode.Dir, hash: hash}
+	}
+
+	tree := &object.Tree{}
+	for name, e := range entries {
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: e.mode, Hash: e.hash})
+	}
+	// git orders directories as if their name ended in a slash
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortKey(tree.Entries[i]) < sortKey(tree.Entries[j])
+	})
+
+	obj := s.repo.Storer.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.repo.Storer.SetEncodedObject(obj)
+}
+
+func (s *Service) writeCommit(commit *object.Commit) (plumbing.Hash, error) {
+	if commit.Committer.When.IsZero() {
+		commit.Committer.When = time.Now()
+	}
+	obj := s.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.repo.Storer.SetEncodedObject(obj)
+}
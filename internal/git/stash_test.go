@@ -0,0 +1,485 @@
+package git
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// stashRepo makes a repository with one commit of files and a committer
+// configured, and returns its directory and a Service for it.
+func stashRepo(t *testing.T, files map[string]string) (string, *Service) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, wt, files, nil)
+	return dir, openService(t, dir)
+}
+
+func writeFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func stage(t *testing.T, s *Service, path string) {
+	t.Helper()
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// treeFiles returns the files of a commit's tree by path, with their modes.
+func treeFiles(t *testing.T, s *Service, hash plumbing.Hash) (map[string]string, map[string]filemode.FileMode) {
+	t.Helper()
+	commit, err := s.repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter, err := commit.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	modes := make(map[string]filemode.FileMode)
+	err = iter.ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		files[f.Name], modes[f.Name] = content, f.Mode
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files, modes
+}
+
+func TestCreateStash(t *testing.T) {
+	head := map[string]string{"a": "a\n", "b": "b\n", "run": "echo\n"}
+
+	tests := []struct {
+		name          string
+		change        func(t *testing.T, dir string, s *Service)
+		untracked     bool
+		wantWorktree  map[string]string // the stash commit's tree
+		wantIndex     map[string]string // the index commit's tree
+		wantUntracked map[string]string // the untracked commit's tree; nil for none
+		wantExec      []string
+		wantErr       error
+	}{
+		{
+			name: "unstaged change",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "a", "changed\n")
+			},
+			wantWorktree: map[string]string{"a": "changed\n", "b": "b\n", "run": "echo\n"},
+			wantIndex:    head,
+		},
+		{
+			name: "staged then changed again",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "a", "staged\n")
+				stage(t, s, "a")
+				writeFile(t, dir, "a", "unstaged\n")
+			},
+			wantWorktree: map[string]string{"a": "unstaged\n", "b": "b\n", "run": "echo\n"},
+			wantIndex:    map[string]string{"a": "staged\n", "b": "b\n", "run": "echo\n"},
+		},
+		{
+			name: "deleted file",
+			change: func(t *testing.T, dir string, s *Service) {
+				if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantWorktree: map[string]string{"a": "a\n", "run": "echo\n"},
+			wantIndex:    head,
+		},
+		{
+			name: "chmod only",
+			change: func(t *testing.T, dir string, s *Service) {
+				if err := os.Chmod(filepath.Join(dir, "run"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantWorktree: head,
+			wantIndex:    head,
+			wantExec:     []string{"run"},
+		},
+		{
+			name: "untracked files left alone",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "a", "changed\n")
+				writeFile(t, dir, "new", "new\n")
+			},
+			wantWorktree: map[string]string{"a": "changed\n", "b": "b\n", "run": "echo\n"},
+			wantIndex:    head,
+		},
+		{
+			name: "untracked files stashed",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "a", "changed\n")
+				writeFile(t, dir, "new", "new\n")
+				if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, dir, "sub/deep", "deep\n")
+			},
+			untracked:     true,
+			wantWorktree:  map[string]string{"a": "changed\n", "b": "b\n", "run": "echo\n"},
+			wantIndex:     head,
+			wantUntracked: map[string]string{"new": "new\n", "sub/deep": "deep\n"},
+		},
+		{
+			name: "only untracked files",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "new", "new\n")
+			},
+			untracked:     true,
+			wantWorktree:  head,
+			wantIndex:     head,
+			wantUntracked: map[string]string{"new": "new\n"},
+		},
+		{
+			name: "untracked files without -u",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "new", "new\n")
+			},
+			wantErr: ErrNoLocalChanges,
+		},
+		{
+			name:    "no changes",
+			change:  func(t *testing.T, dir string, s *Service) {},
+			wantErr: ErrNoLocalChanges,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, s := stashRepo(t, head)
+			tt.change(t, dir, s)
+			changed := readFiles(t, dir)
+
+			err := s.CreateStash("", tt.untracked)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateStash() = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stashes, err := s.GetStashes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stashes) != 1 || !strings.HasPrefix(stashes[0].Message, "WIP on master: ") {
+				t.Fatalf("GetStashes() = %+v, want one WIP stash", stashes)
+			}
+			stash, err := s.repo.CommitObject(plumbing.NewHash(stashes[0].FullHash))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, modes := treeFiles(t, s, stash.Hash)
+			if !maps.Equal(files, tt.wantWorktree) {
+				t.Errorf("stash tree = %q, want %q", files, tt.wantWorktree)
+			}
+			for path, mode := range modes {
+				if want := slices.Contains(tt.wantExec, path); (mode == filemode.Executable) != want {
+					t.Errorf("%s mode = %s, want executable %v", path, mode, want)
+				}
+			}
+			if index, _ := treeFiles(t, s, stash.ParentHashes[1]); !maps.Equal(index, tt.wantIndex) {
+				t.Errorf("index tree = %q, want %q", index, tt.wantIndex)
+			}
+			if tt.wantUntracked == nil {
+				if len(stash.ParentHashes) != 2 {
+					t.Errorf("stash has %d parents, want 2", len(stash.ParentHashes))
+				}
+			} else if len(stash.ParentHashes) != 3 {
+				t.Errorf("stash has %d parents, want 3", len(stash.ParentHashes))
+			} else if untracked, _ := treeFiles(t, s, stash.ParentHashes[2]); !maps.Equal(untracked, tt.wantUntracked) {
+				t.Errorf("untracked tree = %q, want %q", untracked, tt.wantUntracked)
+			}
+
+			// The worktree is back at HEAD, keeping untracked files unless stashed
+			want := maps.Clone(head)
+			if !tt.untracked {
+				for path, content := range changed {
+					if _, tracked := head[path]; !tracked {
+						want[path] = content
+					}
+				}
+			}
+			if got := readFiles(t, dir); !maps.Equal(got, want) {
+				t.Errorf("worktree = %q, want %q", got, want)
+			}
+			if tt.untracked {
+				if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+					t.Errorf("sub should have been removed: %v", err)
+				}
+			}
+
+			// Applying brings the changes back
+			if err := s.ApplyStash(0); err != nil {
+				t.Fatal(err)
+			}
+			if got := readFiles(t, dir); !maps.Equal(got, changed) {
+				t.Errorf("worktree after apply = %q, want %q", got, changed)
+			}
+			for _, path := range tt.wantExec {
+				fi, err := os.Stat(filepath.Join(dir, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fi.Mode()&0o100 == 0 {
+					t.Errorf("%s is not executable after apply", path)
+				}
+			}
+			if tt.wantUntracked["sub/deep"] != "" {
+				if data, err := os.ReadFile(filepath.Join(dir, "sub", "deep")); err != nil || string(data) != "deep\n" {
+					t.Errorf("sub/deep = %q, %v after apply", data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyStashConflict(t *testing.T) {
+	dir, s := stashRepo(t, map[string]string{"f": "base\n", "g": "g\n"})
+	writeFile(t, dir, "f", "stashed\n")
+	writeFile(t, dir, "g", "g stashed\n")
+	writeFile(t, dir, "new", "untracked\n")
+	if err := s.CreateStash("mine", true); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := s.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, s.repo, wt, map[string]string{"f": "committed\n", "g": "g\n"}, nil)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		err := s.ApplyStash(0)
+		var conflict *StashConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("attempt %d: ApplyStash() = %v, want a StashConflictError", attempt, err)
+		}
+		if conflict.Stash != "stash@{0}" || !slices.Equal(conflict.Files, []string{"f"}) || conflict.Untracked != 1 {
+			t.Errorf("attempt %d: conflict = %+v", attempt, conflict)
+		}
+		files := readFiles(t, dir)
+		if !strings.Contains(files["f"], "<<<<<<< HEAD") || files["g"] != "g stashed\n" {
+			t.Errorf("attempt %d: worktree = %q, want f conflicted and g applied", attempt, files)
+		}
+		if _, ok := files["new"]; ok {
+			t.Errorf("attempt %d: untracked file restored despite the conflict", attempt)
+		}
+
+		// Throwing the conflict away leaves nothing behind to block a retry
+		head, err := s.repo.Head()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.resetHard(wt, head.Hash()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stashes, err := s.GetStashes(); err != nil || len(stashes) != 1 {
+		t.Errorf("GetStashes() = %d, %v; want the stash kept", len(stashes), err)
+	}
+}
+
+func TestApplyStashUntrackedExists(t *testing.T) {
+	dir, s := stashRepo(t, map[string]string{"f": "base\n"})
+	writeFile(t, dir, "f", "stashed\n")
+	writeFile(t, dir, "new", "untracked\n")
+	if err := s.CreateStash("", true); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "new", "in the way\n")
+
+	if err := s.ApplyStash(0); err == nil || !strings.Contains(err.Error(), "new already exists") {
+		t.Fatalf("ApplyStash() = %v, want new already exists", err)
+	}
+	want := map[string]string{"f": "base\n", "new": "in the way\n"}
+	if got := readFiles(t, dir); !maps.Equal(got, want) {
+		t.Errorf("worktree = %q, want it untouched %q", got, want)
+	}
+}
+
+func TestApplyStashDirtyWorktree(t *testing.T) {
+	dir, s := stashRepo(t, map[string]string{"f": "base\n"})
+	writeFile(t, dir, "f", "stashed\n")
+	if err := s.CreateStash("", false); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "f", "local\n")
+
+	if err := s.ApplyStash(0); !errors.Is(err, ErrDirtyWorktree) {
+		t.Fatalf("ApplyStash() = %v, want ErrDirtyWorktree", err)
+	}
+	if err := s.ApplyStash(1); err == nil {
+		t.Error("ApplyStash(1) should fail with only one stash")
+	}
+}
+
+// makeStashes stashes a change to f for each message, oldest first.
+func makeStashes(t *testing.T, dir string, s *Service, messages ...string) {
+	t.Helper()
+	for _, message := range messages {
+		writeFile(t, dir, "f", message+"\n")
+		if err := s.CreateStash(message, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func stashMessages(t *testing.T, s *Service) []string {
+	t.Helper()
+	stashes, err := s.GetStashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, stash := range stashes {
+		messages = append(messages, strings.TrimPrefix(stash.Message, "On master: "))
+	}
+	return messages
+}
+
+func TestPopStash(t *testing.T) {
+	dir, s := stashRepo(t, map[string]string{"f": "base\n"})
+	makeStashes(t, dir, s, "one", "two", "three")
+
+	if err := s.PopStash(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFiles(t, dir)["f"]; got != "two\n" {
+		t.Errorf("f = %q, want the popped stash", got)
+	}
+	if got := stashMessages(t, s); !slices.Equal(got, []string{"three", "one"}) {
+		t.Errorf("stashes = %v, want [three one]", got)
+	}
+
+	// A conflicting pop keeps the stash
+	commitIn(t, dir, map[string]string{"f": "committed\n"})
+	var conflict *StashConflictError
+	if err := s.PopStash(0); !errors.As(err, &conflict) {
+		t.Fatalf("PopStash() = %v, want a StashConflictError", err)
+	}
+	if got := stashMessages(t, s); !slices.Equal(got, []string{"three", "one"}) {
+		t.Errorf("stashes = %v, want both kept", got)
+	}
+}
+
+func TestDropStash(t *testing.T) {
+	tests := []struct {
+		name  string
+		drop  []int
+		want  []string // remaining messages, newest first
+		error bool
+	}{
+		{name: "newest", drop: []int{0}, want: []string{"two", "one"}},
+		{name: "middle", drop: []int{1}, want: []string{"three", "one"}},
+		{name: "oldest", drop: []int{2}, want: []string{"three", "two"}},
+		{name: "all", drop: []int{0, 0, 0}},
+		{name: "out of range", drop: []int{3}, want: []string{"three", "two", "one"}, error: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, s := stashRepo(t, map[string]string{"f": "base\n"})
+			makeStashes(t, dir, s, "one", "two", "three")
+
+			for _, index := range tt.drop {
+				if err := s.DropStash(index); (err != nil) != tt.error {
+					t.Fatalf("DropStash(%d) = %v, want error %v", index, err, tt.error)
+				}
+			}
+			if got := stashMessages(t, s); !slices.Equal(got, tt.want) {
+				t.Errorf("stashes = %v, want %v", got, tt.want)
+			}
+
+			entries, err := s.readReflog(stashRef)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, refErr := s.repo.Reference(stashRef, true)
+			if len(tt.want) == 0 {
+				if refErr == nil || len(entries) != 0 {
+					t.Errorf("refs/stash = %v with %d reflog entries, want both gone", ref, len(entries))
+				}
+				return
+			}
+			if refErr != nil {
+				t.Fatal(refErr)
+			}
+			if ref.Hash() != entries[0].New {
+				t.Errorf("refs/stash = %s, want the newest entry %s", ref.Hash(), entries[0].New)
+			}
+			for i, e := range entries {
+				want := plumbing.ZeroHash
+				if i+1 < len(entries) {
+					want = entries[i+1].New
+				}
+				if e.Old != want {
+					t.Errorf("entry %d old = %s, want %s", i, e.Old, want)
+				}
+			}
+
+			// git itself reads the rewritten reflog the same way
+			if _, err := exec.LookPath("git"); err != nil {
+				return
+			}
+			out, err := exec.Command("git", "-C", dir, "stash", "list", "--format=%gs").Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+				got = append(got, strings.TrimPrefix(line, "On master: "))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("git stash list = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
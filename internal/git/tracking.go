@@ -0,0 +1,134 @@
+package git
+
+import (
+	"container/heap"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// GetBranchTracking returns the local branches with their upstream and
+// ahead/behind counts filled in. It walks history for every tracked branch,
+// so callers should run it in the background.
+func (s *Service) GetBranchTracking() ([]types.Branch, error) {
+	branches, err := s.GetBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	var local []types.Branch
+	for _, b := range branches {
+		if b.IsRemote {
+			continue
+		}
+		upstream, err := s.upstreamRef(b.Name)
+		if err == nil {
+			if ref, err := s.repo.Reference(upstream, true); err == nil {
+				b.Upstream = upstream.Short()
+				b.Ahead, b.Behind, err = s.aheadBehind(plumbing.NewHash(b.Hash), ref.Hash())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		local = append(local, b)
+	}
+
+	return local, nil
+}
+
+// aheadBehind counts the commits only reachable from local and only
+// reachable from upstream, as git rev-list --left-right --count does. Both
+// tips are walked together, newest first, marking each commit with the
+// sides it is reachable from. Once every queued commit is reachable from
+// both, older history is shared, unless clock skew dated a shared commit
+// before an ancestor already walked from one side. Like git, the walk goes
+// on for walkSlop more commits to catch that.
+func (s *Service) aheadBehind(local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	sides := make(map[plumbing.Hash]uint8)
+	queue := &commitQueue{}
+	mark := func(hash plumbing.Hash, side uint8) error {
+		if sides[hash]|side == sides[hash] {
+			return nil
+		}
+		c, err := s.repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		sides[hash] |= side
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := mark(local, fromLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := mark(upstream, fromUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	slop := walkSlop
+	for queue.Len() > 0 {
+		if !queue.shared(sides) {
+			slop = walkSlop
+		} else if slop--; slop < 0 {
+			break
+		}
+		c := heap.Pop(queue).(*object.Commit)
+		for _, parent := range c.ParentHashes {
+			if err := mark(parent, sides[c.Hash]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	ahead, behind := 0, 0
+	for _, side := range sides {
+		switch side {
+		case fromLocal:
+			ahead++
+		case fromUpstream:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// walkSlop is how many more commits a walk visits once all it has queued
+// is shared.
+const walkSlop = 5
+
+// Sides of a two-tip walk a commit is reachable from.
+const (
+	fromLocal uint8 = 1 << iota
+	fromUpstream
+	fromBoth = fromLocal | fromUpstream
+)
+
+// commitQueue holds the commits a walk has still to visit, newest first.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].Committer.When.After(q[j].Committer.When) }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)        { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// shared reports whether every queued commit is reachable from both sides.
+func (q commitQueue) shared(sides map[plumbing.Hash]uint8) bool {
+	for _, c := range q {
+		if sides[c.Hash] != fromBoth {
+			return false
+		}
+	}
+	return true
+}
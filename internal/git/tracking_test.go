@@ -0,0 +1,170 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// buildHistory writes commits from "name:parent,parent@seconds" specs,
+// parents first, each dated seconds after a fixed epoch, and returns their
+// hashes by name.
+func buildHistory(t *testing.T, s *Service, specs ...string) map[string]plumbing.Hash {
+	t.Helper()
+	tree := emptyTree(t, s.repo)
+	hashes := make(map[string]plumbing.Hash)
+	for _, spec := range specs {
+		spec, seconds, _ := strings.Cut(spec, "@")
+		name, parents, _ := strings.Cut(spec, ":")
+		var offset int64
+		if _, err := fmt.Sscan(seconds, &offset); err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+
+		sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000+offset, 0).UTC()}
+		commit := &object.Commit{Author: sig, Committer: sig, Message: name + "\n", TreeHash: tree}
+		if parents != "" {
+			for _, p := range strings.Split(parents, ",") {
+				commit.ParentHashes = append(commit.ParentHashes, hashes[p])
+			}
+		}
+		hash, err := s.writeCommit(commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes[name] = hash
+	}
+	return hashes
+}
+
+func TestAheadBehind(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    []string
+		local      string
+		upstream   string
+		wantAhead  int
+		wantBehind int
+	}{
+		{
+			name:     "same commit",
+			history:  []string{"a@1", "b:a@2"},
+			local:    "b",
+			upstream: "b",
+		},
+		{
+			name:      "linear, ahead",
+			history:   []string{"a@1", "b:a@2", "c:b@3"},
+			local:     "c",
+			upstream:  "a",
+			wantAhead: 2,
+		},
+		{
+			name:       "linear, behind",
+			history:    []string{"a@1", "b:a@2", "c:b@3"},
+			local:      "a",
+			upstream:   "c",
+			wantBehind: 2,
+		},
+		{
+			name:       "diverged",
+			history:    []string{"a@1", "b:a@2", "c:b@3", "d:a@4"},
+			local:      "c",
+			upstream:   "d",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			name:       "upstream merged in",
+			history:    []string{"a@1", "u1:a@2", "l1:a@3", "l2:l1,u1@4", "u2:u1@5"},
+			local:      "l2",
+			upstream:   "u2",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			name:       "criss-cross merge",
+			history:    []string{"a@1", "x1:a@2", "y1:a@3", "x2:x1,y1@4", "y2:y1,x1@5", "x3:x2@6"},
+			local:      "x3",
+			upstream:   "y2",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			name:       "unrelated histories",
+			history:    []string{"a@1", "b:a@2", "c@3"},
+			local:      "b",
+			upstream:   "c",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			name:       "parent dated after its child",
+			history:    []string{"a@1", "b:a@100", "c:b@50", "d:a@60"},
+			local:      "c",
+			upstream:   "d",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			// The shared commit B is dated before m, its own parent, so m is
+			// walked from the local side alone before B reaches it
+			name:       "shared commit dated before its parent",
+			history:    []string{"m@1000", "B:m@5", "x:m@1500", "l:x,B@2000", "u:B@3000"},
+			local:      "l",
+			upstream:   "u",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+		{
+			name:       "shared chain dated before its parent",
+			history:    []string{"m@1000", "B1:m@5", "B2:B1@4", "B3:B2@3", "x:m@1500", "l:x,B3@2000", "u:B3@3000"},
+			local:      "l",
+			upstream:   "u",
+			wantAhead:  2,
+			wantBehind: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &Service{repo: repo}
+			hashes := buildHistory(t, s, tt.history...)
+			local, upstream := hashes[tt.local], hashes[tt.upstream]
+
+			ahead, behind, err := s.aheadBehind(local, upstream)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ahead != tt.wantAhead || behind != tt.wantBehind {
+				t.Errorf("aheadBehind() = %d, %d; want %d, %d", ahead, behind, tt.wantAhead, tt.wantBehind)
+			}
+
+			if _, err := exec.LookPath("git"); err != nil {
+				return
+			}
+			out, err := exec.Command("git", "-C", dir, "rev-list", "--left-right", "--count", local.String()+"..."+upstream.String()).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gitAhead, gitBehind int
+			if _, err := fmt.Sscan(string(out), &gitAhead, &gitBehind); err != nil {
+				t.Fatal(err)
+			}
+			if ahead != gitAhead || behind != gitBehind {
+				t.Errorf("aheadBehind() = %d, %d; git rev-list says %d, %d", ahead, behind, gitAhead, gitBehind)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-billy/v6/util"
+	"github.com/go-git/go-git/v6/storage/filesystem"
+)
+
+// Fingerprint summarizes the files git rewrites when the repository moves:
+// HEAD, the index, packed-refs and every loose ref. Two calls return the
+// same string unless something committed, checked out, fetched or staged in
+// between. Repositories not stored on disk always return "".
+func (s *Service) Fingerprint() string {
+	storage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	fs := storage.Filesystem()
+
+	var b strings.Builder
+	stamp := func(path string, info os.FileInfo) {
+		fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	for _, name := range []string{"HEAD", "index", "packed-refs"} {
+		if info, err := fs.Stat(name); err == nil {
+			stamp(name, info)
+		}
+	}
+	util.Walk(fs, "refs", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			stamp(path, info)
+		}
+		return nil
+	})
+	return b.String()
+}
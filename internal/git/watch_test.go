@@ -0,0 +1,64 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, dir string, s *Service)
+	}{
+		{
+			name: "commit",
+			change: func(t *testing.T, dir string, s *Service) {
+				commitIn(t, dir, map[string]string{"f": "changed\n"})
+			},
+		},
+		{
+			name: "new branch",
+			change: func(t *testing.T, dir string, s *Service) {
+				if err := s.CreateBranch("topic", refHash(t, dir, "HEAD").String()); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "staged file",
+			change: func(t *testing.T, dir string, s *Service) {
+				writeFile(t, dir, "g", "new\n")
+				stage(t, s, "g")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, s := stashRepo(t, map[string]string{"f": "f\n"})
+			before := s.Fingerprint()
+			if before == "" {
+				t.Fatal("Fingerprint() is empty for an on-disk repository")
+			}
+			writeFile(t, dir, "f", "unstaged edit\n")
+			if got := s.Fingerprint(); got != before {
+				t.Errorf("Fingerprint() changed without a git change:\n%s\nwas\n%s", got, before)
+			}
+
+			tt.change(t, dir, s)
+			if got := s.Fingerprint(); got == before {
+				t.Errorf("Fingerprint() unchanged after %s", tt.name)
+			}
+		})
+	}
+
+	repo, err := git.Init(memory.NewStorage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Service{repo: repo}).Fingerprint(); got != "" {
+		t.Errorf("Fingerprint() of an in-memory repository = %q, want empty", got)
+	}
+}
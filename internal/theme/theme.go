@@ -0,0 +1,227 @@
+// Package theme defines the colours the TUI is drawn in: the built-in dark,
+// light and high-contrast themes, and themes users write themselves.
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	format "github.com/go-git/go-git/v6/plumbing/format/config"
+)
+
+// Theme is a palette of colours by role, and the chroma style code is
+// highlighted with to match.
+type Theme struct {
+	Name        string
+	SyntaxStyle string
+
+	Background lipgloss.Color // text on coloured badges and tabs
+	Surface    lipgloss.Color // selected rows, borders and dividers
+	Text       lipgloss.Color
+	Muted      lipgloss.Color // labels, help and secondary details
+	Accent     lipgloss.Color // titles, commit dots and active tabs
+	Secondary  lipgloss.Color // merges, the cursor and submodules
+	Added      lipgloss.Color // additions, local branches and success
+	Removed    lipgloss.Color // deletions, errors and bad signatures
+	Changed    lipgloss.Color // hashes, modifications and warnings
+	Info       lipgloss.Color // remotes, authors and directories
+	Special    lipgloss.Color // tags and modified pairs in range-diffs
+	Heat       [4]lipgloss.Color
+}
+
+// Dark is the default theme, after Dracula.
+var Dark = Theme{
+	Name:        "dark",
+	SyntaxStyle: "dracula",
+	Background:  "#282A36",
+	Surface:     "#44475A",
+	Text:        "#F8F8F2",
+	Muted:       "#6272A4",
+	Accent:      "#BD93F9",
+	Secondary:   "#FF79C6",
+	Added:       "#50FA7B",
+	Removed:     "#FF5555",
+	Changed:     "#FFB86C",
+	Info:        "#8BE9FD",
+	Special:     "#F1FA8C",
+	Heat:        [4]lipgloss.Color{"#0E4429", "#006D32", "#26A641", "#39D353"},
+}
+
+// Light is for terminals with a light background.
+var Light = Theme{
+	Name:        "light",
+	SyntaxStyle: "github",
+	Background:  "#FFFFFF",
+	Surface:     "#D0D7DE",
+	Text:        "#1F2328",
+	Muted:       "#656D76",
+	Accent:      "#8250DF",
+	Secondary:   "#BF3989",
+	Added:       "#1A7F37",
+	Removed:     "#CF222E",
+	Changed:     "#9A6700",
+	Info:        "#0969DA",
+	Special:     "#BC4C00",
+	Heat:        [4]lipgloss.Color{"#9BE9A8", "#40C463", "#30A14E", "#216E39"},
+}
+
+// HighContrast uses pure, bright colours on black.
+var HighContrast = Theme{
+	Name:        "high-contrast",
+	SyntaxStyle: "hr_high_contrast",
+	Background:  "#000000",
+	Surface:     "#585858",
+	Text:        "#FFFFFF",
+	Muted:       "#C0C0C0",
+	Accent:      "#FFD700",
+	Secondary:   "#FF5FFF",
+	Added:       "#00FF00",
+	Removed:     "#FF4040",
+	Changed:     "#FFA500",
+	Info:        "#00FFFF",
+	Special:     "#FFFF87",
+	Heat:        [4]lipgloss.Color{"#005F00", "#008700", "#00D700", "#00FF00"},
+}
+
+var builtins = []Theme{Dark, Light, HighContrast}
+
+// Lanes are the colours the lanes of the commit graph cycle through.
+func (t Theme) Lanes() []lipgloss.Color {
+	return []lipgloss.Color{t.Accent, t.Added, t.Secondary, t.Info, t.Changed, t.Special, t.Removed}
+}
+
+// Section is the config section of a theme file.
+const Section = "theme"
+
+// colors maps the keys of a theme file to the colours they set. heat is
+// handled separately as it takes four.
+var colors = map[string]func(t *Theme) *lipgloss.Color{
+	"background": func(t *Theme) *lipgloss.Color { return &t.Background },
+	"surface":    func(t *Theme) *lipgloss.Color { return &t.Surface },
+	"text":       func(t *Theme) *lipgloss.Color { return &t.Text },
+	"muted":      func(t *Theme) *lipgloss.Color { return &t.Muted },
+	"accent":     func(t *Theme) *lipgloss.Color { return &t.Accent },
+	"secondary":  func(t *Theme) *lipgloss.Color { return &t.Secondary },
+	"added":      func(t *Theme) *lipgloss.Color { return &t.Added },
+	"removed":    func(t *Theme) *lipgloss.Color { return &t.Removed },
+	"changed":    func(t *Theme) *lipgloss.Color { return &t.Changed },
+	"info":       func(t *Theme) *lipgloss.Color { return &t.Info },
+	"special":    func(t *Theme) *lipgloss.Color { return &t.Special },
+}
+
+// colorValue is a hex colour or an ANSI colour number from 0 to 255.
+var colorValue = regexp.MustCompile(`^(#[0-9A-Fa-f]{3}|#[0-9A-Fa-f]{6}|[0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])$`)
+
+// Builtin returns the built-in theme called name.
+func Builtin(name string) (Theme, bool) {
+	for _, t := range builtins {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Names lists the built-in themes.
+func Names() []string {
+	var names []string
+	for _, t := range builtins {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Load returns the built-in theme called name, or else the user theme in
+// dir/name.theme: a [theme] section that starts from a built-in "base",
+// dark by default, and overrides any of its colours and its syntaxStyle.
+func Load(name, dir string) (Theme, error) {
+	if t, ok := Builtin(name); ok {
+		return t, nil
+	}
+
+	path := filepath.Join(dir, name+".theme")
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Theme{}, fmt.Errorf("no such theme: not one of %s, and %s does not exist", strings.Join(Names(), ", "), path)
+	}
+	if err != nil {
+		return Theme{}, err
+	}
+	defer f.Close()
+	raw := format.New()
+	if err := format.NewDecoder(f).Decode(raw); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sec := raw.Section(Section)
+	t := Dark
+	if base := sec.Option("base"); base != "" {
+		var ok bool
+		if t, ok = Builtin(base); !ok {
+			return Theme{}, fmt.Errorf("%s: %s.base = %q: not one of %s", path, Section, base, strings.Join(Names(), ", "))
+		}
+	}
+	t.Name = name
+
+	for _, opt := range sec.Options {
+		if err := t.set(strings.ToLower(opt.Key), strings.TrimSpace(opt.Value)); err != nil {
+			return Theme{}, fmt.Errorf("%s: %s.%s = %q: %w", path, Section, opt.Key, opt.Value, err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Theme) set(key, value string) error {
+	switch key {
+	case "base":
+		return nil
+	case "syntaxstyle":
+		if _, ok := styles.Registry[value]; !ok {
+			return errors.New("not a chroma style, such as dracula, monokai or github")
+		}
+		t.SyntaxStyle = value
+		return nil
+	case "heat":
+		if value == "" {
+			return checkColor(value)
+		}
+		fields := strings.Fields(value)
+		if len(fields) != len(t.Heat) {
+			return fmt.Errorf("want %d colours, from fewest commits to most", len(t.Heat))
+		}
+		for i, f := range fields {
+			if err := checkColor(f); err != nil {
+				return fmt.Errorf("%q: %w", f, err)
+			}
+			t.Heat[i] = lipgloss.Color(f)
+		}
+		return nil
+	}
+
+	field, ok := colors[key]
+	if !ok {
+		return errors.New("unknown setting")
+	}
+	if err := checkColor(value); err != nil {
+		return err
+	}
+	*field(t) = lipgloss.Color(value)
+	return nil
+}
+
+func checkColor(value string) error {
+	switch {
+	case value == "":
+		// git config reads an unquoted # as the start of a comment
+		return errors.New(`missing; quote hex colours, as in "#BD93F9"`)
+	case !colorValue.MatchString(value):
+		return errors.New(`not a colour such as "#BD93F9" or 141`)
+	}
+	return nil
+}
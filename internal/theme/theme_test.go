@@ -0,0 +1,134 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		theme   string // name.theme in the themes directory; none when empty
+		load    string // name passed to Load, "mine" by default
+		want    func() Theme
+		wantErr string
+	}{
+		{
+			name: "built-in",
+			load: "light",
+			want: func() Theme { return Light },
+		},
+		{
+			name:  "built-in shadows a file of the same name",
+			load:  "dark",
+			theme: "[theme]\n\taccent = \"#000000\"\n",
+			want:  func() Theme { return Dark },
+		},
+		{
+			name:  "starts from dark",
+			theme: "[theme]\n\taccent = \"#123456\"\n",
+			want: func() Theme {
+				t := Dark
+				t.Name = "mine"
+				t.Accent = "#123456"
+				return t
+			},
+		},
+		{
+			name:  "starts from another base",
+			theme: "[theme]\n\tbase = light\n\tRemoved = 196\n\tsyntaxStyle = monokai\n",
+			want: func() Theme {
+				t := Light
+				t.Name = "mine"
+				t.Removed = "196"
+				t.SyntaxStyle = "monokai"
+				return t
+			},
+		},
+		{
+			name:  "short hex and heat",
+			theme: "[theme]\n\ttext = \"#fff\"\n\theat = \"#111 #222 #333 #444\"\n",
+			want: func() Theme {
+				t := Dark
+				t.Name = "mine"
+				t.Text = "#fff"
+				t.Heat = [4]lipgloss.Color{"#111", "#222", "#333", "#444"}
+				return t
+			},
+		},
+		{name: "no such theme", load: "missing", wantErr: "not one of dark, light, high-contrast"},
+		{name: "unknown base", theme: "[theme]\n\tbase = solarized\n", wantErr: `theme.base = "solarized": not one of`},
+		{name: "unknown key", theme: "[theme]\n\tforeground = 15\n", wantErr: "unknown setting"},
+		{name: "unquoted hex", theme: "[theme]\n\taccent = #BD93F9\n", wantErr: "quote hex colours"},
+		{name: "colour name", theme: "[theme]\n\taccent = purple\n", wantErr: "not a colour"},
+		{name: "long hex", theme: "[theme]\n\taccent = \"#BD93F9AA\"\n", wantErr: "not a colour"},
+		{name: "ANSI number out of range", theme: "[theme]\n\taccent = 256\n", wantErr: "not a colour"},
+		{name: "too few heat colours", theme: "[theme]\n\theat = \"#111 #222\"\n", wantErr: "want 4 colours"},
+		{name: "bad heat colour", theme: "[theme]\n\theat = \"#111 #222 #333 red\"\n", wantErr: `"red": not a colour`},
+		{name: "unknown syntax style", theme: "[theme]\n\tsyntaxStyle = nope\n", wantErr: "not a chroma style"},
+		{name: "malformed file", theme: "[theme\n", wantErr: "mine.theme:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := tt.load
+			if name == "" {
+				name = "mine"
+			}
+			if tt.theme != "" {
+				if err := os.WriteFile(filepath.Join(dir, name+".theme"), []byte(tt.theme), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Load(name, dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			if want := tt.want(); got != want {
+				t.Errorf("Load() = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckColor(t *testing.T) {
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{"#BD93F9", true},
+		{"#bd93f9", true},
+		{"#fff", true},
+		{"0", true},
+		{"141", true},
+		{"255", true},
+		{"256", false},
+		{"999", false},
+		{"-1", false},
+		{"#ff", false},
+		{"#GGGGGG", false},
+		{"BD93F9", false},
+		{"purple", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if err := checkColor(tt.value); (err == nil) != tt.ok {
+				t.Errorf("checkColor(%q) = %v, want ok %v", tt.value, err, tt.ok)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"strings"
+	"time"
+)
+
 type FileChange struct {
 	Status    string
 	Path      string
@@ -20,18 +25,32 @@ type ParentInfo struct {
 
 // GraphCommit represents a commit in the visual graph
 type GraphCommit struct {
-	Hash        string
-	FullHash    string
-	Message     string
-	Author      string
-	Date        string
-	Branches    []string
-	Parents     []string
-	ParentInfos []ParentInfo
-	GraphChars  string
-	IsMerge     bool
-	Lane        int
-	Files       []FileChange
+	Hash           string
+	FullHash       string
+	Message        string // subject line
+	Body           string // rest of the message, including any trailers
+	Trailers       []Trailer
+	Author         string
+	AuthorEmail    string
+	Date           time.Time // author date, in the author's timezone
+	Committer      string
+	CommitterEmail string
+	CommitDate     time.Time
+	Signature      SignatureStatus
+	Signer         string // key identity, or key ID when the key is unknown
+	Branches       []string
+	Parents        []string
+	ParentInfos    []ParentInfo
+	GraphChars     string
+	IsMerge        bool
+	Lane           int
+	Files          []FileChange
+}
+
+// Trailer is a "Key: value" line closing a commit message, e.g. Signed-off-by
+type Trailer struct {
+	Key   string
+	Value string
 }
 
 type Branch struct {
@@ -40,4 +59,175 @@ type Branch struct {
 	Hash     string
 	IsRemote bool
 	IsHead   bool
+	Remote   string // remote name for remote branches, e.g. "upstream"
+	Upstream string // tracked remote branch, e.g. "origin/main"
+	Ahead    int
+	Behind   int
+
+	// Signature of an annotated tag; branches are always Unsigned
+	Signature SignatureStatus
+	Signer    string
+}
+
+type Remote struct {
+	Name string
+	URLs []string
+}
+
+// TreeEntry is a file or directory in a commit's tree
+type TreeEntry struct {
+	Name        string
+	Path        string
+	IsDir       bool
+	IsSubmodule bool
+	Size        int64
+}
+
+// Stash is one entry of the stash list, newest first
+type Stash struct {
+	Index    int
+	Name     string // e.g. stash@{0}
+	Hash     string
+	FullHash string
+	Message  string
+	Date     time.Time
+}
+
+// ReflogEntry is one movement of a ref, newest first
+type ReflogEntry struct {
+	Selector    string // e.g. HEAD@{2}
+	OldHash     string
+	NewHash     string
+	FullNewHash string
+	Action      string // e.g. "commit", "reset", "checkout"
+	Message     string
+	Date        time.Time
+}
+
+// Contributor sums up one author's commits in a range, with identities
+// merged through .mailmap
+type Contributor struct {
+	Name      string
+	Email     string
+	Commits   int
+	Additions int // lines added, merges excluded
+	Deletions int
+	First     time.Time
+	Last      time.Time
+}
+
+// ChurnEntry is how much a file or directory changed over a range
+type ChurnEntry struct {
+	Path      string
+	IsDir     bool
+	Commits   int // commits that touched it
+	Additions int
+	Deletions int
+	Last      time.Time // author date of the latest change
+}
+
+// DayLayout formats a time as its calendar day. Activity counts are keyed
+// by it, and commit filters compare days in it.
+const DayLayout = "2006-01-02"
+
+// CommitFilter narrows the commits the graph loads
+type CommitFilter struct {
+	Author string    // author name after .mailmap
+	Path   string    // file or directory the commits changed
+	Until  time.Time // skip commits authored after this day
+}
+
+func (f CommitFilter) IsZero() bool {
+	return f == CommitFilter{}
+}
+
+// String describes the filter for the graph header, e.g.
+// "author: Alice · path: internal/git"
+func (f CommitFilter) String() string {
+	var parts []string
+	if f.Author != "" {
+		parts = append(parts, "author: "+f.Author)
+	}
+	if f.Path != "" {
+		parts = append(parts, "path: "+f.Path)
+	}
+	if !f.Until.IsZero() {
+		parts = append(parts, "until: "+f.Until.Format(DayLayout))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// RangeDiffPair matches a commit of an old range with its counterpart in a
+// new range. Diff is the diff between the two patches.
+type RangeDiffPair struct {
+	Status   string // "equal", "modified", "added", "dropped"
+	OldIndex int    // 1-based position in the old range, 0 when added
+	NewIndex int    // 1-based position in the new range, 0 when dropped
+	Old      GraphCommit
+	New      GraphCommit
+	Diff     []DiffLine
+}
+
+// SignatureStatus is the result of verifying a commit or tag signature
+type SignatureStatus int
+
+const (
+	Unsigned      SignatureStatus = iota
+	GoodSignature                 // made by a trusted key
+	BadSignature                  // does not match the signed content
+	UnknownKey                    // key is not in the keyring or allowed signers
+	Unverified                    // signed, but not checked yet
+)
+
+func (s SignatureStatus) String() string {
+	switch s {
+	case GoodSignature:
+		return "valid"
+	case BadSignature:
+		return "invalid"
+	case UnknownKey:
+		return "unknown key"
+	case Unverified:
+		return "signed"
+	default:
+		return "unsigned"
+	}
+}
+
+// CompareMode selects how two branches are diffed against each other
+type CompareMode int
+
+const (
+	ThreeDotCompare CompareMode = iota // merge base to source, like a pull request
+	TwoDotCompare                      // target tip to source tip
+)
+
+// Range formats the comparison the way git spells it, e.g. main...feature
+func (c CompareMode) Range(target, source string) string {
+	if c == TwoDotCompare {
+		return target + ".." + source
+	}
+	return target + "..." + source
+}
+
+// Divergence is how two branches have drifted apart. Incoming commits are on
+// Target but not Source, outgoing ones on Source but not Target.
+type Divergence struct {
+	Target    string
+	Source    string
+	Mode      CompareMode
+	MergeBase *GraphCommit // nil when the histories are unrelated
+	Incoming  []GraphCommit
+	Outgoing  []GraphCommit
+	Files     []FileChange
+	Conflicts []string // files both sides changed differently since the merge base
+}
+
+// Totals sums the lines added and deleted across Files.
+func (d Divergence) Totals() (additions, deletions int) {
+	for _, f := range d.Files {
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+	return additions, deletions
 }
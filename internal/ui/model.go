@@ -1,13 +1,22 @@
 package ui
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomiwa-a/git-radar/internal/config"
+	"github.com/tomiwa-a/git-radar/internal/export"
 	"github.com/tomiwa-a/git-radar/internal/git"
 	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/internal/ui/screens"
+	"github.com/tomiwa-a/git-radar/utils"
 )
 
 type Pane int
@@ -22,6 +31,14 @@ type ComparePane int
 const (
 	LocalComparePane ComparePane = iota
 	RemoteComparePane
+	TagComparePane
+)
+
+type CompareSide int
+
+const (
+	TargetSide CompareSide = iota
+	SourceSide
 )
 
 type Screen int
@@ -31,12 +48,75 @@ const (
 	DivergenceScreen
 	CommitDetailScreen
 	DiffViewScreen
+	StashScreen
+	ReflogScreen
+	TreeScreen
+	TreeFileScreen
+	RangeDiffScreen
+	RangeDiffPatchScreen
+	MessageScreen
+	ContributorsScreen
+	HeatmapScreen
+	ChurnScreen
+)
+
+// ContributorSort orders the contributors screen
+type ContributorSort int
+
+const (
+	SortByCommits ContributorSort = iota
+	SortByLines
+	SortByName
+	SortByFirst
+	SortByLast
 )
 
+func (s ContributorSort) String() string {
+	switch s {
+	case SortByLines:
+		return "lines"
+	case SortByName:
+		return "name"
+	case SortByFirst:
+		return "first commit"
+	case SortByLast:
+		return "last commit"
+	default:
+		return "commits"
+	}
+}
+
 type BranchesLoadedMsg struct {
 	Branches      []types.Branch
+	Tags          []types.Branch
 	CurrentBranch string
 	GitService    *git.Service
+	Fingerprint   string // set on the first load, which starts watching the repository
+}
+
+// RepoChangedMsg reports that HEAD, a ref or the index changed on disk,
+// for example after a commit or fetch in another terminal
+type RepoChangedMsg struct {
+	Fingerprint string
+}
+
+type RepoUnchangedMsg struct{}
+
+// RepoRefreshedMsg carries branches and commits reloaded after a change.
+// Commits are those of Branch, the branch in view, which only follows HEAD
+// when it was HEAD's branch before.
+type RepoRefreshedMsg struct {
+	Branches []types.Branch
+	Tags     []types.Branch
+	Branch   string
+	Head     string
+	Commits  []types.GraphCommit
+}
+
+// TrackingLoadedMsg carries local branches with upstream and ahead/behind
+// counts, which are slower to compute than the branch list itself.
+type TrackingLoadedMsg struct {
+	Branches []types.Branch
 }
 
 type CommitsLoadedMsg struct {
@@ -47,12 +127,25 @@ type DetailsLoadedMsg struct {
 	FullHash    string
 	ParentInfos []types.ParentInfo
 	Files       []types.FileChange
+	Signature   types.SignatureStatus
+	Signer      string
+}
+
+// TagVerifiedMsg carries the signature of a tag checked on demand.
+type TagVerifiedMsg struct {
+	Name      string // full ref name
+	Signature types.SignatureStatus
+	Signer    string
+	Err       error
 }
 
 type DebounceTickMsg struct {
 	FullHash string
 }
 
+// ClockTickMsg re-renders relative dates so they do not go stale
+type ClockTickMsg struct{}
+
 type DivergenceLoadedMsg struct {
 	MergeBase      *types.GraphCommit
 	Incoming       []types.GraphCommit
@@ -60,10 +153,92 @@ type DivergenceLoadedMsg struct {
 	TotalFiles     int
 	TotalAdditions int
 	TotalDeletions int
+	Files          []types.FileChange
+	Conflicts      []string
+	CherryPicking  bool
+}
+
+// ExportedMsg reports where a report or graph export was written
+type ExportedMsg struct {
+	Path string
+	Err  error
+}
+
+type CherryPickDoneMsg struct {
+	Aborted bool
+	Err     error
+}
+
+type StashesLoadedMsg struct {
+	Stashes []types.Stash
+}
+
+type StashDoneMsg struct {
+	Op  string
+	Err error
+}
+
+type ReflogLoadedMsg struct {
+	Ref     string
+	Entries []types.ReflogEntry
+}
+
+type BranchCreatedMsg struct {
+	Name string
+	Err  error
+}
+
+type TreeLoadedMsg struct {
+	Dir     string
+	Entries []types.TreeEntry
+	Err     error
+}
+
+type ContributorsLoadedMsg struct {
+	Range        string
+	Contributors []types.Contributor
+	Err          error
+}
+
+type ActivityLoadedMsg struct {
+	Filter types.CommitFilter
+	Days   map[string]int
+	Err    error
+}
+
+type ChurnLoadedMsg struct {
+	Window  string
+	Entries []types.ChurnEntry
+	Err     error
+}
+
+type RangeDiffLoadedMsg struct {
+	Ranges string
+	Pairs  []types.RangeDiffPair
+	Err    error
+}
+
+// RemoteProgressMsg carries a progress line from a running fetch, pull or
+// push. The update loop keeps listening on ch until RemoteOpDoneMsg arrives.
+type RemoteProgressMsg struct {
+	Line string
+	ch   <-chan tea.Msg
+}
+
+type RemoteOpDoneMsg struct {
+	Op  string
+	Err error
 }
 
 type ClearAlertMsg struct{}
 
+// ErrorMsg reports that loading from the repository failed. Retry, when
+// set, runs the same load again.
+type ErrorMsg struct {
+	Err   error
+	Retry tea.Cmd
+}
+
 type Model struct {
 	Incoming             []types.GraphCommit
 	Outgoing             []types.GraphCommit
@@ -82,7 +257,8 @@ type Model struct {
 	ViewportReady        bool
 	GraphCommits         []types.GraphCommit
 	GraphIdx             int
-	CurrentBranch        string
+	CurrentBranch        string // branch the graph shows
+	HeadBranch           string // branch checked out, as last read
 	Branches             []types.Branch
 	ShowBranchModal      bool
 	BranchModalIdx       int
@@ -92,6 +268,8 @@ type Model struct {
 	GraphViewport        viewport.Model
 	GraphViewportReady   bool
 	GitService           *git.Service
+	Config               config.Config
+	DateFormat           utils.DateFormat // how every screen shows dates; d cycles it
 	RepoPath             string
 	LoadingBranches      bool
 	LoadingCommits       bool
@@ -103,6 +281,11 @@ type Model struct {
 	TotalAdditions       int
 	TotalDeletions       int
 	AlertMessage         string
+	LoadError            error
+	LoadRetry            tea.Cmd
+	RemoteOp             string
+	RemoteStatus         string
+	ConfirmRemoteOp      string // "pull" or "push" waiting for a y
 	ShowFilter           bool
 	FilterInput          textinput.Model
 	FilteredFiles        []types.FileChange
@@ -114,6 +297,12 @@ type Model struct {
 	CompareFilterInput   textinput.Model
 	FilteredLocal        []types.Branch
 	FilteredRemote       []types.Branch
+	Tags                 []types.Branch
+	FilteredTags         []types.Branch
+	CompareTagPane       viewport.Model
+	CompareSide          CompareSide
+	CompareTarget        string
+	CompareSource        string
 	BranchLocalPane      viewport.Model
 	BranchRemotePane     viewport.Model
 	ActiveBranchPane     ComparePane // Reusing enum
@@ -123,11 +312,80 @@ type Model struct {
 	ShowGraphSearch      bool
 	GraphSearchInput     textinput.Model
 	FilteredGraphCommits []types.GraphCommit
+	PickedCommits        map[string]bool
+	CherryPicking        bool
+	CherryPickConflicts  []string
+	CherryPickRemaining  []string
+	ConflictFiles        []string
+	ShowExportInput      bool
+	ExportInput          textinput.Model
+	BranchDiffFiles      []types.FileChange
+	BranchDiffMode       bool
+	CompareMode          types.CompareMode
+	Stashes              []types.Stash
+	StashIdx             int
+	LoadingStashes       bool
+	ShowStashInput       bool
+	StashUntracked       bool // the stash being named takes untracked files too
+	StashInput           textinput.Model
+	ConfirmStashDrop     bool
+	ReflogRefs           []string
+	ReflogRefIdx         int
+	Reflog               []types.ReflogEntry
+	ReflogIdx            int
+	LoadingReflog        bool
+	ShowReflogInput      bool
+	ReflogInput          textinput.Model
+	TreeCommit           types.GraphCommit
+	TreeDir              string
+	TreeEntries          []types.TreeEntry
+	TreeIdx              int
+	TreeSelect           string // entry to select once the tree loads
+	TreeError            string
+	TreeFile             string
+	LoadingTree          bool
+	RangeDiffRanges      string
+	RangeDiffPairs       []types.RangeDiffPair
+	RangeDiffIdx         int
+	RangeDiffError       string
+	LoadingRangeDiff     bool
+	RangeDiffReturn      Screen
+	ShowRangeDiffInput   bool
+	RangeDiffInput       textinput.Model
+	MessageCommit        types.GraphCommit
+	GraphFilter          types.CommitFilter
+	Contributors         []types.Contributor
+	ContributorIdx       int
+	ContributorRange     string
+	ContributorSort      ContributorSort
+	ContributorReverse   bool
+	ContributorError     string
+	LoadingContributors  bool
+	ShowContributorInput bool
+	ContributorInput     textinput.Model
+	Activity             map[string]int // commits per day, keyed by types.DayLayout
+	ActivityFilter       types.CommitFilter
+	ActivityDay          time.Time
+	ActivityError        string
+	LoadingActivity      bool
+	ActivityInputField   string // "author" or "path" while the filter prompt is open
+	ActivityInput        textinput.Model
+	Churn                []types.ChurnEntry
+	ChurnWindow          string
+	ChurnIdx             int
+	ChurnDirs            bool // directories tab rather than files
+	ChurnByLines         bool // rank by lines churned rather than commits
+	ChurnError           string
+	LoadingChurn         bool
+	ShowChurnInput       bool
+	ChurnInput           textinput.Model
+	RepoFingerprint      string
 }
 
 func InitialModel(repoPath string) Model {
 	return Model{
 		RepoPath:           repoPath,
+		Config:             config.Default(),
 		GraphCommits:       []types.GraphCommit{},
 		Branches:           nil,
 		CurrentBranch:      "",
@@ -161,6 +419,13 @@ func InitialModel(repoPath string) Model {
 		BranchFilterInput:  textinput.New(),
 		ShowGraphSearch:    false,
 		GraphSearchInput:   textinput.New(),
+		StashInput:         textinput.New(),
+		ReflogInput:        textinput.New(),
+		RangeDiffInput:     textinput.New(),
+		ContributorInput:   textinput.New(),
+		ActivityInput:      textinput.New(),
+		ChurnInput:         textinput.New(),
+		ExportInput:        textinput.New(),
 	}
 }
 
@@ -168,6 +433,7 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
 		m.loadInitialDataCmd(),
+		clockTickCmd(),
 	)
 }
 
@@ -175,16 +441,20 @@ func (m Model) loadInitialDataCmd() tea.Cmd {
 	return func() tea.Msg {
 		service, err := git.NewService(m.RepoPath)
 		if err != nil {
-			return nil
+			return ErrorMsg{Err: err, Retry: m.loadInitialDataCmd()}
 		}
 
-		branches, _ := service.GetBranches()
-		current, _ := service.GetCurrentBranch()
+		branches, tags, current, err := loadRefs(service)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadInitialDataCmd()}
+		}
 
 		return BranchesLoadedMsg{
 			Branches:      branches,
+			Tags:          tags,
 			CurrentBranch: current,
 			GitService:    service,
+			Fingerprint:   service.Fingerprint(),
 		}
 	}
 }
@@ -201,21 +471,102 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case BranchesLoadedMsg:
 		m.Branches = msg.Branches
-		m.CurrentBranch = msg.CurrentBranch
+		m.Tags = msg.Tags
+		// Keep a branch picked with b in view; follow HEAD otherwise
+		if m.CurrentBranch == "" || m.CurrentBranch == m.HeadBranch {
+			m.CurrentBranch = msg.CurrentBranch
+		}
+		m.HeadBranch = msg.CurrentBranch
+		opened := msg.GitService != m.GitService
 		m.GitService = msg.GitService
+		m.Config = msg.GitService.Config()
+		screens.SetTheme(m.Config.Theme)
 		m.LoadingBranches = false
-		return m, m.loadCommitsCmd(m.CurrentBranch, 100)
+		cmds := []tea.Cmd{
+			m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit),
+			m.loadTrackingCmd(),
+		}
+		// Signatures still load without the keys, so just say once why
+		if err := m.GitService.SignersError(); err != nil && opened {
+			m.AlertMessage = err.Error()
+			cmds = append(cmds, clearAlertCmd())
+		}
+		// Bad settings fall back to their defaults; the banner stays until dismissed
+		if err := m.GitService.ConfigError(); err != nil && opened {
+			m.LoadError, m.LoadRetry = err, nil
+		}
+		if msg.Fingerprint != "" {
+			m.RepoFingerprint = msg.Fingerprint
+			cmds = append(cmds, m.watchRepoCmd())
+		}
+		return m, tea.Batch(cmds...)
+
+	case RepoUnchangedMsg:
+		return m, m.watchRepoCmd()
+
+	case RepoChangedMsg:
+		// A commit load may have read the repository before the change, so
+		// keep the old fingerprint and catch the change again once it ends
+		if m.LoadingCommits {
+			return m, m.watchRepoCmd()
+		}
+		m.RepoFingerprint = msg.Fingerprint
+		// A running fetch, pull or push reloads everything when it ends
+		if m.RemoteOp != "" {
+			return m, m.watchRepoCmd()
+		}
+		return m, tea.Batch(m.watchRepoCmd(), m.refreshRepoCmd())
+
+	case RepoRefreshedMsg:
+		m.Branches = msg.Branches
+		m.Tags = msg.Tags
+		m.CurrentBranch = msg.Branch
+		m.HeadBranch = msg.Head
+		m = m.mergeCommits(msg.Commits)
+		if m.Screen == GraphScreen {
+			m = m.updateGraphViewportContent()
+			m = m.scrollToGraphSelection()
+		}
+		cmds := []tea.Cmd{m.loadTrackingCmd()}
+		if commits := m.getDisplayCommits(); m.GraphIdx < len(commits) && commits[m.GraphIdx].Files == nil {
+			m.PendingDetailsHash = commits[m.GraphIdx].FullHash
+			cmds = append(cmds, m.debounceDetailsCmd(m.PendingDetailsHash))
+		}
+		return m, tea.Batch(cmds...)
+
+	case TrackingLoadedMsg:
+		tracking := make(map[string]types.Branch)
+		for _, b := range msg.Branches {
+			tracking[b.FullName] = b
+		}
+		m.Branches = applyTracking(m.Branches, tracking)
+		m.LocalBranches = applyTracking(m.LocalBranches, tracking)
+		m.BranchFilteredLocal = applyTracking(m.BranchFilteredLocal, tracking)
+		if m.ShowBranchModal {
+			m = m.updateBranchViewportContent()
+		}
+		return m, nil
 
 	case CommitsLoadedMsg:
 		m.GraphCommits = msg.Commits
 		m.LoadingCommits = false
 		m.GraphIdx = 0
+		// Coming from the heatmap, start on the chosen day's first commit
+		if day := m.GraphFilter.Until; !day.IsZero() {
+			for i, c := range m.GraphCommits {
+				if c.Date.Format(types.DayLayout) == day.Format(types.DayLayout) {
+					m.GraphIdx = i
+					break
+				}
+			}
+		}
 		if m.Screen == GraphScreen {
 			m = m.initGraphViewport()
 		}
 		if len(m.GraphCommits) > 0 {
-			m.PendingDetailsHash = m.GraphCommits[0].FullHash
-			return m, m.debounceDetailsCmd(m.GraphCommits[0].FullHash)
+			m = m.scrollToGraphSelection()
+			m.PendingDetailsHash = m.GraphCommits[m.GraphIdx].FullHash
+			return m, m.debounceDetailsCmd(m.GraphCommits[m.GraphIdx].FullHash)
 		}
 		return m, nil
 
@@ -225,19 +576,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case ClockTickMsg:
+		if m.Screen == GraphScreen && m.GraphViewportReady {
+			m = m.updateGraphViewportContent()
+		}
+		return m, clockTickCmd()
+
 	case DetailsLoadedMsg:
 		for i := range m.GraphCommits {
 			if m.GraphCommits[i].FullHash == msg.FullHash {
 				m.GraphCommits[i].ParentInfos = msg.ParentInfos
 				m.GraphCommits[i].Files = msg.Files
+				m.GraphCommits[i].Signature, m.GraphCommits[i].Signer = msg.Signature, msg.Signer
 				break
 			}
 		}
 		if m.SelectedCommit.FullHash == msg.FullHash {
 			m.SelectedCommit.ParentInfos = msg.ParentInfos
 			m.SelectedCommit.Files = msg.Files
+			m.SelectedCommit.Signature, m.SelectedCommit.Signer = msg.Signature, msg.Signer
 		}
 		m.LoadingDetails = false
+		if m.Screen == GraphScreen {
+			m = m.updateGraphViewportContent()
+		}
+		return m, nil
+
+	case TagVerifiedMsg:
+		if msg.Err != nil {
+			m.AlertMessage = msg.Err.Error()
+			return m, clearAlertCmd()
+		}
+		for _, tags := range [][]types.Branch{m.Tags, m.FilteredTags} {
+			for i := range tags {
+				if tags[i].FullName == msg.Name {
+					tags[i].Signature, tags[i].Signer = msg.Signature, msg.Signer
+				}
+			}
+		}
+		if m.ShowCompareModal {
+			m = m.updateCompareViewportContent()
+		}
 		return m, nil
 
 	case DivergenceLoadedMsg:
@@ -247,15 +626,202 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.TotalFiles = msg.TotalFiles
 		m.TotalAdditions = msg.TotalAdditions
 		m.TotalDeletions = msg.TotalDeletions
+		m.BranchDiffFiles = msg.Files
+		m.ConflictFiles = msg.Conflicts
 		m.LoadingDivergence = false
 		m.IncomingIdx = 0
 		m.OutgoingIdx = 0
+		m.CherryPicking = msg.CherryPicking
+		if !msg.CherryPicking {
+			m.CherryPickConflicts = nil
+			m.CherryPickRemaining = nil
+		}
 		return m, nil
 
+	case ExportedMsg:
+		if msg.Err != nil {
+			m.AlertMessage = msg.Err.Error()
+		} else {
+			m.AlertMessage = "Exported to " + msg.Path
+		}
+		return m, clearAlertCmd()
+
+	case CherryPickDoneMsg:
+		var conflictErr *git.CherryPickConflictError
+		m.CherryPickConflicts = nil
+		m.CherryPickRemaining = nil
+		switch {
+		case errors.As(msg.Err, &conflictErr):
+			m.CherryPicking = true
+			m.CherryPickConflicts = conflictErr.Files
+			m.CherryPickRemaining = conflictErr.Remaining
+			m.AlertMessage = conflictErr.Error()
+		case msg.Err != nil:
+			m.AlertMessage = msg.Err.Error()
+		case msg.Aborted:
+			m.CherryPicking = false
+			m.AlertMessage = "Cherry-pick aborted"
+		default:
+			m.AlertMessage = "Cherry-pick complete!"
+		}
+		m.PickedCommits = nil
+		m.LoadingDivergence = true
+		return m, tea.Batch(
+			clearAlertCmd(),
+			m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit),
+			m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch),
+			m.loadTrackingCmd(),
+		)
+
+	case StashesLoadedMsg:
+		m.Stashes = msg.Stashes
+		m.LoadingStashes = false
+		if m.StashIdx >= len(m.Stashes) {
+			m.StashIdx = max(len(m.Stashes)-1, 0)
+		}
+		return m, nil
+
+	case StashDoneMsg:
+		var conflict *git.StashConflictError
+		switch {
+		case errors.As(msg.Err, &conflict):
+			m.AlertMessage = conflict.Error() + ", stash kept"
+		case msg.Err != nil:
+			m.AlertMessage = msg.Err.Error()
+		default:
+			m.AlertMessage = "Stash " + msg.Op + " complete!"
+		}
+		m.LoadingStashes = true
+		return m, tea.Batch(
+			clearAlertCmd(),
+			m.loadStashesCmd(),
+			m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit),
+		)
+
+	case ReflogLoadedMsg:
+		if len(m.ReflogRefs) > 0 && m.ReflogRefs[m.ReflogRefIdx] != msg.Ref {
+			return m, nil
+		}
+		m.Reflog = msg.Entries
+		m.LoadingReflog = false
+		return m, nil
+
+	case BranchCreatedMsg:
+		if msg.Err != nil {
+			m.AlertMessage = msg.Err.Error()
+			return m, clearAlertCmd()
+		}
+		m.AlertMessage = "Created branch " + msg.Name
+		return m, tea.Batch(clearAlertCmd(), m.reloadBranchesCmd())
+
+	case TreeLoadedMsg:
+		if msg.Dir != m.TreeDir {
+			return m, nil
+		}
+		m.LoadingTree = false
+		m.TreeEntries = msg.Entries
+		m.TreeError = ""
+		if msg.Err != nil {
+			m.TreeError = msg.Err.Error()
+		}
+		m.TreeIdx = 0
+		for i, e := range m.TreeEntries {
+			if e.Name == m.TreeSelect {
+				m.TreeIdx = i
+				break
+			}
+		}
+		m.TreeSelect = ""
+		return m, nil
+
+	case RangeDiffLoadedMsg:
+		if msg.Ranges != m.RangeDiffRanges {
+			return m, nil
+		}
+		m.LoadingRangeDiff = false
+		m.RangeDiffPairs = msg.Pairs
+		m.RangeDiffError = ""
+		if msg.Err != nil {
+			m.RangeDiffError = msg.Err.Error()
+		}
+		m.RangeDiffIdx = 0
+		return m, nil
+
+	case ContributorsLoadedMsg:
+		if msg.Range != m.ContributorRange {
+			return m, nil
+		}
+		m.LoadingContributors = false
+		m.Contributors = sortContributors(msg.Contributors, m.ContributorSort, m.ContributorReverse)
+		m.ContributorError = ""
+		if msg.Err != nil {
+			m.ContributorError = msg.Err.Error()
+		}
+		m.ContributorIdx = 0
+		return m, nil
+
+	case ActivityLoadedMsg:
+		if msg.Filter != m.ActivityFilter {
+			return m, nil
+		}
+		m.LoadingActivity = false
+		m.Activity = msg.Days
+		m.ActivityError = ""
+		if msg.Err != nil {
+			m.ActivityError = msg.Err.Error()
+		}
+		// Start on the latest day with commits
+		m.ActivityDay = today()
+		if days := activeDays(m.Activity); len(days) > 0 {
+			m.ActivityDay, _ = time.ParseInLocation(types.DayLayout, days[len(days)-1], time.Local)
+		}
+		return m, nil
+
+	case ChurnLoadedMsg:
+		if msg.Window != m.ChurnWindow {
+			return m, nil
+		}
+		m.LoadingChurn = false
+		m.Churn = msg.Entries
+		m.ChurnError = ""
+		if msg.Err != nil {
+			m.ChurnError = msg.Err.Error()
+		}
+		m.ChurnIdx = 0
+		return m, nil
+
+	case RemoteProgressMsg:
+		if msg.Line != "" {
+			m.RemoteStatus = m.RemoteOp + ": " + msg.Line
+		}
+		return m, waitForRemoteMsg(msg.ch)
+
+	case RemoteOpDoneMsg:
+		m.RemoteOp = ""
+		m.RemoteStatus = ""
+		if msg.Err != nil {
+			m.AlertMessage = msg.Err.Error()
+		} else {
+			m.AlertMessage = strings.ToUpper(msg.Op[:1]) + msg.Op[1:] + " complete!"
+		}
+		m.LoadingCommits = true
+		return m, tea.Batch(clearAlertCmd(), m.reloadBranchesCmd())
+
 	case ClearAlertMsg:
 		m.AlertMessage = ""
 		return m, nil
 
+	case ErrorMsg:
+		m.LoadError = msg.Err
+		m.LoadRetry = msg.Retry
+		m.LoadingBranches = false
+		m.LoadingCommits = false
+		m.LoadingDivergence = false
+		m.LoadingDetails = false
+		m.LoadingStashes = false
+		m.LoadingReflog = false
+		return m, nil
+
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
@@ -269,7 +835,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCompareModal(msg)
 		}
 
-		if msg.String() == "b" {
+		if m.LoadError != nil && !m.textInputFocused() && !m.ShowGraphSearch && !m.ShowFilter {
+			switch msg.String() {
+			case "r":
+				return m.retryLoad()
+			case "esc":
+				if m.GitService != nil {
+					m.LoadError, m.LoadRetry = nil, nil
+					return m, nil
+				}
+			case "q":
+				if m.GitService == nil {
+					return m, tea.Quit
+				}
+			}
+			// Nothing else works without a repository
+			if m.GitService == nil {
+				return m, nil
+			}
+		}
+
+		if msg.String() == "b" && !m.textInputFocused() {
 			m.ShowBranchModal = true
 			m.BranchModalIdx = 0
 			m.ActiveBranchPane = LocalComparePane // Use Local as default
@@ -303,21 +889,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCommitDetail(msg)
 		case DiffViewScreen:
 			return m.updateDiffs(msg)
+		case StashScreen:
+			return m.updateStash(msg)
+		case ReflogScreen:
+			return m.updateReflog(msg)
+		case TreeScreen:
+			return m.updateTree(msg)
+		case TreeFileScreen:
+			return m.updateTreeFile(msg)
+		case RangeDiffScreen:
+			return m.updateRangeDiff(msg)
+		case RangeDiffPatchScreen:
+			return m.updateRangeDiffPatch(msg)
+		case MessageScreen:
+			return m.updateMessage(msg)
+		case ContributorsScreen:
+			return m.updateContributors(msg)
+		case HeatmapScreen:
+			return m.updateHeatmap(msg)
+		case ChurnScreen:
+			return m.updateChurn(msg)
 		}
 	}
 	return m, nil
 }
 
-func (m Model) getComparableBranches() []string {
-	var branches []string
-	for _, branch := range m.Branches {
-		if branch.Name != m.CurrentBranch {
-			branches = append(branches, branch.Name)
+func applyTracking(branches []types.Branch, tracking map[string]types.Branch) []types.Branch {
+	for i, b := range branches {
+		if t, ok := tracking[b.FullName]; ok {
+			branches[i].Upstream = t.Upstream
+			branches[i].Ahead = t.Ahead
+			branches[i].Behind = t.Behind
 		}
 	}
 	return branches
 }
 
+// currentBranchInfo returns the checked-out branch, including its tracking
+// information once that has loaded.
+func (m Model) currentBranchInfo() types.Branch {
+	for _, b := range m.Branches {
+		if !b.IsRemote && b.Name == m.CurrentBranch {
+			return b
+		}
+	}
+	return types.Branch{Name: m.CurrentBranch}
+}
+
 func (m Model) findCommitByHash(hash string) types.GraphCommit {
 	for _, c := range m.Incoming {
 		if c.Hash == hash {
@@ -334,16 +952,80 @@ func (m Model) findCommitByHash(hash string) types.GraphCommit {
 
 func (m Model) loadCommitsCmd(branch string, limit int) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		commits, err := m.GitService.GetCommits(branch, limit)
-		if err != nil {
+		commits, err := m.GitService.GetCommits(branch, limit, m.GraphFilter)
+		if errors.Is(err, git.ErrNoCommits) {
 			return CommitsLoadedMsg{Commits: []types.GraphCommit{}}
 		}
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadCommitsCmd(branch, limit)}
+		}
 		return CommitsLoadedMsg{Commits: commits}
 	})
 }
 
+// watchRepoCmd checks the repository for outside changes every watch
+// interval, comparing against the fingerprint taken at the last check.
+func (m Model) watchRepoCmd() tea.Cmd {
+	service, last := m.GitService, m.RepoFingerprint
+	return tea.Tick(m.Config.WatchInterval, func(t time.Time) tea.Msg {
+		if fp := service.Fingerprint(); fp != last {
+			return RepoChangedMsg{Fingerprint: fp}
+		}
+		return RepoUnchangedMsg{}
+	})
+}
+
+// refreshRepoCmd reloads refs and the graph after an outside change, at
+// least as many commits as are loaded now. The graph stays on the branch in
+// view unless that was HEAD's branch or has gone, when it follows HEAD.
+func (m Model) refreshRepoCmd() tea.Cmd {
+	service, filter := m.GitService, m.GraphFilter
+	viewed, head := m.CurrentBranch, m.HeadBranch
+	limit := max(m.Config.CommitLimit, len(m.GraphCommits))
+	var refresh tea.Cmd
+	refresh = func() tea.Msg {
+		if err := service.BuildBranchMap(); err != nil {
+			return ErrorMsg{Err: err, Retry: refresh}
+		}
+		branches, tags, current, err := loadRefs(service)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: refresh}
+		}
+		branch := viewed
+		if _, err := service.ResolveRevision(viewed); viewed == head || err != nil {
+			branch = current
+		}
+		commits, err := service.GetCommits(branch, limit, filter)
+		if err != nil && !errors.Is(err, git.ErrNoCommits) {
+			return ErrorMsg{Err: err, Retry: refresh}
+		}
+
+		return RepoRefreshedMsg{
+			Branches: branches,
+			Tags:     tags,
+			Branch:   branch,
+			Head:     current,
+			Commits:  commits,
+		}
+	}
+	return refresh
+}
+
+func (m Model) loadTrackingCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.GitService == nil {
+			return nil
+		}
+		branches, err := m.GitService.GetBranchTracking()
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadTrackingCmd()}
+		}
+		return TrackingLoadedMsg{Branches: branches}
+	}
+}
+
 func (m Model) debounceDetailsCmd(fullHash string) tea.Cmd {
-	return tea.Tick(200*time.Millisecond, func(t time.Time) tea.Msg {
+	return tea.Tick(m.Config.DetailsDelay, func(t time.Time) tea.Msg {
 		return DebounceTickMsg{FullHash: fullHash}
 	})
 }
@@ -352,38 +1034,323 @@ func (m Model) loadDetailsCmd(fullHash string) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		parentInfos, files, err := m.GitService.GetCommitDetails(fullHash)
 		if err != nil {
-			return DetailsLoadedMsg{FullHash: fullHash}
+			return ErrorMsg{Err: err, Retry: m.loadDetailsCmd(fullHash)}
+		}
+		signature, signer, err := m.GitService.VerifyCommit(fullHash)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadDetailsCmd(fullHash)}
 		}
 		return DetailsLoadedMsg{
 			FullHash:    fullHash,
 			ParentInfos: parentInfos,
 			Files:       files,
+			Signature:   signature,
+			Signer:      signer,
 		}
 	})
 }
 
 func (m Model) loadDivergenceCmd(target, source string) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		mergeBase, _ := m.GitService.GetMergeBase(target, source)
-		incoming, _ := m.GitService.GetIncomingCommits(target, source)
-		outgoing, _ := m.GitService.GetOutgoingCommits(target, source)
-
-		diffStats, _ := m.GitService.GetBranchDiffStats(source, target)
-		totalFiles := len(diffStats)
-		totalAdds, totalDels := 0, 0
-		for _, f := range diffStats {
-			totalAdds += f.Additions
-			totalDels += f.Deletions
+		d, err := m.GitService.Divergence(target, source, m.CompareMode)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadDivergenceCmd(target, source)}
 		}
+		// Picked commits leave Incoming even though they were not merged
+		if err := m.GitService.HideCherryPicked(d); err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadDivergenceCmd(target, source)}
+		}
+		totalAdds, totalDels := d.Totals()
 
 		return DivergenceLoadedMsg{
-			MergeBase:      mergeBase,
-			Incoming:       incoming,
-			Outgoing:       outgoing,
-			TotalFiles:     totalFiles,
+			MergeBase:      d.MergeBase,
+			Incoming:       d.Incoming,
+			Outgoing:       d.Outgoing,
+			TotalFiles:     len(d.Files),
 			TotalAdditions: totalAdds,
 			TotalDeletions: totalDels,
+			Files:          d.Files,
+			Conflicts:      d.Conflicts,
+			CherryPicking:  m.GitService.CherryPickInProgress(),
+		}
+	})
+}
+
+// exportDivergenceCmd writes the loaded comparison to path, as HTML or
+// Markdown depending on its extension.
+func (m Model) exportDivergenceCmd(path string) tea.Cmd {
+	d := &types.Divergence{
+		Target:    m.TargetBranch,
+		Source:    m.SourceBranch,
+		Mode:      m.CompareMode,
+		MergeBase: m.MergeBase,
+		Incoming:  m.Incoming,
+		Outgoing:  m.Outgoing,
+		Files:     m.BranchDiffFiles,
+		Conflicts: m.ConflictFiles,
+	}
+	path = m.exportPath(path)
+	return exportCmd(path, func(w io.Writer) error {
+		return export.WriteDivergence(w, d, export.DivergenceFormat(path), time.Now())
+	})
+}
+
+// exportGraphCmd draws the commits shown in the graph, as DOT when path ends
+// in .dot or .gv and SVG otherwise.
+func (m Model) exportGraphCmd(path string) tea.Cmd {
+	commits := m.getDisplayCommits()
+	refs := append(append([]types.Branch(nil), m.Branches...), m.Tags...)
+	t := m.Config.Theme
+	path = m.exportPath(path)
+	return exportCmd(path, func(w io.Writer) error {
+		return export.WriteGraph(w, commits, refs, export.GraphFormat(path), t)
+	})
+}
+
+// exportPath resolves a relative export path against the repository rather
+// than the directory git-radar was started from, so the alert can say where
+// the file went.
+func (m Model) exportPath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.RepoPath, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// exportCmd creates path and fills it with write in the background.
+func exportCmd(path string, write func(w io.Writer) error) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Create(path)
+		if err != nil {
+			return ExportedMsg{Path: path, Err: err}
+		}
+		err = write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return ExportedMsg{Path: path, Err: err}
+	}
+}
+
+func (m Model) cherryPickCmd(branch string, hashes []string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		return CherryPickDoneMsg{Err: m.GitService.CherryPick(branch, hashes)}
+	})
+}
+
+func (m Model) abortCherryPickCmd() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		return CherryPickDoneMsg{Aborted: true, Err: m.GitService.AbortCherryPick()}
+	})
+}
+
+func (m Model) loadStashesCmd() tea.Cmd {
+	return func() tea.Msg {
+		stashes, err := m.GitService.GetStashes()
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadStashesCmd()}
+		}
+		return StashesLoadedMsg{Stashes: stashes}
+	}
+}
+
+func (m Model) loadReflogCmd(ref string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.GitService.GetReflog(ref)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.loadReflogCmd(ref)}
+		}
+		return ReflogLoadedMsg{Ref: ref, Entries: entries}
+	}
+}
+
+func (m Model) loadTreeCmd(commitHash, dir string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.GitService.GetTree(commitHash, dir)
+		return TreeLoadedMsg{Dir: dir, Entries: entries, Err: err}
+	}
+}
+
+// loadRangeDiffCmd compares the ranges typed as "<old> <new>", or as a
+// single symmetric "<rev1>...<rev2>".
+func (m Model) loadRangeDiffCmd(ranges string) tea.Cmd {
+	return func() tea.Msg {
+		var pairs []types.RangeDiffPair
+		var err error
+		switch fields := strings.Fields(ranges); len(fields) {
+		case 1:
+			pairs, err = m.GitService.RangeDiff(fields[0], "")
+		case 2:
+			pairs, err = m.GitService.RangeDiff(fields[0], fields[1])
+		default:
+			err = errors.New("expected two ranges, or <rev1>...<rev2>")
 		}
+		return RangeDiffLoadedMsg{Ranges: ranges, Pairs: pairs, Err: err}
+	}
+}
+
+func (m Model) loadContributorsCmd(rng string) tea.Cmd {
+	return func() tea.Msg {
+		contributors, err := m.GitService.Contributors(rng)
+		return ContributorsLoadedMsg{Range: rng, Contributors: contributors, Err: err}
+	}
+}
+
+// loadActivityCmd counts the current branch's commits per day.
+func (m Model) loadActivityCmd(filter types.CommitFilter) tea.Cmd {
+	return func() tea.Msg {
+		days, err := m.GitService.Activity(m.CurrentBranch, filter)
+		return ActivityLoadedMsg{Filter: filter, Days: days, Err: err}
+	}
+}
+
+func (m Model) loadChurnCmd(window string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.GitService.Churn(window)
+		return ChurnLoadedMsg{Window: window, Entries: entries, Err: err}
+	}
+}
+
+func (m Model) createBranchCmd(name, hash string) tea.Cmd {
+	return func() tea.Msg {
+		return BranchCreatedMsg{Name: name, Err: m.GitService.CreateBranch(name, hash)}
+	}
+}
+
+// stashCmd runs a stash action: "apply", "pop" and "drop" act on the stash
+// at index, "create" stashes the local changes with message, untracked
+// files included when StashUntracked is set.
+func (m Model) stashCmd(op string, index int, message string) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		switch op {
+		case "apply":
+			err = m.GitService.ApplyStash(index)
+		case "pop":
+			err = m.GitService.PopStash(index)
+		case "drop":
+			err = m.GitService.DropStash(index)
+		case "create":
+			err = m.GitService.CreateStash(message, m.StashUntracked)
+		}
+		return StashDoneMsg{Op: op, Err: err}
+	}
+}
+
+// reloadBranchesCmd re-reads branches and tags after refs have moved, which
+// in turn reloads the graph and tracking counts.
+func (m Model) reloadBranchesCmd() tea.Cmd {
+	return func() tea.Msg {
+		branches, tags, current, err := loadRefs(m.GitService)
+		if err != nil {
+			return ErrorMsg{Err: err, Retry: m.reloadBranchesCmd()}
+		}
+
+		return BranchesLoadedMsg{
+			Branches:      branches,
+			Tags:          tags,
+			CurrentBranch: current,
+			GitService:    m.GitService,
+		}
+	}
+}
+
+// loadRefs reads the branches, tags and checked-out branch the graph and
+// pickers are built from.
+func loadRefs(service *git.Service) (branches, tags []types.Branch, current string, err error) {
+	if branches, err = service.GetBranches(); err != nil {
+		return nil, nil, "", err
+	}
+	if tags, err = service.GetTags(); err != nil {
+		return nil, nil, "", err
+	}
+	if current, err = service.GetCurrentBranch(); err != nil {
+		return nil, nil, "", err
+	}
+	return branches, tags, current, nil
+}
+
+// retryLoad dismisses the error and runs the load that failed again.
+func (m Model) retryLoad() (tea.Model, tea.Cmd) {
+	retry := m.LoadRetry
+	m.LoadError, m.LoadRetry = nil, nil
+	if retry == nil {
+		return m, nil
+	}
+	if m.GitService == nil {
+		m.LoadingBranches = true
+		m.LoadingCommits = true
+	}
+	return m, retry
+}
+
+// textInputFocused reports whether a screen's text input or prompt has the
+// keyboard, so global single-letter keys must not fire.
+func (m Model) textInputFocused() bool {
+	return m.ShowStashInput || m.ConfirmStashDrop || m.ConfirmRemoteOp != "" || m.ShowReflogInput || m.ShowRangeDiffInput || m.ShowContributorInput || m.ActivityInputField != "" || m.ShowChurnInput || m.ShowExportInput
+}
+
+// remoteOpCmd runs fetch, pull or push in the background and streams its
+// progress back as RemoteProgressMsg.
+func (m Model) remoteOpCmd(op string) tea.Cmd {
+	service := m.GitService
+	return func() tea.Msg {
+		ch := make(chan tea.Msg, 1)
+		go func() {
+			w := &progressWriter{ch: ch}
+			var err error
+			switch op {
+			case "fetch":
+				err = service.Fetch(w)
+			case "pull":
+				err = service.Pull(w)
+			case "push":
+				err = service.Push(w)
+			}
+			ch <- RemoteOpDoneMsg{Op: op, Err: err}
+			close(ch)
+		}()
+		return waitForRemoteMsg(ch)()
+	}
+}
+
+func waitForRemoteMsg(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		if p, ok := msg.(RemoteProgressMsg); ok {
+			p.ch = ch
+			return p
+		}
+		return msg
+	}
+}
+
+// progressWriter turns git's sideband progress output into status lines,
+// dropping updates the UI has not caught up with yet.
+type progressWriter struct {
+	ch chan<- tea.Msg
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	lines := strings.FieldsFunc(string(p), func(r rune) bool { return r == '\r' || r == '\n' })
+	if len(lines) > 0 {
+		select {
+		case w.ch <- RemoteProgressMsg{Line: strings.TrimSpace(lines[len(lines)-1])}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func clockTickCmd() tea.Cmd {
+	return tea.Tick(time.Minute, func(t time.Time) tea.Msg {
+		return ClockTickMsg{}
 	})
 }
 
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/tomiwa-a/git-radar/internal/config"
+	"github.com/tomiwa-a/git-radar/internal/git"
+)
+
+func TestRepoChanged(t *testing.T) {
+	tests := []struct {
+		name            string
+		loadingCommits  bool
+		remoteOp        string
+		wantFingerprint string
+	}{
+		{name: "idle", wantFingerprint: "new"},
+		{name: "while commits load", loadingCommits: true, wantFingerprint: "old"},
+		{name: "during a fetch", remoteOp: "fetch", wantFingerprint: "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := InitialModel(".")
+			m.RepoFingerprint = "old"
+			m.LoadingCommits = tt.loadingCommits
+			m.RemoteOp = tt.remoteOp
+
+			updated, cmd := m.Update(RepoChangedMsg{Fingerprint: "new"})
+			if got := updated.(Model).RepoFingerprint; got != tt.wantFingerprint {
+				t.Errorf("RepoFingerprint = %q, want %q", got, tt.wantFingerprint)
+			}
+			if cmd == nil {
+				t.Error("watching stopped")
+			}
+		})
+	}
+}
+
+func TestBadConfigShowsBanner(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	if _, err := gogit.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("[radar]\n\tcommitLimit = 0\n\tdetailsDelay = 1s\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := git.NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() with a bad setting = %v", err)
+	}
+	updated, _ := InitialModel(dir).Update(BranchesLoadedMsg{GitService: service, CurrentBranch: "master"})
+	m := updated.(Model)
+	if m.LoadError == nil || !strings.Contains(m.LoadError.Error(), "radar.commitLimit") {
+		t.Errorf("LoadError = %v, want the bad setting named", m.LoadError)
+	}
+	if m.LoadRetry != nil {
+		t.Error("a config error offers a retry")
+	}
+	if m.Config.CommitLimit != config.Default().CommitLimit || m.Config.DetailsDelay != time.Second {
+		t.Errorf("config = %+v, want the default limit and the good delay", m.Config)
+	}
+
+	// Dismissing the banner leaves the repository open
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m := updated.(Model); m.LoadError != nil {
+		t.Errorf("LoadError after esc = %v", m.LoadError)
+	}
+}
@@ -2,6 +2,7 @@ package screens
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/utils"
 )
 
 func RenderBranchModal(width, height int, localView, remoteView string, filterValue string, activePane int) string {
@@ -10,38 +11,38 @@ func RenderBranchModal(width, height int, localView, remoteView string, filterVa
 
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#BD93F9")).
+		BorderForeground(utils.Theme.Accent).
 		Padding(0, 1)
 
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(utils.Theme.Text).
 		Padding(0, 1)
 
 	localHeader := headerStyle.Render("LOCAL")
 	if activePane == 0 {
 		localHeader = headerStyle.Copy().
-			Background(lipgloss.Color("#BD93F9")).
-			Foreground(lipgloss.Color("#282A36")).
+			Background(utils.Theme.Accent).
+			Foreground(utils.Theme.Background).
 			Render(" LOCAL ")
 	}
 
 	remoteHeader := headerStyle.Render("REMOTE")
 	if activePane == 1 {
 		remoteHeader = headerStyle.Copy().
-			Background(lipgloss.Color("#BD93F9")).
-			Foreground(lipgloss.Color("#282A36")).
+			Background(utils.Theme.Accent).
+			Foreground(utils.Theme.Background).
 			Render(" REMOTE ")
 	}
 
 	// Filter bar
 	filterStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(utils.Theme.Muted).
 		Padding(0, 1)
-	filterBar := filterStyle.Render("Filter: ") + lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2")).Render(filterValue)
+	filterBar := filterStyle.Render("Filter: ") + lipgloss.NewStyle().Foreground(utils.Theme.Text).Render(filterValue)
 
 	divider := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#44475A")).
+		Foreground(utils.Theme.Surface).
 		Render("│")
 
 	paneWidth := (modalWidth - 6) / 2
@@ -61,13 +62,13 @@ func RenderBranchModal(width, height int, localView, remoteView string, filterVa
 	)
 
 	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(utils.Theme.Muted).
 		MarginTop(1).
 		Render("tab: switch pane • ↑/↓: navigate • enter: switch • esc: close")
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
-		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#50FA7B")).Render("Switch Branch")+"\n",
+		lipgloss.NewStyle().Bold(true).Foreground(utils.Theme.Added).Render("Switch Branch")+"\n",
 		titles,
 		"\n",
 		body,
@@ -80,5 +81,5 @@ func RenderBranchModal(width, height int, localView, remoteView string, filterVa
 
 	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("#282A36")))
+		lipgloss.WithWhitespaceForeground(utils.Theme.Background))
 }
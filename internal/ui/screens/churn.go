@@ -0,0 +1,139 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+const churnBarWidth = 12
+
+func RenderChurn(width, height int, window string, entries []types.ChurnEntry, selectedIdx int, dirs bool, byLines bool, loading bool, errMsg string, showInput bool, inputValue string, dates utils.DateFormat) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Hotspots ")
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Header (1) + spacing (1) + tabs (1) + spacing (1) + border (2) + column titles (1) + help (1)
+	reservedHeight := 8
+	if showInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render("Since (e.g. 6 months ago, 2024-01-01) or range (v1.0..main): ")+inputValue+"█") + "\n\n")
+		reservedHeight += 2
+	}
+
+	// Files / directories tabs
+	activeTab := lipgloss.NewStyle().
+		Background(utils.Theme.Accent).
+		Foreground(utils.Theme.Background).
+		Bold(true).
+		Padding(0, 1)
+	inactiveTab := lipgloss.NewStyle().
+		Foreground(utils.Theme.Muted).
+		Padding(0, 1)
+	filesTab, dirsTab := activeTab.Render("Files"), inactiveTab.Render("Directories")
+	if dirs {
+		filesTab, dirsTab = inactiveTab.Render("Files"), activeTab.Render("Directories")
+	}
+	rank := "commits"
+	if byLines {
+		rank = "lines churned"
+	}
+	b.WriteString(" " + filesTab + " " + dirsTab + "  " + utils.DetailsTitleStyle.Render(window) + "  " +
+		utils.DetailsLabelStyle.Render(fmt.Sprintf("%d changed · ranked by %s", len(entries), rank)) + "\n\n")
+
+	availableHeight := height - reservedHeight
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	rowWidth := width - 6
+	// Fixed columns, the bar and the date leave the rest to the path
+	pathWidth := rowWidth - 44 - utils.CompactDateWidth(dates)
+	if pathWidth < 16 {
+		pathWidth = 16
+	}
+
+	var list strings.Builder
+	list.WriteString(dimStyle.Render("  "+fmt.Sprintf("%-*s %7s %8s %8s  %-*s  %s", pathWidth, "PATH", "COMMITS", "ADDED", "DELETED", churnBarWidth, "", "LAST")) + "\n")
+	switch {
+	case errMsg != "":
+		list.WriteString("  " + utils.HelpStyle.Render("Error: "+errMsg))
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Reading history..."))
+	case len(entries) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("Nothing changed in this window."))
+	default:
+		score := func(e types.ChurnEntry) int {
+			if byLines {
+				return e.Additions + e.Deletions
+			}
+			return e.Commits
+		}
+		most := 0
+		for _, e := range entries {
+			most = max(most, score(e))
+		}
+
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		for i := start; i < end; i++ {
+			filled := 0
+			if most > 0 {
+				filled = max(1, score(entries[i])*churnBarWidth/most)
+			}
+			list.WriteString(renderChurnLine(pathWidth, entries[i], filled, i == selectedIdx, dates) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight+1).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ tab: files/directories │ enter: history │ s: rank by commits/lines │ e: edit window │ ESC: back │ q: quit")
+	if showInput {
+		help = utils.HelpStyle.Render("enter: apply │ ESC: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func renderChurnLine(pathWidth int, e types.ChurnEntry, filled int, selected bool, dates utils.DateFormat) string {
+	// Keep the end of long paths, where the file name is
+	p := e.Path
+	if r := []rune(p); len(r) > pathWidth {
+		p = "…" + string(r[len(r)-pathWidth+1:])
+	}
+	p += strings.Repeat(" ", max(0, pathWidth-lipgloss.Width(p)))
+
+	bar := churnBarStyle.Render(strings.Repeat("█", filled)) + strings.Repeat(" ", churnBarWidth-filled)
+
+	line := p + " " +
+		fmt.Sprintf("%7d", e.Commits) + " " +
+		contribAddStyle.Render(fmt.Sprintf("%8s", fmt.Sprintf("+%d", e.Additions))) + " " +
+		contribDelStyle.Render(fmt.Sprintf("%8s", fmt.Sprintf("-%d", e.Deletions))) + "  " +
+		bar + "  " + utils.DetailsLabelStyle.Render(utils.CompactDate(e.Last, dates))
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
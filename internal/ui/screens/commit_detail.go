@@ -25,10 +25,10 @@ func RenderFileList(width, height int, commit types.GraphCommit, files []types.F
 
 	if showFilter {
 		filterStyle := lipgloss.NewStyle().
-			Background(lipgloss.Color("#44475A")).
+			Background(utils.Theme.Surface).
 			Padding(0, 1).
 			Bold(true)
-		promptStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
 
 		filterBar := filterStyle.Render(promptStyle.Render("Filter: ") + filterInput)
 		b.WriteString(" " + filterBar + "\n\n")
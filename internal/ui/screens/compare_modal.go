@@ -1,59 +1,78 @@
 package screens
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
 )
 
-func RenderCompareModal(width, height int, localView, remoteView string, filterValue string, activePane int) string {
+func RenderCompareModal(width, height int, localView, remoteView, tagView string, filterValue string, activePane int, target, source string, editingSource bool, alertMessage string) string {
 	modalWidth := int(float64(width) * 0.8)
 	modalHeight := int(float64(height) * 0.7)
 
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#BD93F9")).
+		BorderForeground(utils.Theme.Accent).
 		Padding(0, 1)
 
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(utils.Theme.Text).
 		Padding(0, 1)
 
-	localHeader := headerStyle.Render("LOCAL")
-	if activePane == 0 {
-		localHeader = headerStyle.Copy().
-			Background(lipgloss.Color("#BD93F9")).
-			Foreground(lipgloss.Color("#282A36")).
-			Render(" LOCAL ")
+	activeHeaderStyle := headerStyle.Copy().
+		Background(utils.Theme.Accent).
+		Foreground(utils.Theme.Background)
+
+	paneHeader := func(label string, pane int) string {
+		if activePane == pane {
+			return activeHeaderStyle.Render(" " + label + " ")
+		}
+		return headerStyle.Render(label)
 	}
 
-	remoteHeader := headerStyle.Render("REMOTE")
-	if activePane == 1 {
-		remoteHeader = headerStyle.Copy().
-			Background(lipgloss.Color("#BD93F9")).
-			Foreground(lipgloss.Color("#282A36")).
-			Render(" REMOTE ")
+	// Target and source sides
+	sideStyle := lipgloss.NewStyle().Foreground(utils.Theme.Text).Padding(0, 1)
+	activeSideStyle := lipgloss.NewStyle().
+		Background(utils.Theme.Surface).
+		Foreground(utils.Theme.Added).
+		Bold(true).
+		Padding(0, 1)
+	sideLabel := func(label, value string, active bool) string {
+		if value == "" {
+			value = "…"
+		}
+		if active {
+			return activeSideStyle.Render(label + ": " + value)
+		}
+		return sideStyle.Render(label + ": " + value)
 	}
+	sides := sideLabel("Target", target, !editingSource) +
+		lipgloss.NewStyle().Foreground(utils.Theme.Muted).Render(" ← ") +
+		sideLabel("Source", source, editingSource)
 
 	// Filter bar
 	filterStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(utils.Theme.Muted).
 		Padding(0, 1)
-	filterBar := filterStyle.Render("Filter: ") + lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2")).Render(filterValue)
+	filterBar := filterStyle.Render("Filter or revision: ") + lipgloss.NewStyle().Foreground(utils.Theme.Text).Render(filterValue)
 
 	divider := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#44475A")).
+		Foreground(utils.Theme.Surface).
 		Render("│")
 
-	paneWidth := (modalWidth - 3) / 2
+	paneWidth := (modalWidth - 8) / 3
 
 	titles := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		lipgloss.NewStyle().Width(paneWidth).Align(lipgloss.Center).Render(localHeader),
-		" ",
-		lipgloss.NewStyle().Width(paneWidth).Align(lipgloss.Center).Render(remoteHeader),
+		lipgloss.NewStyle().Width(paneWidth).Align(lipgloss.Center).Render(paneHeader("LOCAL", 0)),
+		"   ",
+		lipgloss.NewStyle().Width(paneWidth).Align(lipgloss.Center).Render(paneHeader("REMOTE", 1)),
+		"   ",
+		lipgloss.NewStyle().Width(paneWidth).Align(lipgloss.Center).Render(paneHeader("TAGS", 2)),
 	)
 
 	body := lipgloss.JoinHorizontal(
@@ -61,15 +80,26 @@ func RenderCompareModal(width, height int, localView, remoteView string, filterV
 		localView,
 		" "+divider+" ",
 		remoteView,
+		" "+divider+" ",
+		tagView,
 	)
 
 	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(utils.Theme.Muted).
 		MarginTop(1).
-		Render("tab: switch pane • ↑/↓: navigate • enter: compare • esc: close")
+		Render("tab: pane • shift+tab: side • enter: pick • ctrl+g: graph commit • ctrl+x: swap • esc: close")
+	// A revision that did not resolve is reported in place of the help
+	if alertMessage != "" {
+		footer = lipgloss.NewStyle().
+			Foreground(utils.Theme.Removed).
+			Bold(true).
+			MarginTop(1).
+			Render(alertMessage)
+	}
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
+		sides+"\n",
 		titles,
 		"\n",
 		body,
@@ -82,24 +112,29 @@ func RenderCompareModal(width, height int, localView, remoteView string, filterV
 
 	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("#282A36")))
+		lipgloss.WithWhitespaceForeground(utils.Theme.Background))
 }
 
 func RenderBranchListContent(width int, branches []types.Branch, selectedIdx int, isActive bool) string {
 	var b strings.Builder
 
 	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#44475A")).
-		Foreground(lipgloss.Color("#50FA7B")).
+		Background(utils.Theme.Surface).
+		Foreground(utils.Theme.Added).
 		Bold(true).
 		Width(width)
 
 	normalStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(utils.Theme.Text).
 		Width(width)
 
 	dimmedStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(utils.Theme.Muted).
+		Width(width)
+
+	groupStyle := lipgloss.NewStyle().
+		Foreground(utils.Theme.Info).
+		Bold(true).
 		Width(width)
 
 	if len(branches) == 0 {
@@ -107,19 +142,82 @@ func RenderBranchListContent(width int, branches []types.Branch, selectedIdx int
 	}
 
 	for i, branch := range branches {
+		// Remote branches are listed under a header per remote
+		name := branch.Name
+		if branch.Remote != "" {
+			if i == 0 || branches[i-1].Remote != branch.Remote {
+				b.WriteString(groupStyle.Render("▾ "+branch.Remote) + "\n")
+			}
+			name = "  " + strings.TrimPrefix(name, branch.Remote+"/")
+		}
+
+		// Signed tags carry a plain badge, as the row style colors the line
+		if mark := signatureMark(branch.Signature); mark != "" {
+			name += " " + mark
+		}
+
+		// Local branches show their upstream on the right when it fits
+		if tracking := formatTracking(branch); tracking != "" {
+			gap := width - lipgloss.Width(name) - lipgloss.Width(tracking) - 3
+			if gap < 1 {
+				tracking = formatAheadBehind(branch)
+				gap = width - lipgloss.Width(name) - lipgloss.Width(tracking) - 3
+			}
+			if gap >= 1 {
+				name += strings.Repeat(" ", gap) + tracking
+			}
+		}
+
 		if i == selectedIdx && isActive {
-			b.WriteString(selectedStyle.Render("→ "+branch.Name) + "\n")
+			b.WriteString(selectedStyle.Render("→ "+name) + "\n")
 		} else if i == selectedIdx && !isActive {
 			b.WriteString(lipgloss.NewStyle().
-				Background(lipgloss.Color("#282A36")).
-				Foreground(lipgloss.Color("#BD93F9")).
-				Render("  "+branch.Name) + "\n")
+				Background(utils.Theme.Background).
+				Foreground(utils.Theme.Accent).
+				Render("  "+name) + "\n")
 		} else if !isActive {
-			b.WriteString(dimmedStyle.Render("  "+branch.Name) + "\n")
+			b.WriteString(dimmedStyle.Render("  "+name) + "\n")
 		} else {
-			b.WriteString(normalStyle.Render("  "+branch.Name) + "\n")
+			b.WriteString(normalStyle.Render("  "+name) + "\n")
 		}
 	}
 
 	return b.String()
 }
+
+// BranchListLine returns the line RenderBranchListContent draws branch idx
+// on, counting the remote group headers above it.
+func BranchListLine(branches []types.Branch, idx int) int {
+	line := idx
+	for i := 0; i <= idx && i < len(branches); i++ {
+		if branches[i].Remote != "" && (i == 0 || branches[i-1].Remote != branches[i].Remote) {
+			line++
+		}
+	}
+	return line
+}
+
+// formatTracking describes a branch's upstream, e.g. "↑2 ↓1 origin/main".
+func formatTracking(branch types.Branch) string {
+	if branch.Upstream == "" {
+		return ""
+	}
+	return formatAheadBehind(branch) + " " + branch.Upstream
+}
+
+func formatAheadBehind(branch types.Branch) string {
+	if branch.Upstream == "" {
+		return ""
+	}
+	if branch.Ahead == 0 && branch.Behind == 0 {
+		return "≡"
+	}
+	var parts []string
+	if branch.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", branch.Ahead))
+	}
+	if branch.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", branch.Behind))
+	}
+	return strings.Join(parts, " ")
+}
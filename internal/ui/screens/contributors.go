@@ -0,0 +1,129 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+const contribBarWidth = 12
+
+func RenderContributors(width, height int, rng string, contributors []types.Contributor, selectedIdx int, sortBy string, reverse bool, loading bool, errMsg string, showInput bool, inputValue string, dates utils.DateFormat) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Contributors ")
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Header (1) + spacing (1) + summary (1) + spacing (1) + border (2) + column titles (1) + help (1)
+	reservedHeight := 8
+	if showInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render("Branch or range (a..b): ")+inputValue+"█") + "\n\n")
+		reservedHeight += 2
+	}
+
+	commits := 0
+	for _, c := range contributors {
+		commits += c.Commits
+	}
+	order := "↓"
+	if reverse {
+		order = "↑"
+	}
+	summary := utils.DetailsTitleStyle.Render(rng) + "  " +
+		utils.DetailsLabelStyle.Render(fmt.Sprintf("%d authors · %d commits · sorted by %s %s", len(contributors), commits, sortBy, order))
+	b.WriteString(" " + summary + "\n\n")
+
+	availableHeight := height - reservedHeight
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	rowWidth := width - 6
+	// Fixed columns, the bar and both dates leave the rest to the name
+	nameWidth := rowWidth - 44 - 2*utils.CompactDateWidth(dates) - 3
+	if nameWidth < 16 {
+		nameWidth = 16
+	}
+
+	var list strings.Builder
+	list.WriteString(dimStyle.Render("  "+fmt.Sprintf("%-*s %7s %8s %8s  %-*s  %s", nameWidth, "AUTHOR", "COMMITS", "ADDED", "DELETED", contribBarWidth, "", "ACTIVE")) + "\n")
+	switch {
+	case errMsg != "":
+		list.WriteString("  " + utils.HelpStyle.Render("Error: "+errMsg))
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Counting commits..."))
+	case len(contributors) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("No commits in this range."))
+	default:
+		most := 0
+		for _, c := range contributors {
+			most = max(most, c.Commits)
+		}
+
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(contributors) {
+			end = len(contributors)
+		}
+
+		for i := start; i < end; i++ {
+			list.WriteString(renderContributorLine(nameWidth, contributors[i], most, i == selectedIdx, dates) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight+1).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ enter: filter graph by author │ H: heatmap │ s: sort │ S: reverse │ e: edit range │ ESC: back │ q: quit")
+	if showInput {
+		help = utils.HelpStyle.Render("enter: count │ ESC: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func renderContributorLine(nameWidth int, c types.Contributor, most int, selected bool, dates utils.DateFormat) string {
+	name := c.Name
+	if c.Email != "" {
+		name += " <" + c.Email + ">"
+	}
+	name = fmt.Sprintf("%-*s", nameWidth, utils.TruncateMessage(name, nameWidth))
+
+	filled := 1
+	if most > 0 {
+		filled = max(1, c.Commits*contribBarWidth/most)
+	}
+	bar := contribBarStyle.Render(strings.Repeat("█", filled)) + strings.Repeat(" ", contribBarWidth-filled)
+
+	active := utils.CompactDate(c.First, dates)
+	if last := utils.CompactDate(c.Last, dates); last != active {
+		active += " → " + last
+	}
+
+	line := name + " " +
+		fmt.Sprintf("%7d", c.Commits) + " " +
+		contribAddStyle.Render(fmt.Sprintf("%8s", fmt.Sprintf("+%d", c.Additions))) + " " +
+		contribDelStyle.Render(fmt.Sprintf("%8s", fmt.Sprintf("-%d", c.Deletions))) + "  " +
+		bar + "  " + utils.DetailsLabelStyle.Render(active)
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
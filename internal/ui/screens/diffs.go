@@ -19,7 +19,7 @@ func RenderDiffs(width int, commit types.GraphCommit, files []types.FileChange,
 	filterIndicator := ""
 	if showFilter {
 		filterIndicator = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF79C6")).
+			Foreground(utils.Theme.Secondary).
 			Bold(true).
 			Render("[FILTERED] ")
 	}
@@ -47,13 +47,14 @@ func RenderDiffs(width int, commit types.GraphCommit, files []types.FileChange,
 
 	commitInfo := utils.HashStyle.Render(commit.Hash) +
 		" " +
-		utils.DetailsTitleStyle.Render(commit.Message) +
-		" " +
-		utils.DetailsLabelStyle.Render("by "+commit.Author)
+		utils.DetailsTitleStyle.Render(commit.Message)
+	if commit.Author != "" {
+		commitInfo += " " + utils.DetailsLabelStyle.Render("by "+commit.Author)
+	}
 	b.WriteString(commitInfo + "\n")
 
 	divider := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#44475A")).
+		Foreground(utils.Theme.Surface).
 		Render(strings.Repeat("─", width))
 	b.WriteString(divider + "\n")
 
@@ -63,8 +64,8 @@ func RenderDiffs(width int, commit types.GraphCommit, files []types.FileChange,
 }
 
 func renderFileStats(file types.FileChange) string {
-	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
-	delStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Bold(true)
+	addStyle := lipgloss.NewStyle().Foreground(utils.Theme.Added).Bold(true)
+	delStyle := lipgloss.NewStyle().Foreground(utils.Theme.Removed).Bold(true)
 
 	stats := addStyle.Render(fmt.Sprintf("+%d", file.Additions)) +
 		" " +
@@ -3,68 +3,36 @@ package screens
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tomiwa-a/git-radar/internal/types"
-)
-
-var (
-	divBorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#444"))
-
-	divSectionTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#BD93F9"))
-
-	divDimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6272A4"))
-
-	divHashStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFB86C"))
-
-	divMessageStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#F8F8F2"))
-
-	divAuthorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8BE9FD"))
-
-	divAddStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#50FA7B"))
-
-	divDelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF5555"))
-
-	divWarningStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFB86C"))
-
-	divSelectedStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color("#44475A"))
-
-	divIncomingTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#8BE9FD"))
-
-	divOutgoingTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#50FA7B"))
+	"github.com/tomiwa-a/git-radar/utils"
 )
 
 type DivergenceData struct {
-	TargetBranch      string
-	SourceBranch      string
-	MergeBase         *types.GraphCommit
-	Incoming          []types.GraphCommit
-	Outgoing          []types.GraphCommit
-	IncomingIdx       int
-	OutgoingIdx       int
-	ActivePane        int
-	TotalFiles        int
-	TotalAdditions    int
-	TotalDeletions    int
-	ConflictFiles     []string
-	LoadingDivergence bool
-	AlertMessage      string
+	TargetBranch        string
+	SourceBranch        string
+	MergeBase           *types.GraphCommit
+	Incoming            []types.GraphCommit
+	Outgoing            []types.GraphCommit
+	IncomingIdx         int
+	OutgoingIdx         int
+	ActivePane          int
+	TotalFiles          int
+	TotalAdditions      int
+	TotalDeletions      int
+	ConflictFiles       []string
+	LoadingDivergence   bool
+	AlertMessage        string
+	PickedCommits       map[string]bool
+	CherryPicking       bool
+	CherryPickConflicts []string
+	CherryPickRemaining []string
+	CompareMode         types.CompareMode
+	ShowExportInput     bool
+	ExportInput         string
+	DateFormat          utils.DateFormat
 }
 
 func GetDummyDivergenceData() DivergenceData {
@@ -75,19 +43,19 @@ func GetDummyDivergenceData() DivergenceData {
 			Hash:    "abc1234",
 			Message: "initial project setup",
 			Author:  "Tomiwa",
-			Date:    "5 days ago",
+			Date:    time.Now().Add(-5 * 24 * time.Hour),
 		},
 		Incoming: []types.GraphCommit{
-			{Hash: "def5678", Message: "fix auth bug", Author: "Tomiwa", Date: "2 days ago", Files: []types.FileChange{{Status: "M", Path: "auth.go", Additions: 10, Deletions: 5}}},
-			{Hash: "jkl3456", Message: "Merge pull request #4", Author: "Tomiwa", Date: "3 days ago", IsMerge: true},
-			{Hash: "pqr1234", Message: "update dependencies", Author: "Tomiwa", Date: "4 days ago"},
+			{Hash: "def5678", Message: "fix auth bug", Author: "Tomiwa", Date: time.Now().Add(-2 * 24 * time.Hour), Files: []types.FileChange{{Status: "M", Path: "auth.go", Additions: 10, Deletions: 5}}},
+			{Hash: "jkl3456", Message: "Merge pull request #4", Author: "Tomiwa", Date: time.Now().Add(-3 * 24 * time.Hour), IsMerge: true},
+			{Hash: "pqr1234", Message: "update dependencies", Author: "Tomiwa", Date: time.Now().Add(-4 * 24 * time.Hour)},
 		},
 		Outgoing: []types.GraphCommit{
-			{Hash: "ghi9012", Message: "add logging to service", Author: "Tomiwa", Date: "2 hours ago", Files: []types.FileChange{{Status: "A", Path: "logger.go", Additions: 30, Deletions: 0}, {Status: "M", Path: "service.go", Additions: 15, Deletions: 2}}},
-			{Hash: "mno7890", Message: "refactor git service", Author: "Tomiwa", Date: "5 hours ago", Files: []types.FileChange{{Status: "M", Path: "service.go", Additions: 50, Deletions: 30}}},
-			{Hash: "stu5678", Message: "add unit tests", Author: "Tomiwa", Date: "1 day ago"},
-			{Hash: "vwx9012", Message: "cleanup unused code", Author: "Tomiwa", Date: "1 day ago"},
-			{Hash: "yza3456", Message: "update documentation", Author: "Tomiwa", Date: "2 days ago"},
+			{Hash: "ghi9012", Message: "add logging to service", Author: "Tomiwa", Date: time.Now().Add(-2 * time.Hour), Files: []types.FileChange{{Status: "A", Path: "logger.go", Additions: 30, Deletions: 0}, {Status: "M", Path: "service.go", Additions: 15, Deletions: 2}}},
+			{Hash: "mno7890", Message: "refactor git service", Author: "Tomiwa", Date: time.Now().Add(-5 * time.Hour), Files: []types.FileChange{{Status: "M", Path: "service.go", Additions: 50, Deletions: 30}}},
+			{Hash: "stu5678", Message: "add unit tests", Author: "Tomiwa", Date: time.Now().Add(-24 * time.Hour)},
+			{Hash: "vwx9012", Message: "cleanup unused code", Author: "Tomiwa", Date: time.Now().Add(-24 * time.Hour)},
+			{Hash: "yza3456", Message: "update documentation", Author: "Tomiwa", Date: time.Now().Add(-2 * 24 * time.Hour)},
 		},
 		IncomingIdx:    0,
 		OutgoingIdx:    0,
@@ -102,7 +70,7 @@ func GetDummyDivergenceData() DivergenceData {
 func RenderDivergence(width, height int, data DivergenceData) string {
 	var b strings.Builder
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF79C6"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(utils.Theme.Secondary)
 	title := headerStyle.Render(" Git-Radar ")
 	compareLabel := divDimStyle.Render("Compare: ") + divOutgoingTitleStyle.Render(data.TargetBranch) + divDimStyle.Render(" ← ") + divIncomingTitleStyle.Render(data.SourceBranch)
 	headerGap := width - lipgloss.Width(title) - lipgloss.Width(compareLabel) - 4
@@ -113,13 +81,13 @@ func RenderDivergence(width, height int, data DivergenceData) string {
 	if data.LoadingDivergence {
 		// Change title to alert message if one exists even during loading
 		if data.AlertMessage != "" {
-			alertStyle := lipgloss.NewStyle().Background(lipgloss.Color("#50FA7B")).Foreground(lipgloss.Color("#282A36")).Bold(true).Padding(0, 1)
+			alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
 			title = alertStyle.Render(" " + data.AlertMessage + " ")
 		}
 		b.WriteString(title + strings.Repeat(" ", headerGap) + compareLabel + "\n")
 		b.WriteString(divDimStyle.Render(strings.Repeat("─", width-2)) + "\n\n")
 
-		loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C")).Bold(true)
+		loadingStyle := lipgloss.NewStyle().Foreground(utils.Theme.Changed).Bold(true)
 		b.WriteString("\n\n" + loadingStyle.Render("  Loading divergence data...") + "\n\n")
 		help := divDimStyle.Render("y: copy hash │ esc: back │ q: quit")
 		b.WriteString(help)
@@ -128,16 +96,31 @@ func RenderDivergence(width, height int, data DivergenceData) string {
 
 	// Change title to alert message if one exists
 	if data.AlertMessage != "" {
-		alertStyle := lipgloss.NewStyle().Background(lipgloss.Color("#50FA7B")).Foreground(lipgloss.Color("#282A36")).Bold(true).Padding(0, 1)
+		alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
 		title = alertStyle.Render(" " + data.AlertMessage + " ")
 	}
 
 	b.WriteString(title + strings.Repeat(" ", headerGap) + compareLabel + "\n")
 	b.WriteString(divDimStyle.Render(strings.Repeat("─", width-2)) + "\n\n")
 
-	b.WriteString(renderDivergedAt(width, data.MergeBase))
+	if data.ShowExportInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render("Export report to (.md or .html): ")+data.ExportInput+"█") + "\n\n")
+	}
+
+	b.WriteString(renderDivergedAt(width, data.MergeBase, data.DateFormat))
 	b.WriteString("\n")
 
+	if data.CherryPicking {
+		b.WriteString(renderCherryPickConflicts(width, data.CherryPickConflicts, data.CherryPickRemaining))
+		b.WriteString("\n")
+	}
+
 	b.WriteString(renderCommitPanes(width, height, data))
 	b.WriteString("\n")
 
@@ -152,13 +135,19 @@ func RenderDivergence(width, height int, data DivergenceData) string {
 		b.WriteString("\n")
 	}
 
-	help := divDimStyle.Render("←/→: pane │ ↑/↓: nav │ enter: diff │ y: copy │ b: src │ c: target │ esc: back │ q: quit")
+	help := divDimStyle.Render("←/→: pane │ ↑/↓: nav │ enter: diff │ f: files │ space: mark │ p: cherry-pick │ y: copy │ b: src │ c: compare │ s: swap │ R: range-diff │ x: export │ esc: back │ q: quit")
+	if data.CherryPicking {
+		help = divDimStyle.Render("←/→: pane │ ↑/↓: nav │ enter: diff │ a: abort cherry-pick │ y: copy │ esc: back │ q: quit")
+	}
+	if data.ShowExportInput {
+		help = divDimStyle.Render("enter: export │ esc: cancel")
+	}
 	b.WriteString(help)
 
 	return b.String()
 }
 
-func renderDivergedAt(width int, mergeBase *types.GraphCommit) string {
+func renderDivergedAt(width int, mergeBase *types.GraphCommit, dates utils.DateFormat) string {
 	if mergeBase == nil {
 		return ""
 	}
@@ -167,7 +156,7 @@ func renderDivergedAt(width int, mergeBase *types.GraphCommit) string {
 	content.WriteString(" " + divSectionTitleStyle.Render("DIVERGED AT") + "\n")
 	content.WriteString(" ○ " + divHashStyle.Render(mergeBase.Hash) + "  ")
 	content.WriteString(divDimStyle.Render("\""+mergeBase.Message+"\"") + "  ")
-	content.WriteString(divAuthorStyle.Render(mergeBase.Author) + " · " + divDimStyle.Render(mergeBase.Date))
+	content.WriteString(divAuthorStyle.Render(mergeBase.Author) + " · " + divDimStyle.Render(utils.FormatDate(mergeBase.Date, dates)))
 
 	return divBorderStyle.Width(width-4).Render(content.String()) + "\n"
 }
@@ -190,13 +179,13 @@ func renderCommitPanes(width, height int, data DivergenceData) string {
 		availableHeight = 10 // Let's keep a reasonable cap to avoid overwhelming
 	}
 
-	leftContent := renderCommitPane("⬇ INCOMING", data.Incoming, data.IncomingIdx, data.ActivePane == 0, paneWidth, availableHeight, true)
-	rightContent := renderCommitPane("⬆ OUTGOING", data.Outgoing, data.OutgoingIdx, data.ActivePane == 1, paneWidth, availableHeight, false)
+	leftContent := renderCommitPane("⬇ INCOMING", data.Incoming, data.IncomingIdx, data.ActivePane == 0, paneWidth, availableHeight, true, data.PickedCommits)
+	rightContent := renderCommitPane("⬆ OUTGOING", data.Outgoing, data.OutgoingIdx, data.ActivePane == 1, paneWidth, availableHeight, false, nil)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftContent, "  ", rightContent)
 }
 
-func renderCommitPane(title string, commits []types.GraphCommit, selectedIdx int, isActive bool, width, height int, isIncoming bool) string {
+func renderCommitPane(title string, commits []types.GraphCommit, selectedIdx int, isActive bool, width, height int, isIncoming bool, picked map[string]bool) string {
 	var b strings.Builder
 
 	var titleStyle lipgloss.Style
@@ -206,7 +195,11 @@ func renderCommitPane(title string, commits []types.GraphCommit, selectedIdx int
 		titleStyle = divOutgoingTitleStyle
 	}
 
-	b.WriteString(" " + titleStyle.Render(fmt.Sprintf("%s (%d commits)", title, len(commits))) + "\n\n")
+	paneTitle := fmt.Sprintf("%s (%d commits)", title, len(commits))
+	if len(picked) > 0 {
+		paneTitle += fmt.Sprintf(" · %d marked", len(picked))
+	}
+	b.WriteString(" " + titleStyle.Render(paneTitle) + "\n\n")
 
 	maxVisible := height - 4 // Title (2) + Borders (2)
 	if maxVisible < 1 {
@@ -245,7 +238,12 @@ func renderCommitPane(title string, commits []types.GraphCommit, selectedIdx int
 			msg = msg[:maxMsgLen-3] + "..."
 		}
 
-		line := fmt.Sprintf(" %s %s  %s", dot, divHashStyle.Render(commit.Hash), msg)
+		mark := " "
+		if picked[commit.FullHash] {
+			mark = divAddStyle.Render("✓")
+		}
+
+		line := fmt.Sprintf("%s%s %s  %s", mark, dot, divHashStyle.Render(commit.Hash), msg)
 
 		if i == selectedIdx && isActive {
 			line = divSelectedStyle.Render(line)
@@ -271,12 +269,38 @@ func renderCommitPane(title string, commits []types.GraphCommit, selectedIdx int
 
 	paneStyle := divBorderStyle.Width(width)
 	if isActive {
-		paneStyle = paneStyle.BorderForeground(lipgloss.Color("#BD93F9"))
+		paneStyle = paneStyle.BorderForeground(utils.Theme.Accent)
 	}
 
 	return paneStyle.Render(b.String())
 }
 
+// renderCherryPickConflicts shows a cherry-pick stopped part way: the
+// conflicted files, if known, and the commits it did not get to.
+func renderCherryPickConflicts(width int, files, remaining []string) string {
+	var b strings.Builder
+	if len(files) > 0 {
+		b.WriteString(" " + divWarningStyle.Render(fmt.Sprintf("⚠ CHERRY-PICK CONFLICT (%d files)", len(files))) + "\n")
+	} else {
+		// Found on disk rather than hit here, so the files are not known
+		b.WriteString(" " + divWarningStyle.Render("⚠ CHERRY-PICK IN PROGRESS") + "\n")
+	}
+	for i, f := range files {
+		if i >= 3 {
+			b.WriteString("   " + divDimStyle.Render(fmt.Sprintf("• and %d more...", len(files)-i)) + "\n")
+			break
+		}
+		b.WriteString("   " + divDimStyle.Render("• "+f) + "\n")
+	}
+	if len(remaining) > 0 {
+		b.WriteString(" " + divDimStyle.Render(fmt.Sprintf("Not applied, pick again after committing (%d): %s", len(remaining), strings.Join(remaining, ", "))) + "\n")
+	}
+	b.WriteString(" " + divDimStyle.Render("Conflict markers are in the files, unstaged and without conflict stages.") + "\n")
+	b.WriteString(" " + divDimStyle.Render("Fix them, then git add and git commit, which reuses the commit's message and author; or press a to abort"))
+
+	return divBorderStyle.Width(width-4).BorderForeground(utils.Theme.Changed).Render(b.String()) + "\n"
+}
+
 func renderSelectedCommit(width int, data DivergenceData) string {
 	var commit types.GraphCommit
 	if data.ActivePane == 0 && len(data.Incoming) > 0 {
@@ -292,7 +316,7 @@ func renderSelectedCommit(width int, data DivergenceData) string {
 	var b strings.Builder
 	b.WriteString(" " + divSectionTitleStyle.Render("SELECTED COMMIT") + "\n\n")
 	b.WriteString(" " + divHashStyle.Render(commit.Hash) + "  " + divMessageStyle.Render("\""+commit.Message+"\"") + "\n")
-	b.WriteString(" " + divDimStyle.Render("Author:") + " " + divAuthorStyle.Render(commit.Author) + " · " + divDimStyle.Render(commit.Date) + "\n\n")
+	b.WriteString(" " + divDimStyle.Render("Author:") + " " + divAuthorStyle.Render(commit.Author) + " · " + divDimStyle.Render(utils.FormatDate(commit.Date, data.DateFormat)) + "\n\n")
 
 	if len(commit.Files) > 0 {
 		totalAdds, totalDels := 0, 0
@@ -326,11 +350,20 @@ func renderSelectedCommit(width int, data DivergenceData) string {
 
 func renderTotalChanges(width, height int, data DivergenceData) string {
 	var b strings.Builder
-	b.WriteString(" " + divSectionTitleStyle.Render(fmt.Sprintf("TOTAL CHANGES (%s → %s)", data.SourceBranch, data.TargetBranch)) + "\n\n")
+	modeLabel := "three-dot: since merge base"
+	if data.CompareMode == types.TwoDotCompare {
+		modeLabel = "two-dot: tip to tip"
+	}
+	b.WriteString(" " + divSectionTitleStyle.Render(fmt.Sprintf("TOTAL CHANGES (%s)", data.CompareMode.Range(data.TargetBranch, data.SourceBranch))))
+	b.WriteString("  " + divDimStyle.Render(modeLabel+" · m: toggle") + "\n\n")
 
 	b.WriteString(" " + divDimStyle.Render(fmt.Sprintf("%d files changed", data.TotalFiles)) + "   ")
 	b.WriteString(divAddStyle.Render(fmt.Sprintf("+%d", data.TotalAdditions)) + "  ")
-	b.WriteString(divDelStyle.Render(fmt.Sprintf("-%d", data.TotalDeletions)) + "\n\n")
+	b.WriteString(divDelStyle.Render(fmt.Sprintf("-%d", data.TotalDeletions)))
+	if data.TotalFiles > 0 {
+		b.WriteString("   " + divDimStyle.Render("f: browse files"))
+	}
+	b.WriteString("\n\n")
 
 	if len(data.ConflictFiles) > 0 {
 		b.WriteString(" " + divWarningStyle.Render(fmt.Sprintf("⚠ Potential conflicts: %d files modified in both branches", len(data.ConflictFiles))) + "\n")
@@ -0,0 +1,42 @@
+package screens
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+// RenderError fills the screen when the repository could not be opened at
+// all, so there is nothing else to show.
+func RenderError(width, height int, path string, err error) string {
+	var b strings.Builder
+
+	b.WriteString(utils.TitleStyle.Render(" Git Radar ") + "\n\n")
+
+	wrap := lipgloss.NewStyle().Width(utils.Max(width-8, 20))
+	var content strings.Builder
+	content.WriteString(errorTitleStyle.Render("✗ Could not load the repository") + "\n\n")
+	content.WriteString(dimStyle.Render("Path  ") + messageStyle.Render(path) + "\n\n")
+	content.WriteString(wrap.Render(messageStyle.Render(err.Error())) + "\n")
+
+	// Header (1) + spacing (1) + border (2) + help (1)
+	paneHeight := utils.Max(height-5, 3)
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(paneHeight).Render(content.String()) + "\n")
+	b.WriteString(utils.HelpStyle.Render("r: retry │ q: quit"))
+
+	return b.String()
+}
+
+// RenderErrorBanner is a one-line notice laid over the top of a screen after
+// a load failed, with the keys to retry or dismiss it.
+func RenderErrorBanner(width int, err error, canRetry bool) string {
+	keys := "esc: dismiss"
+	if canRetry {
+		keys = "r: retry │ " + keys
+	}
+	// Joined errors come one per line; the banner has only one
+	text := utils.TruncateMessage("✗ "+strings.ReplaceAll(err.Error(), "\n", "; "), utils.Max(width-lipgloss.Width(keys)-4, 10))
+	gap := utils.Max(width-lipgloss.Width(text)-lipgloss.Width(keys)-2, 1)
+	return errorBannerStyle.Render(" " + text + strings.Repeat(" ", gap) + keys + " ")
+}
@@ -2,6 +2,7 @@ package screens
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -9,29 +10,42 @@ import (
 	"github.com/tomiwa-a/git-radar/utils"
 )
 
-// Styles for the new graph UI
-var (
-	commitDotStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9"))
-	selectedDotStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
-	mergeDotStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Bold(true)
-	hashStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C")).Bold(true)
-	messageStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2"))
-	dimStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272A4"))
-	localBranchStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
-	remoteBranchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8BE9FD"))
-	mainBranchStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).Bold(true)
-	mergeTagStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Italic(true)
-	selectedBgStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#44475A"))
-	paneBorderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#44475A"))
-	sectionTitleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#BD93F9")).Bold(true)
-	branchCountStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C"))
-)
+// signatureMark is the plain badge for a signature status, empty when unsigned.
+func signatureMark(status types.SignatureStatus) string {
+	switch status {
+	case types.GoodSignature:
+		return "✓"
+	case types.BadSignature:
+		return "✗"
+	case types.UnknownKey:
+		return "?"
+	case types.Unverified:
+		return "•"
+	default:
+		return ""
+	}
+}
 
-func RenderGraph(width int, commits []types.GraphCommit, selectedIdx int, currentBranch string, alertMessage string) string {
-	return RenderGraphWithLegend(width, 24, commits, selectedIdx, currentBranch, false, "", false, alertMessage, false, "")
+func signatureBadge(status types.SignatureStatus) string {
+	switch status {
+	case types.GoodSignature:
+		return goodSigStyle.Render(signatureMark(status))
+	case types.BadSignature:
+		return badSigStyle.Render(signatureMark(status))
+	case types.UnknownKey:
+		return unknownSigStyle.Render(signatureMark(status))
+	case types.Unverified:
+		return dimStyle.Render(signatureMark(status))
+	default:
+		return ""
+	}
 }
 
-func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selectedIdx int, currentBranch string, showLegend bool, viewportContent string, loading bool, alertMessage string, showSearch bool, searchQuery string) string {
+func RenderGraph(width int, commits []types.GraphCommit, selectedIdx int, currentBranch types.Branch, alertMessage string, dates utils.DateFormat) string {
+	return RenderGraphWithLegend(width, 24, commits, selectedIdx, currentBranch, false, "", false, alertMessage, false, "", "", "", "", false, "", dates)
+}
+
+func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selectedIdx int, currentBranch types.Branch, showLegend bool, viewportContent string, loading bool, alertMessage string, showSearch bool, searchQuery string, status string, confirmOp string, filter string, showExport bool, exportPath string, dates utils.DateFormat) string {
 	if showLegend {
 		return utils.RenderLegend(
 			width, height,
@@ -44,7 +58,10 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 	// Header
 	title := utils.TitleStyle.Render(" Git-Radar ")
 	branchLabel := utils.DetailsLabelStyle.Render("on: ")
-	branchName := utils.BranchStyle.Render(" " + currentBranch + " ")
+	branchName := utils.BranchStyle.Render(" " + currentBranch.Name + " ")
+	if tracking := formatTracking(currentBranch); tracking != "" {
+		branchName += " " + branchCountStyle.Render(tracking)
+	}
 	help := utils.DetailsLabelStyle.Render("?: help")
 
 	headerGap := width - lipgloss.Width(title) - lipgloss.Width(branchLabel) - lipgloss.Width(branchName) - lipgloss.Width(help) - 4
@@ -54,7 +71,7 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 
 	// Change title to alert message if one exists
 	if alertMessage != "" {
-		alertStyle := lipgloss.NewStyle().Background(lipgloss.Color("#50FA7B")).Foreground(lipgloss.Color("#282A36")).Bold(true).Padding(0, 1)
+		alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
 		title = alertStyle.Render(" " + alertMessage + " ")
 	}
 
@@ -67,6 +84,9 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 
 	// Panel headers
 	leftHeader := sectionTitleStyle.Render("COMMITS")
+	if filter != "" {
+		leftHeader += " " + branchCountStyle.Render(utils.TruncateMessage(filter, utils.Max(leftPaneWidth-30, 10))) + " " + dimStyle.Render("(esc: clear)")
+	}
 	rightHeader := sectionTitleStyle.Render("DETAILS")
 
 	headerLine := leftHeader + strings.Repeat(" ", leftPaneWidth-lipgloss.Width(leftHeader)) +
@@ -77,11 +97,15 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 	b.WriteString(divider + "\n")
 
 	if showSearch {
-		searchStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F8F8F2")).Background(lipgloss.Color("#44475A")).Padding(0, 1)
+		searchStyle := lipgloss.NewStyle().Foreground(utils.Theme.Text).Background(utils.Theme.Surface).Padding(0, 1)
 		searchLabel := utils.DetailsLabelStyle.Render(" / ")
 		searchBox := searchStyle.Render(searchQuery + "█")
 		b.WriteString(searchLabel + searchBox + "\n")
 	}
+	if showExport {
+		inputStyle := lipgloss.NewStyle().Foreground(utils.Theme.Text).Background(utils.Theme.Surface).Padding(0, 1)
+		b.WriteString(utils.DetailsLabelStyle.Render(" Export graph to (.svg or .dot): ") + inputStyle.Render(exportPath+"█") + "\n")
+	}
 
 	// Get selected commit for details panel
 	var selectedCommit *types.GraphCommit
@@ -94,7 +118,7 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 	if loading {
 		leftContent = "Loading commits...\n"
 	}
-	rightContent := renderDetailsPanel(rightPaneWidth, selectedCommit, height-6)
+	rightContent := renderDetailsPanel(rightPaneWidth, selectedCommit, height-6, dates)
 
 	// Split viewport content into lines
 	leftLines := strings.Split(leftContent, "\n")
@@ -122,51 +146,185 @@ func RenderGraphWithLegend(width, height int, commits []types.GraphCommit, selec
 		b.WriteString(leftLine + paneBorderStyle.Render("│") + " " + rightLine + "\n")
 	}
 
-	// Footer
-	footer := utils.DetailsLabelStyle.Render("↑/↓: navigate │ enter: view files │ /: search │ y: copy hash │ b: branches │ c: compare │ ?: help │ q: quit")
+	// Footer doubles as the status area while a fetch, pull or push runs
+	footer := utils.DetailsLabelStyle.Render("↑/↓: navigate │ enter: view files │ /: search │ y: copy hash │ b: branches │ c: compare │ t: tree │ s: stash │ m: message │ r: reflog │ R: range-diff │ a: authors │ H: heatmap │ C: churn │ d: dates │ x: export │ f/p/P: fetch/pull/push │ ?: help │ q: quit")
+	if showExport {
+		footer = utils.DetailsLabelStyle.Render("enter: export │ esc: cancel")
+	}
+	if confirmOp != "" {
+		promptStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Changed).
+			Foreground(utils.Theme.Background).
+			Padding(0, 1).
+			Bold(true)
+		footer = promptStyle.Render(utils.TruncateMessage(remoteOpPrompt(confirmOp, currentBranch), width-4)) +
+			" " + utils.HelpStyle.Render("y: confirm │ any other key: cancel")
+	}
+	if status != "" {
+		footer = branchCountStyle.Render("⟳ " + utils.TruncateMessage(status, width-4))
+	}
 	b.WriteString(footer)
 
 	return b.String()
 }
 
-// RenderGraphContent renders compact commit lines for the viewport
-func RenderGraphContent(width int, commits []types.GraphCommit, selectedIdx int) string {
+// remoteOpPrompt asks whether to pull into or push the current branch,
+// naming its upstream when it has one.
+func remoteOpPrompt(op string, branch types.Branch) string {
+	switch {
+	case op == "pull" && branch.Upstream != "":
+		return "Pull " + branch.Upstream + " into " + branch.Name + "? (y/n)"
+	case op == "pull":
+		return "Pull into " + branch.Name + "? (y/n)"
+	case branch.Upstream != "":
+		return "Push " + branch.Name + " to " + branch.Upstream + "? (y/n)"
+	default:
+		return "Push " + branch.Name + " and track it on the remote? (y/n)"
+	}
+}
+
+// RenderGraphContent renders compact commit lines for the viewport, each
+// after its row of the commit graph
+func RenderGraphContent(width int, commits []types.GraphCommit, selectedIdx int, dates utils.DateFormat) string {
 	var b strings.Builder
 
-	msgWidth := width - 25
+	// Lay out a copy, leaving the caller's commits as they are
+	commits = slices.Clone(commits)
+	edges, lanes := utils.AssignLanes(commits)
+	// Past a third of the pane, further lanes are folded into the last one
+	lanes = max(min(lanes, width/6), 1)
+	graph := renderLanes(commits, edges, lanes, selectedIdx)
+
+	msgWidth := width - 19 - 2*lanes - utils.CompactDateWidth(dates)
 	if msgWidth < 20 {
 		msgWidth = 20
 	}
 
 	for i, commit := range commits {
 		isSelected := i == selectedIdx
-		line := renderCompactCommitLine(commit, isSelected, width, msgWidth)
+		line := renderCompactCommitLine(commit, graph[i], isSelected, width, msgWidth, dates)
 		b.WriteString(line + "\n")
 	}
 
 	return b.String()
 }
 
-func renderCompactCommitLine(commit types.GraphCommit, isSelected bool, width, msgWidth int) string {
-	// Dot
-	var dot string
-	if isSelected {
-		dot = selectedDotStyle.Render("●")
-	} else if commit.IsMerge {
-		dot = mergeDotStyle.Render("◆")
-	} else {
-		dot = commitDotStyle.Render("○")
+// graphCell is one column of a row of the graph, drawn in lane's colour.
+type graphCell struct {
+	char rune
+	lane int
+}
+
+// renderLanes draws the graph beside each commit, as git log --graph does
+// but one row per commit: its dot in its lane, lines running down to the
+// parents, and corners where a line forks off or joins another lane. Each
+// lane is two columns wide; those at or past lanes are drawn in the last.
+func renderLanes(commits []types.GraphCommit, edges []utils.GraphEdge, lanes, selectedIdx int) []string {
+	grid := make([][]graphCell, len(commits))
+	for i := range grid {
+		grid[i] = make([]graphCell, 2*lanes)
+	}
+	col := func(lane int) int { return 2 * min(lane, lanes-1) }
+
+	vertical := func(row, lane int) {
+		cell := &grid[row][col(lane)]
+		switch cell.char {
+		case 0:
+			*cell = graphCell{'│', lane}
+		case '─':
+			*cell = graphCell{'┼', lane}
+		}
+	}
+	// turn runs along row from the commit's lane to the edge's, where it
+	// bends up or down into corner
+	turn := func(row, commitLane, lane int, corner rune) {
+		for c := min(col(commitLane), col(lane)) + 1; c < max(col(commitLane), col(lane)); c++ {
+			cell := &grid[row][c]
+			switch cell.char {
+			case 0:
+				*cell = graphCell{'─', lane}
+			case '│':
+				*cell = graphCell{'┼', lane}
+			}
+		}
+		if cell := &grid[row][col(lane)]; cell.char == 0 || cell.char == '─' {
+			*cell = graphCell{corner, lane}
+		}
 	}
 
-	// Hash
+	for _, e := range edges {
+		// Fork off into the edge's lane
+		if from := commits[e.From].Lane; col(e.Lane) != col(from) {
+			corner := '╮'
+			if e.Lane < from {
+				corner = '╭'
+			}
+			turn(e.From, from, e.Lane, corner)
+		}
+
+		end := e.To
+		if end < 0 {
+			// The parent is not loaded, so the line runs off the bottom
+			end = len(commits)
+		}
+		for row := e.From + 1; row < end; row++ {
+			vertical(row, e.Lane)
+		}
+
+		// Join the parent's lane
+		if e.To >= 0 {
+			if to := commits[e.To].Lane; col(e.Lane) != col(to) {
+				corner := '╯'
+				if e.Lane < to {
+					corner = '╰'
+				}
+				turn(e.To, to, e.Lane, corner)
+			}
+		}
+	}
+
+	rows := make([]string, len(commits))
+	for i, c := range commits {
+		var b strings.Builder
+		for j, cell := range grid[i] {
+			switch {
+			case j == col(c.Lane):
+				b.WriteString(commitDot(c, i == selectedIdx))
+			case cell.char == 0:
+				b.WriteByte(' ')
+			default:
+				b.WriteString(laneStyles[cell.lane%len(laneStyles)].Render(string(cell.char)))
+			}
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
+
+func commitDot(commit types.GraphCommit, isSelected bool) string {
+	switch {
+	case isSelected:
+		return selectedDotStyle.Render("●")
+	case commit.IsMerge:
+		return mergeDotStyle.Render("◆")
+	default:
+		return commitDotStyle.Render("○")
+	}
+}
+
+func renderCompactCommitLine(commit types.GraphCommit, graph string, isSelected bool, width, msgWidth int, dates utils.DateFormat) string {
+	// Hash, followed by the signature badge of signed commits
 	hash := hashStyle.Render(commit.Hash)
+	if badge := signatureBadge(commit.Signature); badge != "" {
+		hash += " " + badge
+	}
 
 	// Message (truncated)
 	msg := utils.TruncateMessage(commit.Message, msgWidth)
 	msgStyled := messageStyle.Render(msg)
 
 	// Time (compact)
-	timeStr := utils.CompactTime(commit.Date)
+	timeStr := utils.CompactDate(commit.Date, dates)
 	timeStyled := dimStyle.Render(timeStr)
 
 	// Branch indicator
@@ -187,7 +345,7 @@ func renderCompactCommitLine(commit types.GraphCommit, isSelected bool, width, m
 	}
 
 	// Build line
-	line := " " + dot + " " + hash + " " + msgStyled
+	line := " " + graph + hash + " " + msgStyled
 
 	// Calculate padding
 	currentWidth := lipgloss.Width(line)
@@ -211,7 +369,46 @@ func renderCompactCommitLine(commit types.GraphCommit, isSelected bool, width, m
 	return line
 }
 
-func renderDetailsPanel(width int, commit *types.GraphCommit, height int) string {
+func formatIdentity(name, email string) string {
+	if email == "" {
+		return name
+	}
+	return name + " <" + email + ">"
+}
+
+// MessageBody returns the commit body without its trailer block.
+func MessageBody(commit types.GraphCommit) string {
+	body := commit.Body
+	if len(commit.Trailers) > 0 {
+		if i := strings.LastIndex(body, "\n\n"); i != -1 {
+			body = body[:i]
+		} else {
+			body = ""
+		}
+	}
+	return strings.TrimSpace(body)
+}
+
+// renderSignature describes a signature, e.g. "✓ Alice <alice@example.com>"
+// or "? unknown key 1A2B3C4D5E6F7A8B".
+func renderSignature(status types.SignatureStatus, signer string, width int) string {
+	var text string
+	switch status {
+	case types.GoodSignature:
+		text = signer
+	case types.BadSignature:
+		text = "invalid signature"
+	case types.UnknownKey:
+		text = "unknown key " + signer
+	case types.Unverified:
+		text = "signed, checked when opened"
+	default:
+		return dimStyle.Render("unsigned")
+	}
+	return signatureBadge(status) + " " + messageStyle.Render(utils.TruncateMessage(strings.TrimSpace(text), utils.Max(width-2, 10)))
+}
+
+func renderDetailsPanel(width int, commit *types.GraphCommit, height int, dates utils.DateFormat) string {
 	if commit == nil {
 		return dimStyle.Render("  No commit selected")
 	}
@@ -233,13 +430,40 @@ func renderDetailsPanel(width int, commit *types.GraphCommit, height int) string
 	}
 	b.WriteString("\n")
 
+	// A preview of the body; trailers are listed separately below
+	if body := MessageBody(*commit); body != "" {
+		const maxBodyLines = 6
+		var bodyLines []string
+		for i, para := range strings.Split(body, "\n\n") {
+			if i > 0 {
+				bodyLines = append(bodyLines, "")
+			}
+			bodyLines = append(bodyLines, utils.WrapText(para, width-2)...)
+		}
+		if len(bodyLines) > maxBodyLines {
+			bodyLines = append(bodyLines[:maxBodyLines-1], "… m: full message")
+		}
+		for _, line := range bodyLines {
+			b.WriteString(" " + dimStyle.Render(line) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Divider
 	b.WriteString(" " + paneBorderStyle.Render(strings.Repeat("─", width-2)) + "\n")
 	b.WriteString("\n")
 
-	// Info section with labels
-	b.WriteString(" " + dimStyle.Render("Author") + "    " + messageStyle.Render(commit.Author) + "\n")
-	b.WriteString(" " + dimStyle.Render("Date") + "      " + messageStyle.Render(commit.Date) + "\n")
+	// Info section with labels; the committer only shows when it differs
+	valueWidth := utils.Max(width-12, 10)
+	b.WriteString(" " + dimStyle.Render("Author") + "    " + messageStyle.Render(utils.TruncateMessage(formatIdentity(commit.Author, commit.AuthorEmail), valueWidth)) + "\n")
+	b.WriteString(" " + dimStyle.Render("Date") + "      " + messageStyle.Render(utils.FormatDate(commit.Date, dates)) + "\n")
+	if commit.Committer != "" && (commit.Committer != commit.Author || commit.CommitterEmail != commit.AuthorEmail) {
+		b.WriteString(" " + dimStyle.Render("Committer") + " " + messageStyle.Render(utils.TruncateMessage(formatIdentity(commit.Committer, commit.CommitterEmail), valueWidth)) + "\n")
+	}
+	if committed := utils.FormatDate(commit.CommitDate, dates); committed != "" && committed != utils.FormatDate(commit.Date, dates) {
+		b.WriteString(" " + dimStyle.Render("Committed") + " " + messageStyle.Render(committed) + "\n")
+	}
+	b.WriteString(" " + dimStyle.Render("Signed") + "    " + renderSignature(commit.Signature, commit.Signer, width-12) + "\n")
 
 	// Branch (show first branch ref or current viewing branch)
 	if len(commit.Branches) > 0 {
@@ -247,6 +471,15 @@ func renderDetailsPanel(width int, commit *types.GraphCommit, height int) string
 	}
 	b.WriteString("\n")
 
+	// Trailers such as Co-authored-by and Signed-off-by
+	if len(commit.Trailers) > 0 {
+		for _, t := range commit.Trailers {
+			key := trailerKeyStyle.Render(t.Key + ":")
+			b.WriteString(" " + key + " " + messageStyle.Render(utils.TruncateMessage(t.Value, utils.Max(width-lipgloss.Width(key)-4, 10))) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Files summary
 	if len(commit.Files) > 0 {
 		totalAdds := 0
@@ -257,8 +490,8 @@ func renderDetailsPanel(width int, commit *types.GraphCommit, height int) string
 		}
 		filesLabel := fmt.Sprintf("%d files changed", len(commit.Files))
 		statsLabel := fmt.Sprintf("+%d -%d", totalAdds, totalDels)
-		addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B"))
-		delStyleLocal := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+		addStyle := lipgloss.NewStyle().Foreground(utils.Theme.Added)
+		delStyleLocal := lipgloss.NewStyle().Foreground(utils.Theme.Removed)
 		b.WriteString(" " + dimStyle.Render(filesLabel) + "  " + addStyle.Render(fmt.Sprintf("+%d", totalAdds)) + " " + delStyleLocal.Render(fmt.Sprintf("-%d", totalDels)) + "\n")
 		_ = statsLabel // suppress unused warning
 		b.WriteString("\n")
@@ -0,0 +1,184 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+func RenderHeatmap(width, height int, branch, filter string, days map[string]int, selected time.Time, loading bool, errMsg string, inputField, inputValue string) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Activity ")
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	if inputField != "" {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		prompt := "Author (empty for all): "
+		if inputField == "path" {
+			prompt = "Path (empty for all): "
+		}
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render(prompt)+inputValue+"█") + "\n\n")
+	}
+
+	summary := utils.DetailsTitleStyle.Render(branch)
+	if filter != "" {
+		summary += "  " + branchCountStyle.Render(filter)
+	}
+	b.WriteString(" " + summary + "\n\n")
+
+	// Header (1) + spacing (1) + summary (1) + spacing (1) + border (2) + help (1)
+	paneHeight := height - 7
+	if inputField != "" {
+		paneHeight -= 2
+	}
+	if paneHeight < 3 {
+		paneHeight = 3
+	}
+
+	var content strings.Builder
+	switch {
+	case errMsg != "":
+		content.WriteString("  " + utils.HelpStyle.Render("Error: "+errMsg))
+	case loading:
+		content.WriteString("  " + utils.HelpStyle.Render("Counting commits..."))
+	default:
+		content.WriteString(renderCalendar(width-6, days, selected))
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(paneHeight).Render(content.String()) + "\n")
+
+	help := utils.HelpStyle.Render("←/→: week │ ↑/↓: day │ n/N: next/previous active day │ enter: show in graph │ a: author │ p: path │ x: clear filters │ ESC: back │ q: quit")
+	if inputField != "" {
+		help = utils.HelpStyle.Render("enter: apply │ ESC: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+// renderCalendar draws one column per week, Sunday at the top, ending with
+// the week of the latest commit or of the selected day when that is outside.
+func renderCalendar(width int, days map[string]int, selected time.Time) string {
+	weeks := (width - 6) / 2
+	if weeks > 53 {
+		weeks = 53
+	}
+	if weeks < 4 {
+		weeks = 4
+	}
+
+	latest := selected
+	for day, n := range days {
+		if n == 0 {
+			continue
+		}
+		if t, err := time.ParseInLocation(types.DayLayout, day, time.Local); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	end := endOfWeek(latest)
+	start := end.AddDate(0, 0, -7*weeks+1)
+	if selected.Before(start) {
+		end = endOfWeek(selected)
+		start = end.AddDate(0, 0, -7*weeks+1)
+	}
+
+	// Shades are relative to the busiest day in view
+	busiest, total, active := 0, 0, 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		n := days[d.Format(types.DayLayout)]
+		busiest = max(busiest, n)
+		total += n
+		if n > 0 {
+			active++
+		}
+	}
+
+	var b strings.Builder
+
+	// Month labels above the first week of each month
+	months := []rune(strings.Repeat(" ", weeks*2+1))
+	for w := 0; w < weeks; w++ {
+		first := start.AddDate(0, 0, 7*w)
+		if w == 0 || first.Month() != first.AddDate(0, 0, -7).Month() {
+			label := first.Format("Jan")
+			if w == 0 && first.AddDate(0, 0, 14).Month() != first.Month() {
+				continue
+			}
+			if w*2+len(label) <= len(months) {
+				copy(months[w*2:], []rune(label))
+			}
+		}
+	}
+	b.WriteString("      " + dimStyle.Render(strings.TrimRight(string(months), " ")) + "\n")
+
+	now := time.Now()
+	for weekday := 0; weekday < 7; weekday++ {
+		label := "    "
+		switch time.Weekday(weekday) {
+		case time.Monday, time.Wednesday, time.Friday:
+			label = time.Weekday(weekday).String()[:3] + " "
+		}
+		b.WriteString("  " + dimStyle.Render(label))
+
+		for w := 0; w < weeks; w++ {
+			day := start.AddDate(0, 0, 7*w+weekday)
+			switch {
+			case day.Equal(selected):
+				b.WriteString(heatSelectedStyle.Render("■") + " ")
+			case day.After(now):
+				b.WriteString("  ")
+			default:
+				b.WriteString(heatLevelStyles[heatLevel(days[day.Format(types.DayLayout)], busiest)].Render("■") + " ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	// Legend
+	legend := dimStyle.Render("Less ")
+	for _, style := range heatLevelStyles {
+		legend += style.Render("■") + " "
+	}
+	legend += dimStyle.Render("More")
+	b.WriteString("\n      " + legend + "\n\n")
+
+	count := days[selected.Format(types.DayLayout)]
+	plural := "s"
+	if count == 1 {
+		plural = ""
+	}
+	b.WriteString("  " + utils.DetailsTitleStyle.Render(selected.Format("Monday, 2 January 2006")) + "  " +
+		messageStyle.Render(fmt.Sprintf("%d commit%s", count, plural)) + "\n")
+	b.WriteString("  " + dimStyle.Render(fmt.Sprintf("%d commits on %d days from %s to %s", total, active, start.Format("2 Jan 2006"), end.Format("2 Jan 2006"))) + "\n")
+
+	return b.String()
+}
+
+// heatLevel buckets a day's commits into one of the shades.
+func heatLevel(count, busiest int) int {
+	if count == 0 || busiest == 0 {
+		return 0
+	}
+	level := (count*4 + busiest - 1) / busiest
+	return min(max(level, 1), 4)
+}
+
+// endOfWeek is the Saturday of t's week.
+func endOfWeek(t time.Time) time.Time {
+	return t.AddDate(0, 0, 6-int(t.Weekday()))
+}
@@ -0,0 +1,75 @@
+package screens
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+// RenderCommitMessage lays out the full metadata and message of a commit,
+// like git show --format=fuller, for the message viewport.
+func RenderCommitMessage(width int, commit types.GraphCommit, dates utils.DateFormat) string {
+	var b strings.Builder
+
+	field := func(label, value string) {
+		b.WriteString(dimStyle.Render(label+strings.Repeat(" ", 12-len(label))) + messageStyle.Render(value) + "\n")
+	}
+
+	b.WriteString(dimStyle.Render("commit      ") + hashStyle.Render(commit.FullHash) + "\n")
+	if len(commit.Parents) > 0 {
+		var parents []string
+		for _, p := range commit.Parents {
+			parents = append(parents, p[:7])
+		}
+		field("Parents", strings.Join(parents, " "))
+	}
+	field("Author", formatIdentity(commit.Author, commit.AuthorEmail))
+	field("AuthorDate", utils.FormatDate(commit.Date, dates))
+	if commit.Committer != "" {
+		field("Commit", formatIdentity(commit.Committer, commit.CommitterEmail))
+		field("CommitDate", utils.FormatDate(commit.CommitDate, dates))
+	}
+	b.WriteString(dimStyle.Render("Signature   ") + renderSignature(commit.Signature, commit.Signer, width-12) + "\n")
+	if len(commit.Branches) > 0 {
+		field("Branches", strings.Join(commit.Branches, ", "))
+	}
+	b.WriteString("\n")
+
+	wrap := lipgloss.NewStyle().Width(utils.Max(width-4, 20))
+	b.WriteString("    " + utils.DetailsTitleStyle.Render(commit.Message) + "\n")
+	if body := MessageBody(commit); body != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(wrap.Render(body), "\n") {
+			b.WriteString("    " + messageStyle.Render(line) + "\n")
+		}
+	}
+
+	if len(commit.Trailers) > 0 {
+		b.WriteString("\n")
+		for _, t := range commit.Trailers {
+			b.WriteString("    " + trailerKeyStyle.Render(t.Key+":") + " " + messageStyle.Render(t.Value) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+func RenderMessage(width int, commit types.GraphCommit, viewportContent string) string {
+	var b strings.Builder
+
+	backHint := utils.DetailsLabelStyle.Render("ESC: back  ↑↓: scroll")
+	commitInfo := utils.HashStyle.Render("← " + commit.Hash + " ")
+	commitMsg := utils.DetailsTitleStyle.Render(utils.TruncateMessage(commit.Message, utils.Max(width-lipgloss.Width(backHint)-lipgloss.Width(commitInfo)-2, 10)))
+
+	headerGap := width - lipgloss.Width(commitInfo) - lipgloss.Width(commitMsg) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(commitInfo + commitMsg + strings.Repeat(" ", headerGap) + backHint + "\n")
+	b.WriteString(paneBorderStyle.Render(strings.Repeat("─", width)) + "\n")
+	b.WriteString(viewportContent)
+
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+func RenderRangeDiff(width, height int, ranges string, pairs []types.RangeDiffPair, selectedIdx int, loading bool, errMsg string, alertMessage string, showInput bool, inputValue string) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Range-diff ")
+	if alertMessage != "" {
+		alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
+		title = alertStyle.Render(" " + alertMessage + " ")
+	}
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Header (1) + spacing (1) + summary (1) + spacing (1) + border (2) + help (1)
+	reservedHeight := 7
+	if showInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render("Ranges (old new, or a...b): ")+inputValue+"█") + "\n\n")
+		reservedHeight += 2
+	}
+
+	var equal, modified, added, dropped int
+	for _, p := range pairs {
+		switch p.Status {
+		case "equal":
+			equal++
+		case "modified":
+			modified++
+		case "added":
+			added++
+		case "dropped":
+			dropped++
+		}
+	}
+	summary := utils.DetailsTitleStyle.Render(ranges) + "  " +
+		rangeEqualStyle.Render(fmt.Sprintf("=%d", equal)) + " " +
+		rangeModifiedStyle.Render(fmt.Sprintf("!%d", modified)) + " " +
+		rangeAddedStyle.Render(fmt.Sprintf(">%d", added)) + " " +
+		rangeDroppedStyle.Render(fmt.Sprintf("<%d", dropped))
+	b.WriteString(" " + summary + "\n\n")
+
+	availableHeight := height - reservedHeight
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	var list strings.Builder
+	switch {
+	case errMsg != "":
+		list.WriteString("  " + utils.HelpStyle.Render("Error: "+errMsg))
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Comparing ranges..."))
+	case ranges == "":
+		list.WriteString("  " + utils.HelpStyle.Render("Enter two ranges to compare, e.g. main..old-topic main..topic"))
+	case len(pairs) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("Both ranges are empty."))
+	default:
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+
+		for i := start; i < end; i++ {
+			list.WriteString(renderRangeDiffLine(width-6, pairs[i], i == selectedIdx) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ enter: diff of patches │ e: edit ranges │ y: copy hash │ ESC: back │ q: quit")
+	if showInput {
+		help = utils.HelpStyle.Render("enter: compare │ ESC: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+// renderRangeDiffLine mirrors git range-diff's "1: abc1234 = 1: def5678 subject".
+func renderRangeDiffLine(width int, pair types.RangeDiffPair, selected bool) string {
+	side := func(index int, hash string) string {
+		if index == 0 {
+			return rangeEqualStyle.Render("-: -------")
+		}
+		return rangeEqualStyle.Render(fmt.Sprintf("%d:", index)) + " " + utils.HashStyle.Render(hash)
+	}
+
+	var marker, message string
+	switch pair.Status {
+	case "equal":
+		marker, message = rangeEqualStyle.Render("="), pair.New.Message
+	case "modified":
+		marker, message = rangeModifiedStyle.Render("!"), pair.New.Message
+	case "added":
+		marker, message = rangeAddedStyle.Render(">"), pair.New.Message
+	case "dropped":
+		marker, message = rangeDroppedStyle.Render("<"), pair.Old.Message
+	}
+
+	prefix := side(pair.OldIndex, pair.Old.Hash) + " " + marker + " " + side(pair.NewIndex, pair.New.Hash) + " "
+	msgWidth := width - lipgloss.Width(prefix) - 2
+	if msgWidth < 10 {
+		msgWidth = 10
+	}
+	line := prefix + utils.TruncateMessage(message, msgWidth)
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
+
+func RenderRangeDiffPatch(width int, pair types.RangeDiffPair, viewportContent string) string {
+	var b strings.Builder
+
+	backHint := utils.DetailsLabelStyle.Render("ESC: back  ↑↓: scroll")
+	header := renderRangeDiffLine(width-lipgloss.Width(backHint), pair, false)
+
+	headerGap := width - lipgloss.Width(header) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(header + strings.Repeat(" ", headerGap) + backHint + "\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(utils.Theme.Surface).Render(strings.Repeat("─", width)) + "\n")
+	b.WriteString(viewportContent)
+
+	return b.String()
+}
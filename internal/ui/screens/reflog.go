@@ -0,0 +1,134 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+func RenderReflog(width, height int, refs []string, refIdx int, entries []types.ReflogEntry, selectedIdx int, loading bool, alertMessage string, showInput bool, inputValue string, dates utils.DateFormat) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Reflog ")
+	if alertMessage != "" {
+		alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
+		title = alertStyle.Render(" " + alertMessage + " ")
+	}
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Ref tabs
+	activeTab := lipgloss.NewStyle().
+		Background(utils.Theme.Accent).
+		Foreground(utils.Theme.Background).
+		Bold(true).
+		Padding(0, 1)
+	inactiveTab := lipgloss.NewStyle().
+		Foreground(utils.Theme.Muted).
+		Padding(0, 1)
+	var tabs []string
+	for i, ref := range refs {
+		if i == refIdx {
+			tabs = append(tabs, activeTab.Render(ref))
+		} else {
+			tabs = append(tabs, inactiveTab.Render(ref))
+		}
+	}
+	b.WriteString(" " + strings.Join(tabs, " ") + "\n\n")
+
+	// Header (1) + spacing (1) + tabs (1) + spacing (1) + border (2) + help (1)
+	reservedHeight := 7
+	if showInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		target := ""
+		if selectedIdx < len(entries) {
+			target = " at " + entries[selectedIdx].NewHash
+		}
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render("New branch"+target+": ")+inputValue+"█") + "\n\n")
+		reservedHeight += 2
+	}
+
+	availableHeight := height - reservedHeight
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	var list strings.Builder
+	switch {
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Loading reflog..."))
+	case len(entries) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("No reflog for this ref."))
+	default:
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		for i := start; i < end; i++ {
+			list.WriteString(renderReflogLine(width-6, entries[i], i == selectedIdx, dates) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ ←/→: ref │ enter: view files │ n: branch here │ y: copy hash │ R: range-diff since here │ ESC: back │ q: quit")
+	if showInput {
+		help = utils.HelpStyle.Render("enter: create branch │ ESC: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func renderReflogLine(width int, entry types.ReflogEntry, selected bool, dates utils.DateFormat) string {
+	selector := lipgloss.NewStyle().Foreground(utils.Theme.Muted).Render(fmt.Sprintf("%-12s", entry.Selector))
+
+	oldHash := entry.OldHash
+	if oldHash == "" {
+		oldHash = "0000000"
+	}
+	hashes := utils.DetailsLabelStyle.Render(oldHash+" → ") + utils.HashStyle.Render(entry.NewHash)
+
+	// "commit (amend)" is colored like "commit"
+	verb, _, _ := strings.Cut(entry.Action, " ")
+	color, ok := reflogActionColors[verb]
+	if !ok {
+		color = utils.Theme.Text
+	}
+	action := lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%-16s", utils.TruncateMessage(entry.Action, 16)))
+	date := utils.DetailsLabelStyle.Render(utils.FormatDate(entry.Date, dates))
+
+	prefix := selector + " " + hashes + " " + action + " "
+	msgWidth := width - lipgloss.Width(prefix) - lipgloss.Width(date) - 4
+	if msgWidth < 10 {
+		msgWidth = 10
+	}
+	line := prefix + utils.TruncateMessage(entry.Message, msgWidth)
+
+	gap := width - lipgloss.Width(line) - lipgloss.Width(date) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	line = line + strings.Repeat(" ", gap) + date
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
@@ -0,0 +1,118 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+func RenderStash(width, height int, stashes []types.Stash, selectedIdx int, loading bool, alertMessage string, showInput, untracked bool, inputValue string, confirmDrop bool, dates utils.DateFormat) string {
+	var b strings.Builder
+
+	title := utils.TitleStyle.Render(" Stash ")
+	if alertMessage != "" {
+		alertStyle := lipgloss.NewStyle().Background(utils.Theme.Added).Foreground(utils.Theme.Background).Bold(true).Padding(0, 1)
+		title = alertStyle.Render(" " + alertMessage + " ")
+	}
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	headerGap := width - lipgloss.Width(title) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(title + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Header (1) + spacing (1) + title (1) + spacing (1) + border (2) + help (1)
+	reservedHeight := 7
+	if showInput {
+		inputStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Surface).
+			Padding(0, 1).
+			Bold(true)
+		promptStyle := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Bold(true)
+
+		prompt := "Stash message: "
+		if untracked {
+			prompt = "Stash message (with untracked files): "
+		}
+		b.WriteString(" " + inputStyle.Render(promptStyle.Render(prompt)+inputValue+"█") + "\n\n")
+		reservedHeight += 2
+	}
+	if confirmDrop && selectedIdx < len(stashes) {
+		promptStyle := lipgloss.NewStyle().
+			Background(utils.Theme.Removed).
+			Foreground(utils.Theme.Background).
+			Padding(0, 1).
+			Bold(true)
+
+		b.WriteString(" " + promptStyle.Render("Drop "+stashes[selectedIdx].Name+"? It can't be undone. (y/n)") + "\n\n")
+		reservedHeight += 2
+	}
+
+	b.WriteString(utils.DetailsTitleStyle.Render(fmt.Sprintf("STASHES (%d)", len(stashes))) + "\n\n")
+
+	availableHeight := height - reservedHeight
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	var list strings.Builder
+	switch {
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Loading stashes..."))
+	case len(stashes) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("No stashes. Press n to stash your local changes."))
+	default:
+		// Keep the selection in view
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(stashes) {
+			end = len(stashes)
+		}
+
+		for i := start; i < end; i++ {
+			list.WriteString(renderStashLine(width-6, stashes[i], i == selectedIdx, dates) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ enter: view files │ a: apply │ p: pop │ d: drop │ n: new stash │ u: with untracked │ ESC: back │ q: quit")
+	if showInput {
+		help = utils.HelpStyle.Render("enter: create stash │ ESC: cancel")
+	}
+	if confirmDrop {
+		help = utils.HelpStyle.Render("y: drop │ any other key: cancel")
+	}
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func renderStashLine(width int, stash types.Stash, selected bool, dates utils.DateFormat) string {
+	name := lipgloss.NewStyle().Foreground(utils.Theme.Accent).Render(fmt.Sprintf("%-10s", stash.Name))
+	hash := utils.HashStyle.Render(stash.Hash)
+	date := utils.DetailsLabelStyle.Render(utils.FormatDate(stash.Date, dates))
+
+	msgWidth := width - lipgloss.Width(name) - lipgloss.Width(hash) - lipgloss.Width(date) - 6
+	if msgWidth < 10 {
+		msgWidth = 10
+	}
+	msg := utils.TruncateMessage(stash.Message, msgWidth)
+
+	line := name + " " + hash + " " + msg
+	gap := width - lipgloss.Width(line) - lipgloss.Width(date) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	line = line + strings.Repeat(" ", gap) + date
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
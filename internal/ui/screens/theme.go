@@ -0,0 +1,153 @@
+package screens
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+// Styles shared by the renderers of each screen, built from the theme by
+// SetTheme.
+var (
+	// Graph and commit details
+	commitDotStyle    lipgloss.Style
+	selectedDotStyle  lipgloss.Style
+	mergeDotStyle     lipgloss.Style
+	hashStyle         lipgloss.Style
+	messageStyle      lipgloss.Style
+	dimStyle          lipgloss.Style
+	localBranchStyle  lipgloss.Style
+	remoteBranchStyle lipgloss.Style
+	mainBranchStyle   lipgloss.Style
+	mergeTagStyle     lipgloss.Style
+	selectedBgStyle   lipgloss.Style
+	paneBorderStyle   lipgloss.Style
+	sectionTitleStyle lipgloss.Style
+	branchCountStyle  lipgloss.Style
+	laneStyles        []lipgloss.Style
+	goodSigStyle      lipgloss.Style
+	badSigStyle       lipgloss.Style
+	unknownSigStyle   lipgloss.Style
+	trailerKeyStyle   lipgloss.Style
+
+	// Divergence
+	divBorderStyle        lipgloss.Style
+	divSectionTitleStyle  lipgloss.Style
+	divDimStyle           lipgloss.Style
+	divHashStyle          lipgloss.Style
+	divMessageStyle       lipgloss.Style
+	divAuthorStyle        lipgloss.Style
+	divAddStyle           lipgloss.Style
+	divDelStyle           lipgloss.Style
+	divWarningStyle       lipgloss.Style
+	divSelectedStyle      lipgloss.Style
+	divIncomingTitleStyle lipgloss.Style
+	divOutgoingTitleStyle lipgloss.Style
+
+	// Tree
+	treeDirStyle       lipgloss.Style
+	treeSubmoduleStyle lipgloss.Style
+
+	// Reflog
+	reflogActionColors map[string]lipgloss.Color
+
+	// Range-diff
+	rangeEqualStyle    lipgloss.Style
+	rangeModifiedStyle lipgloss.Style
+	rangeAddedStyle    lipgloss.Style
+	rangeDroppedStyle  lipgloss.Style
+
+	// Contributors, heatmap and churn
+	contribBarStyle   lipgloss.Style
+	contribAddStyle   lipgloss.Style
+	contribDelStyle   lipgloss.Style
+	heatLevelStyles   []lipgloss.Style
+	heatSelectedStyle lipgloss.Style
+	churnBarStyle     lipgloss.Style
+
+	// Errors
+	errorBannerStyle lipgloss.Style
+	errorTitleStyle  lipgloss.Style
+)
+
+func init() {
+	SetTheme(theme.Dark)
+}
+
+// SetTheme draws every screen in t from now on, along with the shared
+// styles in utils.
+func SetTheme(t theme.Theme) {
+	utils.SetTheme(t)
+
+	commitDotStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	selectedDotStyle = lipgloss.NewStyle().Foreground(t.Added).Bold(true)
+	mergeDotStyle = lipgloss.NewStyle().Foreground(t.Secondary).Bold(true)
+	hashStyle = lipgloss.NewStyle().Foreground(t.Changed).Bold(true)
+	messageStyle = lipgloss.NewStyle().Foreground(t.Text)
+	dimStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	localBranchStyle = lipgloss.NewStyle().Foreground(t.Added)
+	remoteBranchStyle = lipgloss.NewStyle().Foreground(t.Info)
+	mainBranchStyle = lipgloss.NewStyle().Foreground(t.Added).Bold(true)
+	mergeTagStyle = lipgloss.NewStyle().Foreground(t.Secondary).Italic(true)
+	selectedBgStyle = lipgloss.NewStyle().Background(t.Surface)
+	paneBorderStyle = lipgloss.NewStyle().Foreground(t.Surface)
+	sectionTitleStyle = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	branchCountStyle = lipgloss.NewStyle().Foreground(t.Changed)
+	laneStyles = nil
+	for _, c := range t.Lanes() {
+		laneStyles = append(laneStyles, lipgloss.NewStyle().Foreground(c))
+	}
+	goodSigStyle = lipgloss.NewStyle().Foreground(t.Added).Bold(true)
+	badSigStyle = lipgloss.NewStyle().Foreground(t.Removed).Bold(true)
+	unknownSigStyle = lipgloss.NewStyle().Foreground(t.Changed).Bold(true)
+	trailerKeyStyle = lipgloss.NewStyle().Foreground(t.Info)
+
+	divBorderStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Surface)
+	divSectionTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	divDimStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	divHashStyle = lipgloss.NewStyle().Foreground(t.Changed)
+	divMessageStyle = lipgloss.NewStyle().Foreground(t.Text)
+	divAuthorStyle = lipgloss.NewStyle().Foreground(t.Info)
+	divAddStyle = lipgloss.NewStyle().Foreground(t.Added)
+	divDelStyle = lipgloss.NewStyle().Foreground(t.Removed)
+	divWarningStyle = lipgloss.NewStyle().Foreground(t.Changed)
+	divSelectedStyle = lipgloss.NewStyle().Background(t.Surface)
+	divIncomingTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Info)
+	divOutgoingTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Added)
+
+	treeDirStyle = lipgloss.NewStyle().Foreground(t.Info).Bold(true)
+	treeSubmoduleStyle = lipgloss.NewStyle().Foreground(t.Secondary)
+
+	reflogActionColors = map[string]lipgloss.Color{
+		"commit":      t.Added,
+		"reset":       t.Removed,
+		"rebase":      t.Secondary,
+		"checkout":    t.Info,
+		"merge":       t.Accent,
+		"cherry-pick": t.Changed,
+	}
+
+	rangeEqualStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	rangeModifiedStyle = lipgloss.NewStyle().Foreground(t.Special).Bold(true)
+	rangeAddedStyle = lipgloss.NewStyle().Foreground(t.Added).Bold(true)
+	rangeDroppedStyle = lipgloss.NewStyle().Foreground(t.Removed).Bold(true)
+
+	contribBarStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	contribAddStyle = lipgloss.NewStyle().Foreground(t.Added)
+	contribDelStyle = lipgloss.NewStyle().Foreground(t.Removed)
+	// Shades from no commits to the busiest day in view, GitHub style
+	heatLevelStyles = []lipgloss.Style{lipgloss.NewStyle().Foreground(t.Surface)}
+	for _, c := range t.Heat {
+		heatLevelStyles = append(heatLevelStyles, lipgloss.NewStyle().Foreground(c))
+	}
+	heatSelectedStyle = lipgloss.NewStyle().Foreground(t.Secondary).Bold(true)
+	churnBarStyle = lipgloss.NewStyle().Foreground(t.Changed)
+
+	errorBannerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Background).
+		Background(t.Removed)
+	errorTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Removed)
+}
@@ -0,0 +1,120 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/utils"
+)
+
+func RenderTree(width, height int, commit types.GraphCommit, dir string, entries []types.TreeEntry, selectedIdx int, loading bool, errMsg string) string {
+	var b strings.Builder
+
+	backHint := utils.DetailsLabelStyle.Render("ESC: back")
+	commitInfo := utils.HashStyle.Render("← " + commit.Hash + " ")
+	commitMsg := utils.DetailsTitleStyle.Render(utils.TruncateMessage(commit.Message, utils.Max(width-30, 10)))
+	headerGap := width - lipgloss.Width(backHint) - lipgloss.Width(commitInfo) - lipgloss.Width(commitMsg)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(commitInfo + commitMsg + strings.Repeat(" ", headerGap) + backHint + "\n\n")
+
+	// Breadcrumb of the current directory
+	crumbs := []string{treeDirStyle.Render("/")}
+	if dir != "" {
+		for _, part := range strings.Split(dir, "/") {
+			crumbs = append(crumbs, treeDirStyle.Render(part))
+		}
+	}
+	b.WriteString(" " + strings.Join(crumbs, utils.DetailsLabelStyle.Render(" › ")) + "\n\n")
+
+	// Header (1) + spacing (1) + breadcrumb (1) + spacing (1) + border (2) + help (1)
+	availableHeight := height - 7
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	var list strings.Builder
+	switch {
+	case errMsg != "":
+		list.WriteString("  " + utils.HelpStyle.Render("Error loading tree: "+errMsg))
+	case loading:
+		list.WriteString("  " + utils.HelpStyle.Render("Loading tree..."))
+	case len(entries) == 0:
+		list.WriteString("  " + utils.HelpStyle.Render("Empty directory."))
+	default:
+		start := 0
+		if selectedIdx >= availableHeight {
+			start = selectedIdx - availableHeight + 1
+		}
+		end := start + availableHeight
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		for i := start; i < end; i++ {
+			list.WriteString(renderTreeLine(width-6, entries[i], i == selectedIdx) + "\n")
+		}
+	}
+	b.WriteString(utils.PaneStyle.Width(width-4).Height(availableHeight).Render(list.String()) + "\n")
+
+	help := utils.HelpStyle.Render("↑/↓: navigate │ enter/→: open │ ←/backspace: up │ y: copy path │ ESC: back │ q: quit")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func renderTreeLine(width int, entry types.TreeEntry, selected bool) string {
+	var name, size string
+	switch {
+	case entry.IsDir:
+		name = treeDirStyle.Render("▸ " + entry.Name + "/")
+	case entry.IsSubmodule:
+		name = treeSubmoduleStyle.Render("◆ " + entry.Name)
+		size = utils.DetailsLabelStyle.Render("submodule")
+	default:
+		name = "  " + entry.Name
+		size = utils.DetailsLabelStyle.Render(formatSize(entry.Size))
+	}
+
+	gap := width - lipgloss.Width(name) - lipgloss.Width(size) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	line := name + strings.Repeat(" ", gap) + size
+
+	if selected {
+		return utils.SelectedItemStyle.Render("→ " + line)
+	}
+	return utils.NormalItemStyle.Render("  " + line)
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func RenderTreeFile(width int, commit types.GraphCommit, filePath string, viewportContent string) string {
+	var b strings.Builder
+
+	backHint := utils.DetailsLabelStyle.Render("ESC: back  ↑↓: scroll")
+	header := utils.HashStyle.Render(commit.Hash+":") + utils.FileNameStyle.Render(filePath)
+
+	headerGap := width - lipgloss.Width(header) - lipgloss.Width(backHint)
+	if headerGap < 0 {
+		headerGap = 0
+	}
+	b.WriteString(header + strings.Repeat(" ", headerGap) + backHint + "\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(utils.Theme.Surface).Render(strings.Repeat("─", width)) + "\n")
+	b.WriteString(viewportContent)
+
+	return b.String()
+}
@@ -18,6 +18,39 @@ func copyToClipboard(text string) {
 const linesPerCommit = 1
 
 func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowExportInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowExportInput = false
+			return m, nil
+		case "enter":
+			path := strings.TrimSpace(m.ExportInput.Value())
+			m.ShowExportInput = false
+			if path == "" {
+				return m, nil
+			}
+			m.AlertMessage = "Exporting..."
+			return m, m.exportGraphCmd(path)
+		}
+
+		var cmd tea.Cmd
+		m.ExportInput, cmd = m.ExportInput.Update(msg)
+		return m, cmd
+	}
+
+	// Pulling and pushing move a branch, so they wait for a y; any other
+	// key cancels
+	if m.ConfirmRemoteOp != "" {
+		op := m.ConfirmRemoteOp
+		m.ConfirmRemoteOp = ""
+		if msg.String() == "y" && m.RemoteOp == "" {
+			m.RemoteOp = op
+			m.RemoteStatus = op + "..."
+			return m, m.remoteOpCmd(op)
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "q":
 		return m, tea.Quit
@@ -39,6 +72,10 @@ func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.ShowLegend = false
 			return m, nil
 		}
+		if !m.GraphFilter.IsZero() {
+			m.GraphFilter = types.CommitFilter{}
+			return m.reloadGraph()
+		}
 
 	case "/":
 		if m.ShowGraphSearch {
@@ -140,23 +177,16 @@ func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				gc := commits[m.GraphIdx]
 				m.SelectedCommit = gc
 				if m.SelectedCommit.Hash != "" {
-					if len(gc.Files) == 0 && m.GitService != nil {
-						parentInfos, files, _ := m.GitService.GetCommitDetails(gc.FullHash)
-						for i := range m.GraphCommits {
-							if m.GraphCommits[i].FullHash == gc.FullHash {
-								m.GraphCommits[i].ParentInfos = parentInfos
-								m.GraphCommits[i].Files = files
-								m.SelectedCommit = m.GraphCommits[i]
-								break
-							}
-						}
-					}
 					m.PreviousScreen = m.Screen
 					m.Screen = CommitDetailScreen
 					m.ShowFilter = false
 					m.FilterInput.SetValue("")
 					m.FilteredFiles = nil
 					m.FileIdx = 0
+					if len(gc.Files) == 0 && m.GitService != nil {
+						m.LoadingDetails = true
+						return m, m.loadDetailsCmd(gc.FullHash)
+					}
 				}
 			}
 		}
@@ -171,30 +201,87 @@ func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		if !m.ShowLegend {
-			m.ShowCompareModal = true
-			m.CompareModalIdx = 0
-			m.ActiveComparePane = LocalComparePane
-			m.CompareFilterInput.SetValue("")
-			m.CompareFilterInput.Focus()
-
-			// Split branches
-			m.LocalBranches = nil
-			m.RemoteBranches = nil
-			for _, b := range m.Branches {
-				if b.Name == m.CurrentBranch {
-					continue
-				}
-				if b.IsRemote {
-					m.RemoteBranches = append(m.RemoteBranches, b)
-				} else {
-					m.LocalBranches = append(m.LocalBranches, b)
-				}
+			m = m.openCompareModal("", m.CurrentBranch)
+		}
+
+	case "a":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend {
+			rng := m.CurrentBranch
+			if rng == "" {
+				rng = "HEAD"
+			}
+			return m.openContributors(rng)
+		}
+
+	case "H":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend {
+			return m.openHeatmap(types.CommitFilter{})
+		}
+
+	case "C":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend {
+			window := m.ChurnWindow
+			if window == "" {
+				window = m.Config.ChurnWindow
 			}
-			m.FilteredLocal = m.LocalBranches
-			m.FilteredRemote = m.RemoteBranches
+			return m.openChurn(window)
+		}
 
-			// Initialize viewports
-			m = m.initCompareViewports()
+	case "d":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend {
+			m.DateFormat = m.DateFormat.Next()
+			m = m.updateGraphViewportContent()
+			m.AlertMessage = "Dates: " + m.DateFormat.String()
+			return m, clearAlertCmd()
+		}
+
+	case "x":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend && len(m.getDisplayCommits()) > 0 {
+			m.ExportInput.SetValue("graph.svg")
+			m.ExportInput.CursorEnd()
+			m.ExportInput.Focus()
+			m.ShowExportInput = true
+			return m, nil
 		}
 
 	case "y":
@@ -216,6 +303,121 @@ func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case "s":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend && m.GitService != nil {
+			m.Screen = StashScreen
+			m.StashIdx = 0
+			m.LoadingStashes = true
+			return m, m.loadStashesCmd()
+		}
+
+	case "t":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		commits := m.getDisplayCommits()
+		if !m.ShowLegend && m.GitService != nil && m.GraphIdx < len(commits) {
+			m.TreeCommit = commits[m.GraphIdx]
+			m.TreeError = ""
+			m.Screen = TreeScreen
+			return m.openTreeDir("", "")
+		}
+
+	case "r":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend && m.GitService != nil {
+			m.ReflogRefs = []string{"HEAD"}
+			for _, b := range m.Branches {
+				if !b.IsRemote {
+					m.ReflogRefs = append(m.ReflogRefs, b.Name)
+				}
+			}
+			m.ReflogRefIdx = 0
+			m.ReflogIdx = 0
+			m.Reflog = nil
+			m.LoadingReflog = true
+			m.Screen = ReflogScreen
+			return m, m.loadReflogCmd("HEAD")
+		}
+
+	case "m":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		commits := m.getDisplayCommits()
+		if !m.ShowLegend && m.GraphIdx < len(commits) {
+			m.MessageCommit = commits[m.GraphIdx]
+			m.Screen = MessageScreen
+			m = m.initMessageViewport()
+		}
+
+	case "R":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend && m.GitService != nil {
+			// Comparing with the upstream shows what a force-push would change
+			m.RangeDiffInput.SetValue("")
+			if m.currentBranchInfo().Upstream != "" {
+				m.RangeDiffInput.SetValue("@{u}...HEAD")
+			}
+			m.RangeDiffInput.CursorEnd()
+			return m.openRangeDiff("")
+		}
+
+	case "f", "p", "P":
+		if m.ShowGraphSearch {
+			var cmd tea.Cmd
+			m.GraphSearchInput, cmd = m.GraphSearchInput.Update(msg)
+			m = m.filterGraphCommits()
+			m.GraphIdx = 0
+			m = m.updateGraphViewportContent()
+			return m, cmd
+		}
+		if !m.ShowLegend && m.GitService != nil && m.RemoteOp == "" {
+			switch msg.String() {
+			case "p":
+				m.ConfirmRemoteOp = "pull"
+				return m, nil
+			case "P":
+				m.ConfirmRemoteOp = "push"
+				return m, nil
+			}
+			m.RemoteOp = "fetch"
+			m.RemoteStatus = m.RemoteOp + "..."
+			return m, m.remoteOpCmd(m.RemoteOp)
+		}
+
 	case "pgup", "pgdown", "home", "end":
 		if !m.ShowLegend && !m.ShowGraphSearch && m.GraphViewportReady {
 			var cmd tea.Cmd
@@ -236,6 +438,59 @@ func (m Model) updateGraph(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// reloadGraph returns to the graph and loads the current branch again,
+// applying GraphFilter.
+func (m Model) reloadGraph() (tea.Model, tea.Cmd) {
+	m.Screen = GraphScreen
+	m.ShowGraphSearch = false
+	m.GraphSearchInput.SetValue("")
+	m.FilteredGraphCommits = nil
+	m.GraphCommits = nil
+	m.GraphIdx = 0
+	m.LoadingCommits = true
+	m = m.updateGraphViewportContent()
+	return m, m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit)
+}
+
+// mergeCommits swaps in a reloaded commit list. Commits already loaded keep
+// their file details, and the cursor stays on the same commit when it is
+// still there.
+func (m Model) mergeCommits(commits []types.GraphCommit) Model {
+	var selected string
+	if display := m.getDisplayCommits(); m.GraphIdx < len(display) {
+		selected = display[m.GraphIdx].FullHash
+	}
+
+	known := make(map[string]types.GraphCommit, len(m.GraphCommits))
+	for _, c := range m.GraphCommits {
+		known[c.FullHash] = c
+	}
+	for i, c := range commits {
+		if old, ok := known[c.FullHash]; ok {
+			commits[i].Files = old.Files
+			commits[i].ParentInfos = old.ParentInfos
+			if c.Signature == types.Unverified {
+				commits[i].Signature, commits[i].Signer = old.Signature, old.Signer
+			}
+		}
+	}
+
+	m.GraphCommits = commits
+	if m.ShowGraphSearch {
+		m = m.filterGraphCommits()
+	}
+
+	display := m.getDisplayCommits()
+	m.GraphIdx = min(m.GraphIdx, max(len(display)-1, 0))
+	for i, c := range display {
+		if c.FullHash == selected {
+			m.GraphIdx = i
+			break
+		}
+	}
+	return m
+}
+
 func (m Model) getDisplayCommits() []types.GraphCommit {
 	if m.ShowGraphSearch && len(m.FilteredGraphCommits) > 0 {
 		return m.FilteredGraphCommits
@@ -297,7 +552,7 @@ func (m Model) initGraphViewport() Model {
 	m.GraphViewport.YPosition = headerHeight
 
 	commits := m.getDisplayCommits()
-	content := screens.RenderGraphContent(leftPaneWidth, commits, m.GraphIdx)
+	content := screens.RenderGraphContent(leftPaneWidth, commits, m.GraphIdx, m.DateFormat)
 	m.GraphViewport.SetContent(content)
 	m.GraphViewportReady = true
 
@@ -308,7 +563,7 @@ func (m Model) updateGraphViewportContent() Model {
 	if m.GraphViewportReady {
 		leftPaneWidth := (m.Width * 60) / 100
 		commits := m.getDisplayCommits()
-		content := screens.RenderGraphContent(leftPaneWidth, commits, m.GraphIdx)
+		content := screens.RenderGraphContent(leftPaneWidth, commits, m.GraphIdx, m.DateFormat)
 		m.GraphViewport.SetContent(content)
 	}
 	return m
@@ -317,18 +572,19 @@ func (m Model) updateGraphViewportContent() Model {
 func (m Model) initCompareViewports() Model {
 	modalWidth := int(float64(m.Width) * 0.8)
 	modalHeight := int(float64(m.Height) * 0.7)
-	paneWidth := (modalWidth - 6) / 2 // Borders and divider
-	paneHeight := modalHeight - 7     // Headers, filter, footer
+	paneWidth := (modalWidth - 8) / 3 // Borders and dividers
+	paneHeight := modalHeight - 9     // Sides, headers, filter, footer
 
 	m.CompareLocalPane = viewport.New(paneWidth, paneHeight)
 	m.CompareRemotePane = viewport.New(paneWidth, paneHeight)
+	m.CompareTagPane = viewport.New(paneWidth, paneHeight)
 
 	return m.updateCompareViewportContent()
 }
 
 func (m Model) updateCompareViewportContent() Model {
 	modalWidth := int(float64(m.Width) * 0.8)
-	paneWidth := (modalWidth - 6) / 2
+	paneWidth := (modalWidth - 8) / 3
 
 	m = m.scrollToCompareSelection()
 
@@ -338,30 +594,29 @@ func (m Model) updateCompareViewportContent() Model {
 	remoteContent := screens.RenderBranchListContent(paneWidth, m.FilteredRemote, m.CompareModalIdx, m.ActiveComparePane == RemoteComparePane)
 	m.CompareRemotePane.SetContent(remoteContent)
 
+	tagContent := screens.RenderBranchListContent(paneWidth, m.FilteredTags, m.CompareModalIdx, m.ActiveComparePane == TagComparePane)
+	m.CompareTagPane.SetContent(tagContent)
+
 	return m
 }
 
 func (m Model) scrollToCompareSelection() Model {
-	if m.ActiveComparePane == LocalComparePane {
-		selectedLine := m.CompareModalIdx
-		viewportHeight := m.CompareLocalPane.Height
-		currentTop := m.CompareLocalPane.YOffset
-
-		if selectedLine < currentTop {
-			m.CompareLocalPane.SetYOffset(selectedLine)
-		} else if selectedLine >= currentTop+viewportHeight {
-			m.CompareLocalPane.SetYOffset(selectedLine - viewportHeight + 1)
-		}
-	} else {
-		selectedLine := m.CompareModalIdx
-		viewportHeight := m.CompareRemotePane.Height
-		currentTop := m.CompareRemotePane.YOffset
-
-		if selectedLine < currentTop {
-			m.CompareRemotePane.SetYOffset(selectedLine)
-		} else if selectedLine >= currentTop+viewportHeight {
-			m.CompareRemotePane.SetYOffset(selectedLine - viewportHeight + 1)
-		}
+	pane := &m.CompareLocalPane
+	switch m.ActiveComparePane {
+	case RemoteComparePane:
+		pane = &m.CompareRemotePane
+	case TagComparePane:
+		pane = &m.CompareTagPane
+	}
+
+	selectedLine := screens.BranchListLine(m.activeCompareList(), m.CompareModalIdx)
+	viewportHeight := pane.Height
+	currentTop := pane.YOffset
+
+	if selectedLine < currentTop {
+		pane.SetYOffset(selectedLine)
+	} else if selectedLine >= currentTop+viewportHeight {
+		pane.SetYOffset(selectedLine - viewportHeight + 1)
 	}
 	return m
 }
@@ -394,26 +649,21 @@ func (m Model) updateBranchViewportContent() Model {
 }
 
 func (m Model) scrollToBranchSelection() Model {
-	if m.ActiveBranchPane == LocalComparePane {
-		selectedLine := m.BranchModalIdx
-		viewportHeight := m.BranchLocalPane.Height
-		currentTop := m.BranchLocalPane.YOffset
-
-		if selectedLine < currentTop {
-			m.BranchLocalPane.SetYOffset(selectedLine)
-		} else if selectedLine >= currentTop+viewportHeight {
-			m.BranchLocalPane.SetYOffset(selectedLine - viewportHeight + 1)
-		}
-	} else {
-		selectedLine := m.BranchModalIdx
-		viewportHeight := m.BranchRemotePane.Height
-		currentTop := m.BranchRemotePane.YOffset
-
-		if selectedLine < currentTop {
-			m.BranchRemotePane.SetYOffset(selectedLine)
-		} else if selectedLine >= currentTop+viewportHeight {
-			m.BranchRemotePane.SetYOffset(selectedLine - viewportHeight + 1)
-		}
+	pane := &m.BranchLocalPane
+	branches := m.BranchFilteredLocal
+	if m.ActiveBranchPane != LocalComparePane {
+		pane = &m.BranchRemotePane
+		branches = m.BranchFilteredRemote
+	}
+
+	selectedLine := screens.BranchListLine(branches, m.BranchModalIdx)
+	viewportHeight := pane.Height
+	currentTop := pane.YOffset
+
+	if selectedLine < currentTop {
+		pane.SetYOffset(selectedLine)
+	} else if selectedLine >= currentTop+viewportHeight {
+		pane.SetYOffset(selectedLine - viewportHeight + 1)
 	}
 	return m
 }
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// commitList builds commits named by their hashes, newest first.
+func commitList(hashes ...string) []types.GraphCommit {
+	commits := make([]types.GraphCommit, len(hashes))
+	for i, h := range hashes {
+		commits[i] = types.GraphCommit{FullHash: h, Hash: h, Message: "commit " + h}
+	}
+	return commits
+}
+
+func TestMergeCommits(t *testing.T) {
+	tests := []struct {
+		name     string
+		loaded   []string
+		idx      int
+		search   string
+		reloaded []string
+		wantIdx  int
+	}{
+		{
+			name:     "new commit on top keeps the selection",
+			loaded:   []string{"c", "b", "a"},
+			idx:      1,
+			reloaded: []string{"d", "c", "b", "a"},
+			wantIdx:  2,
+		},
+		{
+			name:     "first commit stays selected when it is still there",
+			loaded:   []string{"c", "b", "a"},
+			reloaded: []string{"e", "d", "c", "b", "a"},
+			wantIdx:  2,
+		},
+		{
+			name:     "selected commit gone keeps the row",
+			loaded:   []string{"c", "b", "a"},
+			idx:      1,
+			reloaded: []string{"x", "y", "a"},
+			wantIdx:  1,
+		},
+		{
+			name:     "shorter list clamps the row",
+			loaded:   []string{"c", "b", "a"},
+			idx:      2,
+			reloaded: []string{"y", "x"},
+			wantIdx:  1,
+		},
+		{
+			name:     "empty list",
+			loaded:   []string{"c", "b", "a"},
+			idx:      2,
+			reloaded: nil,
+			wantIdx:  0,
+		},
+		{
+			name:     "search results follow the selection",
+			loaded:   []string{"c", "b", "a"},
+			idx:      1,
+			search:   "commit",
+			reloaded: []string{"d", "c", "b", "a"},
+			wantIdx:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := InitialModel(".")
+			m.GraphCommits = commitList(tt.loaded...)
+			if tt.search != "" {
+				m.ShowGraphSearch = true
+				m.GraphSearchInput.SetValue(tt.search)
+				m = m.filterGraphCommits()
+			}
+			m.GraphIdx = tt.idx
+
+			m = m.mergeCommits(commitList(tt.reloaded...))
+			if m.GraphIdx != tt.wantIdx {
+				t.Errorf("GraphIdx = %d, want %d", m.GraphIdx, tt.wantIdx)
+			}
+			if display := m.getDisplayCommits(); len(display) != len(tt.reloaded) {
+				t.Errorf("%d commits shown, want %d", len(display), len(tt.reloaded))
+			}
+		})
+	}
+}
+
+func TestMergeCommitsKeepsDetails(t *testing.T) {
+	m := InitialModel(".")
+	m.GraphCommits = commitList("b", "a")
+	m.GraphCommits[1].Files = []types.FileChange{{Path: "f", Status: "M"}}
+	m.GraphCommits[1].Signature, m.GraphCommits[1].Signer = types.GoodSignature, "Ada"
+
+	reloaded := commitList("c", "b", "a")
+	reloaded[2].Signature = types.Unverified
+	m = m.mergeCommits(reloaded)
+
+	a := m.GraphCommits[2]
+	if len(a.Files) != 1 || a.Files[0].Path != "f" {
+		t.Errorf("files = %v, want the loaded details kept", a.Files)
+	}
+	if a.Signature != types.GoodSignature || a.Signer != "Ada" {
+		t.Errorf("signature = %v %q, want the verified result kept", a.Signature, a.Signer)
+	}
+	if m.GraphCommits[0].Files != nil {
+		t.Errorf("new commit has files %v before they were loaded", m.GraphCommits[0].Files)
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tomiwa-a/git-radar/internal/types"
@@ -15,16 +16,16 @@ type dummyDivergence struct {
 func getDummyDivergenceCommits() dummyDivergence {
 	return dummyDivergence{
 		Incoming: []types.GraphCommit{
-			{Hash: "def5678", FullHash: "def5678abc123", Message: "fix auth bug", Author: "Tomiwa", Date: "2 days ago", Files: []types.FileChange{{Status: "M", Path: "auth.go", Additions: 10, Deletions: 5}}},
-			{Hash: "jkl3456", FullHash: "jkl3456def456", Message: "Merge pull request #4", Author: "Tomiwa", Date: "3 days ago", IsMerge: true},
-			{Hash: "pqr1234", FullHash: "pqr1234ghi789", Message: "update dependencies", Author: "Tomiwa", Date: "4 days ago"},
+			{Hash: "def5678", FullHash: "def5678abc123", Message: "fix auth bug", Author: "Tomiwa", Date: time.Now().Add(-2 * 24 * time.Hour), Files: []types.FileChange{{Status: "M", Path: "auth.go", Additions: 10, Deletions: 5}}},
+			{Hash: "jkl3456", FullHash: "jkl3456def456", Message: "Merge pull request #4", Author: "Tomiwa", Date: time.Now().Add(-3 * 24 * time.Hour), IsMerge: true},
+			{Hash: "pqr1234", FullHash: "pqr1234ghi789", Message: "update dependencies", Author: "Tomiwa", Date: time.Now().Add(-4 * 24 * time.Hour)},
 		},
 		Outgoing: []types.GraphCommit{
-			{Hash: "ghi9012", FullHash: "ghi9012jkl012", Message: "add logging to service", Author: "Tomiwa", Date: "2 hours ago", Files: []types.FileChange{{Status: "A", Path: "logger.go", Additions: 30, Deletions: 0}, {Status: "M", Path: "service.go", Additions: 15, Deletions: 2}}},
-			{Hash: "mno7890", FullHash: "mno7890mno345", Message: "refactor git service", Author: "Tomiwa", Date: "5 hours ago", Files: []types.FileChange{{Status: "M", Path: "service.go", Additions: 50, Deletions: 30}}},
-			{Hash: "stu5678", FullHash: "stu5678pqr678", Message: "add unit tests", Author: "Tomiwa", Date: "1 day ago"},
-			{Hash: "vwx9012", FullHash: "vwx9012stu901", Message: "cleanup unused code", Author: "Tomiwa", Date: "1 day ago"},
-			{Hash: "yza3456", FullHash: "yza3456vwx234", Message: "update documentation", Author: "Tomiwa", Date: "2 days ago"},
+			{Hash: "ghi9012", FullHash: "ghi9012jkl012", Message: "add logging to service", Author: "Tomiwa", Date: time.Now().Add(-2 * time.Hour), Files: []types.FileChange{{Status: "A", Path: "logger.go", Additions: 30, Deletions: 0}, {Status: "M", Path: "service.go", Additions: 15, Deletions: 2}}},
+			{Hash: "mno7890", FullHash: "mno7890mno345", Message: "refactor git service", Author: "Tomiwa", Date: time.Now().Add(-5 * time.Hour), Files: []types.FileChange{{Status: "M", Path: "service.go", Additions: 50, Deletions: 30}}},
+			{Hash: "stu5678", FullHash: "stu5678pqr678", Message: "add unit tests", Author: "Tomiwa", Date: time.Now().Add(-24 * time.Hour)},
+			{Hash: "vwx9012", FullHash: "vwx9012stu901", Message: "cleanup unused code", Author: "Tomiwa", Date: time.Now().Add(-24 * time.Hour)},
+			{Hash: "yza3456", FullHash: "yza3456vwx234", Message: "update documentation", Author: "Tomiwa", Date: time.Now().Add(-2 * 24 * time.Hour)},
 		},
 	}
 }
@@ -88,12 +89,12 @@ func (m Model) updateBranchModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.Outgoing = nil
 				m.MergeBase = nil
 				return m, tea.Batch(
-					m.loadCommitsCmd(m.CurrentBranch, 100),
+					m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit),
 					m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch),
 				)
 			}
 			m.LoadingCommits = true
-			return m, m.loadCommitsCmd(m.CurrentBranch, 100)
+			return m, m.loadCommitsCmd(m.CurrentBranch, m.Config.CommitLimit)
 		}
 	}
 
@@ -129,6 +130,107 @@ func (m Model) updateBranchModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m.updateBranchViewportContent(), cmd
 }
 
+func (m Model) openCompareModal(target, source string) Model {
+	m.ShowCompareModal = true
+	m.CompareModalIdx = 0
+	m.ActiveComparePane = LocalComparePane
+	m.CompareSide = TargetSide
+	m.CompareTarget = target
+	m.CompareSource = source
+	m.CompareFilterInput.SetValue("")
+	m.CompareFilterInput.Focus()
+
+	// Split branches
+	m.LocalBranches = nil
+	m.RemoteBranches = nil
+	for _, b := range m.Branches {
+		if b.IsRemote {
+			m.RemoteBranches = append(m.RemoteBranches, b)
+		} else {
+			m.LocalBranches = append(m.LocalBranches, b)
+		}
+	}
+	m.FilteredLocal = m.LocalBranches
+	m.FilteredRemote = m.RemoteBranches
+	m.FilteredTags = m.Tags
+
+	// Initialize viewports
+	return m.initCompareViewports()
+}
+
+func (m Model) activeCompareList() []types.Branch {
+	switch m.ActiveComparePane {
+	case RemoteComparePane:
+		return m.FilteredRemote
+	case TagComparePane:
+		return m.FilteredTags
+	}
+	return m.FilteredLocal
+}
+
+// pickCompareRevision fills the side being edited and moves on to the other
+// side. Once both sides are set the comparison starts.
+func (m Model) pickCompareRevision(rev string) (tea.Model, tea.Cmd) {
+	if m.CompareSide == TargetSide {
+		m.CompareTarget = rev
+		m.CompareSide = SourceSide
+	} else {
+		m.CompareSource = rev
+		m.CompareSide = TargetSide
+	}
+
+	if m.CompareTarget == "" || m.CompareSource == "" {
+		m.CompareFilterInput.SetValue("")
+		m = m.filterCompareLists()
+		return m.updateCompareViewportContent(), nil
+	}
+
+	m.TargetBranch = m.CompareTarget
+	m.SourceBranch = m.CompareSource
+	m.ShowCompareModal = false
+	m.Screen = DivergenceScreen
+	m.IncomingIdx = 0
+	m.OutgoingIdx = 0
+	m.ActivePane = OutgoingPane
+	m.LoadingDivergence = true
+	m.Incoming = nil
+	m.Outgoing = nil
+	m.MergeBase = nil
+	m.PickedCommits = nil
+	return m, m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch)
+}
+
+func (m Model) filterCompareLists() Model {
+	query := strings.ToLower(m.CompareFilterInput.Value())
+
+	m.FilteredLocal = nil
+	for _, b := range m.LocalBranches {
+		if strings.Contains(strings.ToLower(b.Name), query) {
+			m.FilteredLocal = append(m.FilteredLocal, b)
+		}
+	}
+
+	m.FilteredRemote = nil
+	for _, b := range m.RemoteBranches {
+		if strings.Contains(strings.ToLower(b.Name), query) {
+			m.FilteredRemote = append(m.FilteredRemote, b)
+		}
+	}
+
+	m.FilteredTags = nil
+	for _, t := range m.Tags {
+		if strings.Contains(strings.ToLower(t.Name), query) {
+			m.FilteredTags = append(m.FilteredTags, t)
+		}
+	}
+
+	// Reset index if it's out of bounds
+	if m.CompareModalIdx >= len(m.activeCompareList()) {
+		m.CompareModalIdx = 0
+	}
+	return m
+}
+
 func (m Model) updateCompareModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -136,92 +238,86 @@ func (m Model) updateCompareModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "tab", "right", "l":
-		if m.ActiveComparePane == LocalComparePane {
-			m.ActiveComparePane = RemoteComparePane
-			m.CompareModalIdx = 0
-		} else {
-			m.ActiveComparePane = LocalComparePane
-			m.CompareModalIdx = 0
-		}
-		return m.updateCompareViewportContent(), nil
+		m.ActiveComparePane = (m.ActiveComparePane + 1) % 3
+		m.CompareModalIdx = 0
+		return m.updateCompareViewportContent(), m.verifyTagCmd()
 
 	case "left", "h":
-		if m.ActiveComparePane == RemoteComparePane {
-			m.ActiveComparePane = LocalComparePane
-			m.CompareModalIdx = 0
+		m.ActiveComparePane = (m.ActiveComparePane + 2) % 3
+		m.CompareModalIdx = 0
+		return m.updateCompareViewportContent(), m.verifyTagCmd()
+
+	case "shift+tab":
+		if m.CompareSide == TargetSide {
+			m.CompareSide = SourceSide
 		} else {
-			m.ActiveComparePane = RemoteComparePane
-			m.CompareModalIdx = 0
+			m.CompareSide = TargetSide
 		}
-		return m.updateCompareViewportContent(), nil
+		return m, nil
+
+	case "ctrl+x":
+		m.CompareTarget, m.CompareSource = m.CompareSource, m.CompareTarget
+		return m, nil
+
+	case "ctrl+g":
+		commits := m.getDisplayCommits()
+		if len(commits) > 0 && m.GraphIdx < len(commits) {
+			return m.pickCompareRevision(commits[m.GraphIdx].Hash)
+		}
+		return m, nil
 
 	case "up", "k":
 		if m.CompareModalIdx > 0 {
 			m.CompareModalIdx--
 			m = m.updateCompareViewportContent()
 		}
-		return m, nil
+		return m, m.verifyTagCmd()
 
 	case "down", "j":
-		activeList := m.FilteredLocal
-		if m.ActiveComparePane == RemoteComparePane {
-			activeList = m.FilteredRemote
-		}
-		if m.CompareModalIdx < len(activeList)-1 {
+		if m.CompareModalIdx < len(m.activeCompareList())-1 {
 			m.CompareModalIdx++
 			m = m.updateCompareViewportContent()
 		}
-		return m, nil
+		return m, m.verifyTagCmd()
 
 	case "enter":
-		activeList := m.FilteredLocal
-		if m.ActiveComparePane == RemoteComparePane {
-			activeList = m.FilteredRemote
-		}
+		activeList := m.activeCompareList()
 		if len(activeList) > 0 && m.CompareModalIdx < len(activeList) {
-			m.TargetBranch = activeList[m.CompareModalIdx].Name
-			m.SourceBranch = m.CurrentBranch
-			m.ShowCompareModal = false
-			m.Screen = DivergenceScreen
-			m.IncomingIdx = 0
-			m.OutgoingIdx = 0
-			m.ActivePane = OutgoingPane
-			m.LoadingDivergence = true
-			m.Incoming = nil
-			m.Outgoing = nil
-			m.MergeBase = nil
-			return m, m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch)
+			return m.pickCompareRevision(activeList[m.CompareModalIdx].Name)
+		}
+		// Nothing matches the filter, so treat it as a revision expression
+		if rev := strings.TrimSpace(m.CompareFilterInput.Value()); rev != "" {
+			if m.GitService != nil {
+				if _, err := m.GitService.ResolveRevision(rev); err != nil {
+					m.AlertMessage = err.Error()
+					return m, clearAlertCmd()
+				}
+			}
+			return m.pickCompareRevision(rev)
 		}
+		return m, nil
 	}
 
 	var cmd tea.Cmd
 	m.CompareFilterInput, cmd = m.CompareFilterInput.Update(msg)
+	m = m.filterCompareLists()
 
-	// Update filtering
-	query := strings.ToLower(m.CompareFilterInput.Value())
-
-	m.FilteredLocal = nil
-	for _, b := range m.LocalBranches {
-		if strings.Contains(strings.ToLower(b.Name), query) {
-			m.FilteredLocal = append(m.FilteredLocal, b)
-		}
-	}
+	return m.updateCompareViewportContent(), tea.Batch(cmd, m.verifyTagCmd())
+}
 
-	m.FilteredRemote = nil
-	for _, b := range m.RemoteBranches {
-		if strings.Contains(strings.ToLower(b.Name), query) {
-			m.FilteredRemote = append(m.FilteredRemote, b)
-		}
+// verifyTagCmd checks the signature of the tag under the cursor the first
+// time it is highlighted, as listing tags leaves them unverified.
+func (m Model) verifyTagCmd() tea.Cmd {
+	if m.ActiveComparePane != TagComparePane || m.GitService == nil || m.CompareModalIdx >= len(m.FilteredTags) {
+		return nil
 	}
-
-	// Reset index if it's out of bounds
-	activeList := m.FilteredLocal
-	if m.ActiveComparePane == RemoteComparePane {
-		activeList = m.FilteredRemote
+	tag := m.FilteredTags[m.CompareModalIdx]
+	if tag.Signature != types.Unverified {
+		return nil
 	}
-	if m.CompareModalIdx >= len(activeList) {
-		m.CompareModalIdx = 0
+	service := m.GitService
+	return func() tea.Msg {
+		signature, signer, err := service.VerifyTag(tag.FullName)
+		return TagVerifiedMsg{Name: tag.FullName, Signature: signature, Signer: signer, Err: err}
 	}
-
-	return m.updateCompareViewportContent(), cmd
 }
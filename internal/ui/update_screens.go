@@ -1,15 +1,39 @@
 package ui
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tomiwa-a/git-radar/internal/types"
+	"github.com/tomiwa-a/git-radar/internal/ui/screens"
 	"github.com/tomiwa-a/git-radar/utils"
 )
 
 func (m Model) updateDivergence(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowExportInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowExportInput = false
+			return m, nil
+		case "enter":
+			path := strings.TrimSpace(m.ExportInput.Value())
+			m.ShowExportInput = false
+			if path == "" {
+				return m, nil
+			}
+			m.AlertMessage = "Exporting..."
+			return m, m.exportDivergenceCmd(path)
+		}
+
+		var cmd tea.Cmd
+		m.ExportInput, cmd = m.ExportInput.Update(msg)
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "q":
 		return m, tea.Quit
@@ -42,8 +66,22 @@ func (m Model) updateDivergence(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case "c":
-		m.ShowCompareModal = true
-		m.CompareModalIdx = 0
+		m = m.openCompareModal(m.TargetBranch, m.SourceBranch)
+
+	case "R":
+		if m.GitService != nil {
+			return m.openRangeDiff(m.TargetBranch + "..." + m.SourceBranch)
+		}
+
+	case "s":
+		if !m.LoadingDivergence {
+			m.TargetBranch, m.SourceBranch = m.SourceBranch, m.TargetBranch
+			m.IncomingIdx = 0
+			m.OutgoingIdx = 0
+			m.PickedCommits = nil
+			m.LoadingDivergence = true
+			return m, m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch)
+		}
 
 	case "enter":
 		var commit types.GraphCommit
@@ -79,6 +117,79 @@ func (m Model) updateDivergence(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, clearAlertCmd()
 		}
 
+	case " ":
+		if m.ActivePane == IncomingPane && len(m.Incoming) > 0 {
+			hash := m.Incoming[m.IncomingIdx].FullHash
+			if m.PickedCommits == nil {
+				m.PickedCommits = make(map[string]bool)
+			}
+			if m.PickedCommits[hash] {
+				delete(m.PickedCommits, hash)
+			} else {
+				m.PickedCommits[hash] = true
+			}
+		}
+
+	case "p":
+		if m.GitService == nil || len(m.Incoming) == 0 {
+			return m, nil
+		}
+		// Apply oldest first; Incoming is listed newest first
+		var hashes []string
+		for i := len(m.Incoming) - 1; i >= 0; i-- {
+			if m.PickedCommits[m.Incoming[i].FullHash] {
+				hashes = append(hashes, m.Incoming[i].FullHash)
+			}
+		}
+		if len(hashes) == 0 && m.ActivePane == IncomingPane {
+			hashes = []string{m.Incoming[m.IncomingIdx].FullHash}
+		}
+		if len(hashes) > 0 {
+			m.AlertMessage = "Cherry-picking..."
+			return m, m.cherryPickCmd(m.SourceBranch, hashes)
+		}
+
+	case "f":
+		if len(m.BranchDiffFiles) > 0 {
+			m.SelectedCommit = types.GraphCommit{
+				Hash:    m.CompareMode.Range(m.TargetBranch, m.SourceBranch),
+				Message: "branch diff",
+				Files:   m.BranchDiffFiles,
+			}
+			m.BranchDiffMode = true
+			m.PreviousScreen = m.Screen
+			m.Screen = CommitDetailScreen
+			m.ShowFilter = false
+			m.FilterInput.SetValue("")
+			m.FilteredFiles = nil
+			m.FileIdx = 0
+		}
+
+	case "m":
+		if m.GitService != nil && !m.LoadingDivergence {
+			if m.CompareMode == types.ThreeDotCompare {
+				m.CompareMode = types.TwoDotCompare
+			} else {
+				m.CompareMode = types.ThreeDotCompare
+			}
+			m.LoadingDivergence = true
+			return m, m.loadDivergenceCmd(m.TargetBranch, m.SourceBranch)
+		}
+
+	case "a":
+		if m.GitService != nil && m.CherryPicking {
+			return m, m.abortCherryPickCmd()
+		}
+
+	case "x":
+		if !m.LoadingDivergence {
+			name := "divergence-" + m.TargetBranch + "-" + m.SourceBranch + ".md"
+			m.ExportInput.SetValue(strings.NewReplacer("/", "-", "~", "-", "^", "-", " ", "-").Replace(name))
+			m.ExportInput.CursorEnd()
+			m.ExportInput.Focus()
+			m.ShowExportInput = true
+		}
+
 	case "esc":
 		m.Screen = GraphScreen
 	}
@@ -171,6 +282,7 @@ func (m Model) updateCommitDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		m.Screen = m.PreviousScreen
 		m.SelectedCommit = types.GraphCommit{}
+		m.BranchDiffMode = false
 		m.FileIdx = 0
 		if m.PreviousScreen == GraphScreen {
 			m = m.updateGraphViewportContent()
@@ -250,13 +362,316 @@ func (m Model) initViewport() Model {
 
 	var content string
 	if m.GitService != nil {
-		diffLines, err := m.GitService.GetFileDiff(m.SelectedCommit.FullHash, file.Path)
+		var diffLines []types.DiffLine
+		var err error
+		if m.BranchDiffMode {
+			diffLines, err = m.GitService.GetBranchFileDiff(m.SourceBranch, m.TargetBranch, file.Path, m.CompareMode)
+		} else {
+			diffLines, err = m.GitService.GetFileDiff(m.SelectedCommit.FullHash, file.Path)
+		}
 		if err != nil {
 			content = "Error loading diff: " + err.Error()
 		} else if diffLines == nil {
 			content = "No changes in this file"
 		} else {
-			content = utils.RenderDiffLines(diffLines, file.Path)
+			content = utils.RenderDiffLines(diffLines, file.Path, m.Config.SyntaxStyle, m.Config.Theme, m.Config.DiffCollapse, m.Config.DiffContext)
+		}
+	} else {
+		content = "Git service not available"
+	}
+
+	m.Viewport.SetContent(content)
+	m.ViewportReady = true
+
+	return m
+}
+
+func (m Model) updateStash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowStashInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowStashInput = false
+			m.StashUntracked = false
+			m.StashInput.SetValue("")
+			return m, nil
+		case "enter":
+			message := strings.TrimSpace(m.StashInput.Value())
+			cmd := m.stashCmd("create", 0, message)
+			m.ShowStashInput = false
+			m.StashUntracked = false
+			m.StashInput.SetValue("")
+			m.AlertMessage = "Stashing..."
+			return m, cmd
+		}
+
+		var cmd tea.Cmd
+		m.StashInput, cmd = m.StashInput.Update(msg)
+		return m, cmd
+	}
+
+	// Dropping can't be undone, so it waits for a y; any other key cancels
+	if m.ConfirmStashDrop {
+		m.ConfirmStashDrop = false
+		if msg.String() == "y" && len(m.Stashes) > 0 {
+			return m, m.stashCmd("drop", m.Stashes[m.StashIdx].Index, "")
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		if m.StashIdx > 0 {
+			m.StashIdx--
+		}
+
+	case "down", "j":
+		if m.StashIdx < len(m.Stashes)-1 {
+			m.StashIdx++
+		}
+
+	case "enter":
+		if len(m.Stashes) > 0 {
+			stash := m.Stashes[m.StashIdx]
+			m.SelectedCommit = types.GraphCommit{
+				Hash:     stash.Name,
+				FullHash: stash.FullHash,
+				Message:  stash.Message,
+				Date:     stash.Date,
+			}
+			m.PreviousScreen = m.Screen
+			m.Screen = CommitDetailScreen
+			m.ShowFilter = false
+			m.FilterInput.SetValue("")
+			m.FilteredFiles = nil
+			m.FileIdx = 0
+			m.LoadingDetails = true
+			return m, m.loadDetailsCmd(stash.FullHash)
+		}
+
+	case "a", "p":
+		if len(m.Stashes) > 0 && m.GitService != nil {
+			op := "apply"
+			if msg.String() == "p" {
+				op = "pop"
+			}
+			return m, m.stashCmd(op, m.Stashes[m.StashIdx].Index, "")
+		}
+
+	case "d":
+		if len(m.Stashes) > 0 && m.GitService != nil {
+			m.ConfirmStashDrop = true
+		}
+
+	case "n", "u":
+		if m.GitService != nil {
+			m.ShowStashInput = true
+			m.StashUntracked = msg.String() == "u"
+			m.StashInput.SetValue("")
+			m.StashInput.Focus()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateReflog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowReflogInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowReflogInput = false
+			m.ReflogInput.SetValue("")
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(m.ReflogInput.Value())
+			m.ShowReflogInput = false
+			m.ReflogInput.SetValue("")
+			if name != "" && len(m.Reflog) > 0 {
+				return m, m.createBranchCmd(name, m.Reflog[m.ReflogIdx].FullNewHash)
+			}
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.ReflogInput, cmd = m.ReflogInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		if m.ReflogIdx > 0 {
+			m.ReflogIdx--
+		}
+
+	case "down", "j":
+		if m.ReflogIdx < len(m.Reflog)-1 {
+			m.ReflogIdx++
+		}
+
+	case "left", "h", "right", "l", "tab":
+		if len(m.ReflogRefs) > 1 {
+			if msg.String() == "left" || msg.String() == "h" {
+				m.ReflogRefIdx = (m.ReflogRefIdx + len(m.ReflogRefs) - 1) % len(m.ReflogRefs)
+			} else {
+				m.ReflogRefIdx = (m.ReflogRefIdx + 1) % len(m.ReflogRefs)
+			}
+			m.ReflogIdx = 0
+			m.Reflog = nil
+			m.LoadingReflog = true
+			return m, m.loadReflogCmd(m.ReflogRefs[m.ReflogRefIdx])
+		}
+
+	case "enter":
+		if len(m.Reflog) > 0 {
+			entry := m.Reflog[m.ReflogIdx]
+			m.SelectedCommit = types.GraphCommit{
+				Hash:     entry.NewHash,
+				FullHash: entry.FullNewHash,
+				Message:  entry.Selector + ": " + entry.Action,
+				Date:     entry.Date,
+			}
+			m.PreviousScreen = m.Screen
+			m.Screen = CommitDetailScreen
+			m.ShowFilter = false
+			m.FilterInput.SetValue("")
+			m.FilteredFiles = nil
+			m.FileIdx = 0
+			m.LoadingDetails = true
+			return m, m.loadDetailsCmd(entry.FullNewHash)
+		}
+
+	case "y":
+		if len(m.Reflog) > 0 {
+			copyToClipboard(m.Reflog[m.ReflogIdx].FullNewHash)
+			m.AlertMessage = "Hash copied!"
+			return m, clearAlertCmd()
+		}
+
+	case "R":
+		// The ref as it was at this entry against where it is now
+		if len(m.Reflog) > 0 {
+			entry := m.Reflog[m.ReflogIdx]
+			return m.openRangeDiff(entry.FullNewHash + "..." + m.ReflogRefs[m.ReflogRefIdx])
+		}
+
+	case "n":
+		if len(m.Reflog) > 0 {
+			m.ShowReflogInput = true
+			m.ReflogInput.SetValue("recover-" + m.Reflog[m.ReflogIdx].NewHash)
+			m.ReflogInput.CursorEnd()
+			m.ReflogInput.Focus()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		if m.TreeIdx > 0 {
+			m.TreeIdx--
+		}
+
+	case "down", "j":
+		if m.TreeIdx < len(m.TreeEntries)-1 {
+			m.TreeIdx++
+		}
+
+	case "enter", "right", "l":
+		if len(m.TreeEntries) == 0 || m.LoadingTree {
+			return m, nil
+		}
+		entry := m.TreeEntries[m.TreeIdx]
+		switch {
+		case entry.IsDir:
+			return m.openTreeDir(entry.Path, "")
+		case !entry.IsSubmodule:
+			m.TreeFile = entry.Path
+			m.Screen = TreeFileScreen
+			m = m.initTreeFileViewport()
+		}
+
+	case "left", "h", "backspace":
+		if m.TreeDir != "" && !m.LoadingTree {
+			parent, name := "", m.TreeDir
+			if i := strings.LastIndex(m.TreeDir, "/"); i != -1 {
+				parent, name = m.TreeDir[:i], m.TreeDir[i+1:]
+			}
+			return m.openTreeDir(parent, name)
+		}
+
+	case "y":
+		if len(m.TreeEntries) > 0 {
+			copyToClipboard(m.TreeEntries[m.TreeIdx].Path)
+			m.AlertMessage = "Path copied!"
+			return m, clearAlertCmd()
+		}
+	}
+	return m, nil
+}
+
+// openTreeDir loads dir of the tree being browsed, selecting the entry
+// named sel once it arrives.
+func (m Model) openTreeDir(dir, sel string) (tea.Model, tea.Cmd) {
+	m.TreeDir = dir
+	m.TreeSelect = sel
+	m.TreeEntries = nil
+	m.TreeIdx = 0
+	m.LoadingTree = true
+	return m, m.loadTreeCmd(m.TreeCommit.FullHash, dir)
+}
+
+func (m Model) updateTreeFile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = TreeScreen
+		m.ViewportReady = false
+
+	default:
+		var cmd tea.Cmd
+		m.Viewport, cmd = m.Viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) initTreeFileViewport() Model {
+	headerHeight := 2
+	m.Viewport = viewport.New(m.Width, m.Height-headerHeight)
+	m.Viewport.YPosition = headerHeight
+
+	var content string
+	if m.GitService != nil {
+		code, err := m.GitService.GetFileContent(m.TreeCommit.FullHash, m.TreeFile)
+		switch {
+		case err != nil:
+			content = "Error loading file: " + err.Error()
+		case strings.IndexByte(code, 0) != -1:
+			content = fmt.Sprintf("Binary file (%d bytes)", len(code))
+		default:
+			content = utils.RenderCodeWithLineNumbers(code, m.TreeFile, m.Width, m.Config.SyntaxStyle, m.Config.Theme)
 		}
 	} else {
 		content = "Git service not available"
@@ -267,3 +682,486 @@ func (m Model) initViewport() Model {
 
 	return m
 }
+
+// openRangeDiff shows the range-diff screen, comparing ranges straight away
+// or asking for them when ranges is empty.
+func (m Model) openRangeDiff(ranges string) (tea.Model, tea.Cmd) {
+	if m.Screen != RangeDiffScreen {
+		m.RangeDiffReturn = m.Screen
+	}
+	m.Screen = RangeDiffScreen
+	m.RangeDiffPairs = nil
+	m.RangeDiffIdx = 0
+	m.RangeDiffError = ""
+	m.RangeDiffRanges = ranges
+	if ranges == "" {
+		m.LoadingRangeDiff = false
+		m.ShowRangeDiffInput = true
+		m.RangeDiffInput.Focus()
+		return m, nil
+	}
+	m.LoadingRangeDiff = true
+	return m, m.loadRangeDiffCmd(ranges)
+}
+
+func (m Model) updateRangeDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowRangeDiffInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowRangeDiffInput = false
+			if m.RangeDiffRanges == "" {
+				m.Screen = m.RangeDiffReturn
+				if m.Screen == GraphScreen {
+					m = m.updateGraphViewportContent()
+				}
+			}
+			return m, nil
+		case "enter":
+			ranges := strings.TrimSpace(m.RangeDiffInput.Value())
+			if ranges == "" {
+				return m, nil
+			}
+			m.ShowRangeDiffInput = false
+			return m.openRangeDiff(ranges)
+		}
+
+		var cmd tea.Cmd
+		m.RangeDiffInput, cmd = m.RangeDiffInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = m.RangeDiffReturn
+		if m.Screen == GraphScreen {
+			m = m.updateGraphViewportContent()
+		}
+
+	case "up", "k":
+		if m.RangeDiffIdx > 0 {
+			m.RangeDiffIdx--
+		}
+
+	case "down", "j":
+		if m.RangeDiffIdx < len(m.RangeDiffPairs)-1 {
+			m.RangeDiffIdx++
+		}
+
+	case "enter":
+		if len(m.RangeDiffPairs) > 0 {
+			m.Screen = RangeDiffPatchScreen
+			m = m.initRangeDiffViewport()
+		}
+
+	case "e":
+		m.ShowRangeDiffInput = true
+		m.RangeDiffInput.SetValue(m.RangeDiffRanges)
+		m.RangeDiffInput.CursorEnd()
+		m.RangeDiffInput.Focus()
+
+	case "y":
+		if len(m.RangeDiffPairs) > 0 {
+			pair := m.RangeDiffPairs[m.RangeDiffIdx]
+			hash := pair.New.FullHash
+			if pair.Status == "dropped" {
+				hash = pair.Old.FullHash
+			}
+			copyToClipboard(hash)
+			m.AlertMessage = "Hash copied!"
+			return m, clearAlertCmd()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateRangeDiffPatch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = RangeDiffScreen
+		m.ViewportReady = false
+
+	default:
+		var cmd tea.Cmd
+		m.Viewport, cmd = m.Viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) initRangeDiffViewport() Model {
+	headerHeight := 2
+	m.Viewport = viewport.New(m.Width, m.Height-headerHeight)
+	m.Viewport.YPosition = headerHeight
+
+	// The patches are themselves diffs, so the diff lexer colors the inner
+	// +/- lines while the outer markers show how the patch changed
+	pair := m.RangeDiffPairs[m.RangeDiffIdx]
+	content := "Patches are identical"
+	if pair.Status != "equal" {
+		content = utils.RenderDiffLines(pair.Diff, "range.diff", m.Config.SyntaxStyle, m.Config.Theme, m.Config.DiffCollapse, m.Config.DiffContext)
+	}
+
+	m.Viewport.SetContent(content)
+	m.ViewportReady = true
+
+	return m
+}
+
+func (m Model) updateMessage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc", "m":
+		m.Screen = GraphScreen
+		m.ViewportReady = false
+		m = m.updateGraphViewportContent()
+
+	default:
+		var cmd tea.Cmd
+		m.Viewport, cmd = m.Viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) initMessageViewport() Model {
+	headerHeight := 2
+	m.Viewport = viewport.New(m.Width, m.Height-headerHeight)
+	m.Viewport.YPosition = headerHeight
+	m.Viewport.SetContent(screens.RenderCommitMessage(m.Width, m.MessageCommit, m.DateFormat))
+	m.ViewportReady = true
+	return m
+}
+
+// openContributors shows the authors of rng, the current branch by default.
+func (m Model) openContributors(rng string) (tea.Model, tea.Cmd) {
+	m.Screen = ContributorsScreen
+	m.ContributorRange = rng
+	m.Contributors = nil
+	m.ContributorIdx = 0
+	m.ContributorError = ""
+	m.LoadingContributors = true
+	return m, m.loadContributorsCmd(rng)
+}
+
+// rangeTip returns the revision a range ends at: the tip of <base>..<tip>,
+// or the revision itself. An empty tip means HEAD.
+func rangeTip(rng string) string {
+	if _, tip, ok := strings.Cut(rng, ".."); ok {
+		rng = tip
+	}
+	if rng == "" {
+		return "HEAD"
+	}
+	return rng
+}
+
+func (m Model) updateContributors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowContributorInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowContributorInput = false
+			return m, nil
+		case "enter":
+			rng := strings.TrimSpace(m.ContributorInput.Value())
+			m.ShowContributorInput = false
+			if rng == "" {
+				return m, nil
+			}
+			return m.openContributors(rng)
+		}
+
+		var cmd tea.Cmd
+		m.ContributorInput, cmd = m.ContributorInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		if m.ContributorIdx > 0 {
+			m.ContributorIdx--
+		}
+
+	case "down", "j":
+		if m.ContributorIdx < len(m.Contributors)-1 {
+			m.ContributorIdx++
+		}
+
+	case "s":
+		m.ContributorSort = (m.ContributorSort + 1) % (SortByLast + 1)
+		m.Contributors = sortContributors(m.Contributors, m.ContributorSort, m.ContributorReverse)
+		m.ContributorIdx = 0
+
+	case "S":
+		m.ContributorReverse = !m.ContributorReverse
+		m.Contributors = sortContributors(m.Contributors, m.ContributorSort, m.ContributorReverse)
+		m.ContributorIdx = 0
+
+	case "e":
+		m.ShowContributorInput = true
+		m.ContributorInput.SetValue(m.ContributorRange)
+		m.ContributorInput.CursorEnd()
+		m.ContributorInput.Focus()
+
+	case "H":
+		if len(m.Contributors) > 0 {
+			return m.openHeatmap(types.CommitFilter{Author: m.Contributors[m.ContributorIdx].Name})
+		}
+
+	case "enter":
+		// Show only this author's commits in the graph, from the tip of
+		// the range the list was counted over
+		if len(m.Contributors) > 0 {
+			tip := rangeTip(m.ContributorRange)
+			if tip == "HEAD" && m.HeadBranch != "" {
+				tip = m.HeadBranch
+			}
+			m.CurrentBranch = tip
+			m.GraphFilter = types.CommitFilter{Author: m.Contributors[m.ContributorIdx].Name}
+			return m.reloadGraph()
+		}
+	}
+	return m, nil
+}
+
+// sortContributors orders contributors by the chosen column, largest or
+// latest first, or alphabetically for names. reverse flips the order.
+func sortContributors(contributors []types.Contributor, by ContributorSort, reverse bool) []types.Contributor {
+	less := func(a, b types.Contributor) bool {
+		switch by {
+		case SortByLines:
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		case SortByName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case SortByFirst:
+			return a.First.After(b.First)
+		case SortByLast:
+			return a.Last.After(b.Last)
+		default:
+			return a.Commits > b.Commits
+		}
+	}
+	sort.SliceStable(contributors, func(i, j int) bool {
+		if reverse {
+			return less(contributors[j], contributors[i])
+		}
+		return less(contributors[i], contributors[j])
+	})
+	return contributors
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// activeDays lists the days with commits, oldest first.
+func activeDays(activity map[string]int) []string {
+	days := make([]string, 0, len(activity))
+	for day, n := range activity {
+		if n > 0 {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days
+}
+
+// openHeatmap shows commit activity on the current branch.
+func (m Model) openHeatmap(filter types.CommitFilter) (tea.Model, tea.Cmd) {
+	m.Screen = HeatmapScreen
+	m.ActivityFilter = filter
+	m.Activity = nil
+	m.ActivityError = ""
+	m.LoadingActivity = true
+	return m, m.loadActivityCmd(filter)
+}
+
+func (m Model) updateHeatmap(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ActivityInputField != "" {
+		switch msg.String() {
+		case "esc":
+			m.ActivityInputField = ""
+			return m, nil
+		case "enter":
+			value := strings.TrimSpace(m.ActivityInput.Value())
+			filter := m.ActivityFilter
+			if m.ActivityInputField == "author" {
+				filter.Author = value
+			} else {
+				filter.Path = value
+			}
+			m.ActivityInputField = ""
+			return m.openHeatmap(filter)
+		}
+
+		var cmd tea.Cmd
+		m.ActivityInput, cmd = m.ActivityInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		m.ActivityDay = m.ActivityDay.AddDate(0, 0, -1)
+	case "down", "j":
+		m.ActivityDay = m.ActivityDay.AddDate(0, 0, 1)
+	case "left", "h":
+		m.ActivityDay = m.ActivityDay.AddDate(0, 0, -7)
+	case "right", "l":
+		m.ActivityDay = m.ActivityDay.AddDate(0, 0, 7)
+
+	case "n", "N":
+		// Next or previous day with commits
+		current := m.ActivityDay.Format(types.DayLayout)
+		days := activeDays(m.Activity)
+		target := ""
+		if msg.String() == "n" {
+			if i := sort.SearchStrings(days, current); i < len(days) && days[i] == current {
+				if i+1 < len(days) {
+					target = days[i+1]
+				}
+			} else if i < len(days) {
+				target = days[i]
+			}
+		} else if i := sort.SearchStrings(days, current); i > 0 {
+			target = days[i-1]
+		}
+		if target != "" {
+			m.ActivityDay, _ = time.ParseInLocation(types.DayLayout, target, time.Local)
+		}
+
+	case "a", "p":
+		m.ActivityInputField = "author"
+		m.ActivityInput.SetValue(m.ActivityFilter.Author)
+		if msg.String() == "p" {
+			m.ActivityInputField = "path"
+			m.ActivityInput.SetValue(m.ActivityFilter.Path)
+		}
+		m.ActivityInput.CursorEnd()
+		m.ActivityInput.Focus()
+
+	case "x":
+		if !m.ActivityFilter.IsZero() {
+			return m.openHeatmap(types.CommitFilter{})
+		}
+
+	case "enter":
+		// Show the graph from the end of the selected day
+		m.GraphFilter = m.ActivityFilter
+		m.GraphFilter.Until = m.ActivityDay
+		return m.reloadGraph()
+	}
+	return m, nil
+}
+
+// openChurn ranks what changed in window, a date like "3 months ago" or a
+// range.
+func (m Model) openChurn(window string) (tea.Model, tea.Cmd) {
+	m.Screen = ChurnScreen
+	m.ChurnWindow = window
+	m.Churn = nil
+	m.ChurnIdx = 0
+	m.ChurnError = ""
+	m.LoadingChurn = true
+	return m, m.loadChurnCmd(window)
+}
+
+// churnEntries is the list on the active tab, in the chosen order.
+func (m Model) churnEntries() []types.ChurnEntry {
+	var entries []types.ChurnEntry
+	for _, e := range m.Churn {
+		if e.IsDir == m.ChurnDirs {
+			entries = append(entries, e)
+		}
+	}
+	if m.ChurnByLines {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Additions+entries[i].Deletions > entries[j].Additions+entries[j].Deletions
+		})
+	}
+	return entries
+}
+
+func (m Model) updateChurn(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ShowChurnInput {
+		switch msg.String() {
+		case "esc":
+			m.ShowChurnInput = false
+			return m, nil
+		case "enter":
+			window := strings.TrimSpace(m.ChurnInput.Value())
+			m.ShowChurnInput = false
+			if window == "" {
+				return m, nil
+			}
+			return m.openChurn(window)
+		}
+
+		var cmd tea.Cmd
+		m.ChurnInput, cmd = m.ChurnInput.Update(msg)
+		return m, cmd
+	}
+
+	entries := m.churnEntries()
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.Screen = GraphScreen
+		m = m.updateGraphViewportContent()
+
+	case "up", "k":
+		if m.ChurnIdx > 0 {
+			m.ChurnIdx--
+		}
+
+	case "down", "j":
+		if m.ChurnIdx < len(entries)-1 {
+			m.ChurnIdx++
+		}
+
+	case "tab", "left", "h", "right", "l":
+		m.ChurnDirs = !m.ChurnDirs
+		m.ChurnIdx = 0
+
+	case "s":
+		m.ChurnByLines = !m.ChurnByLines
+		m.ChurnIdx = 0
+
+	case "e":
+		m.ShowChurnInput = true
+		m.ChurnInput.SetValue(m.ChurnWindow)
+		m.ChurnInput.CursorEnd()
+		m.ChurnInput.Focus()
+
+	case "enter":
+		// The history of the file or directory
+		if len(entries) > 0 {
+			m.GraphFilter = types.CommitFilter{Path: entries[m.ChurnIdx].Path}
+			return m.reloadGraph()
+		}
+	}
+	return m, nil
+}
@@ -1,11 +1,18 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tomiwa-a/git-radar/internal/ui/screens"
+	"github.com/tomiwa-a/git-radar/utils"
 )
 
 func (m Model) View() string {
+	if m.LoadError != nil && m.GitService == nil {
+		return screens.RenderError(m.Width, m.Height, m.RepoPath, m.LoadError)
+	}
+
 	var baseView string
 
 	switch m.Screen {
@@ -22,7 +29,7 @@ func (m Model) View() string {
 		if m.ShowGraphSearch && m.GraphSearchInput.Value() != "" && len(m.FilteredGraphCommits) == 0 {
 			displayCommits = m.FilteredGraphCommits
 		}
-		baseView = screens.RenderGraphWithLegend(m.Width, m.Height, displayCommits, m.GraphIdx, m.CurrentBranch, m.ShowLegend, viewportContent, isLoading, m.AlertMessage, m.ShowGraphSearch, m.GraphSearchInput.Value())
+		baseView = screens.RenderGraphWithLegend(m.Width, m.Height, displayCommits, m.GraphIdx, m.currentBranchInfo(), m.ShowLegend, viewportContent, isLoading, m.AlertMessage, m.ShowGraphSearch, m.GraphSearchInput.Value(), m.RemoteStatus, m.ConfirmRemoteOp, m.GraphFilter.String(), m.ShowExportInput, m.ExportInput.Value(), m.DateFormat)
 	case CommitDetailScreen:
 		displayFiles := m.SelectedCommit.Files
 		if m.ShowFilter {
@@ -37,24 +44,52 @@ func (m Model) View() string {
 		baseView = screens.RenderDiffs(m.Width, m.SelectedCommit, displayFiles, m.FileIdx, m.Viewport.View(), m.ShowFilter)
 	case DivergenceScreen:
 		data := screens.DivergenceData{
-			TargetBranch:      m.TargetBranch,
-			SourceBranch:      m.SourceBranch,
-			MergeBase:         m.MergeBase,
-			Incoming:          m.Incoming,
-			Outgoing:          m.Outgoing,
-			IncomingIdx:       m.IncomingIdx,
-			OutgoingIdx:       m.OutgoingIdx,
-			ActivePane:        int(m.ActivePane),
-			TotalFiles:        m.TotalFiles,
-			TotalAdditions:    m.TotalAdditions,
-			TotalDeletions:    m.TotalDeletions,
-			ConflictFiles:     nil,
-			LoadingDivergence: m.LoadingDivergence,
-			AlertMessage:      m.AlertMessage,
+			TargetBranch:        m.TargetBranch,
+			SourceBranch:        m.SourceBranch,
+			MergeBase:           m.MergeBase,
+			Incoming:            m.Incoming,
+			Outgoing:            m.Outgoing,
+			IncomingIdx:         m.IncomingIdx,
+			OutgoingIdx:         m.OutgoingIdx,
+			ActivePane:          int(m.ActivePane),
+			TotalFiles:          m.TotalFiles,
+			TotalAdditions:      m.TotalAdditions,
+			TotalDeletions:      m.TotalDeletions,
+			ConflictFiles:       m.ConflictFiles,
+			LoadingDivergence:   m.LoadingDivergence,
+			AlertMessage:        m.AlertMessage,
+			PickedCommits:       m.PickedCommits,
+			CherryPicking:       m.CherryPicking,
+			CherryPickConflicts: m.CherryPickConflicts,
+			CherryPickRemaining: m.CherryPickRemaining,
+			CompareMode:         m.CompareMode,
+			ShowExportInput:     m.ShowExportInput,
+			ExportInput:         m.ExportInput.Value(),
+			DateFormat:          m.DateFormat,
 		}
 		baseView = screens.RenderDivergence(m.Width, m.Height, data)
+	case StashScreen:
+		baseView = screens.RenderStash(m.Width, m.Height, m.Stashes, m.StashIdx, m.LoadingStashes, m.AlertMessage, m.ShowStashInput, m.StashUntracked, m.StashInput.Value(), m.ConfirmStashDrop, m.DateFormat)
+	case ReflogScreen:
+		baseView = screens.RenderReflog(m.Width, m.Height, m.ReflogRefs, m.ReflogRefIdx, m.Reflog, m.ReflogIdx, m.LoadingReflog, m.AlertMessage, m.ShowReflogInput, m.ReflogInput.Value(), m.DateFormat)
+	case TreeScreen:
+		baseView = screens.RenderTree(m.Width, m.Height, m.TreeCommit, m.TreeDir, m.TreeEntries, m.TreeIdx, m.LoadingTree, m.TreeError)
+	case TreeFileScreen:
+		baseView = screens.RenderTreeFile(m.Width, m.TreeCommit, m.TreeFile, m.Viewport.View())
+	case RangeDiffScreen:
+		baseView = screens.RenderRangeDiff(m.Width, m.Height, m.RangeDiffRanges, m.RangeDiffPairs, m.RangeDiffIdx, m.LoadingRangeDiff, m.RangeDiffError, m.AlertMessage, m.ShowRangeDiffInput, m.RangeDiffInput.Value())
+	case RangeDiffPatchScreen:
+		baseView = screens.RenderRangeDiffPatch(m.Width, m.RangeDiffPairs[m.RangeDiffIdx], m.Viewport.View())
+	case MessageScreen:
+		baseView = screens.RenderMessage(m.Width, m.MessageCommit, m.Viewport.View())
+	case ChurnScreen:
+		baseView = screens.RenderChurn(m.Width, m.Height, m.ChurnWindow, m.churnEntries(), m.ChurnIdx, m.ChurnDirs, m.ChurnByLines, m.LoadingChurn, m.ChurnError, m.ShowChurnInput, m.ChurnInput.Value(), m.DateFormat)
+	case HeatmapScreen:
+		baseView = screens.RenderHeatmap(m.Width, m.Height, m.CurrentBranch, m.ActivityFilter.String(), m.Activity, m.ActivityDay, m.LoadingActivity, m.ActivityError, m.ActivityInputField, m.ActivityInput.Value())
+	case ContributorsScreen:
+		baseView = screens.RenderContributors(m.Width, m.Height, m.ContributorRange, m.Contributors, m.ContributorIdx, m.ContributorSort.String(), m.ContributorReverse, m.LoadingContributors, m.ContributorError, m.ShowContributorInput, m.ContributorInput.Value(), m.DateFormat)
 	default:
-		baseView = screens.RenderGraph(m.Width, m.GraphCommits, m.GraphIdx, m.CurrentBranch, m.AlertMessage)
+		baseView = screens.RenderGraph(m.Width, m.GraphCommits, m.GraphIdx, m.currentBranchInfo(), m.AlertMessage, m.DateFormat)
 	}
 
 	if m.ShowBranchModal {
@@ -67,15 +102,22 @@ func (m Model) View() string {
 	if m.ShowCompareModal {
 		localView := m.CompareLocalPane.View()
 		remoteView := m.CompareRemotePane.View()
-		modal := screens.RenderCompareModal(m.Width, m.Height, localView, remoteView, m.CompareFilterInput.Value(), int(m.ActiveComparePane))
+		tagView := m.CompareTagPane.View()
+		modal := screens.RenderCompareModal(m.Width, m.Height, localView, remoteView, tagView, m.CompareFilterInput.Value(), int(m.ActiveComparePane), m.CompareTarget, m.CompareSource, m.CompareSide == SourceSide, m.AlertMessage)
 		return modal
 	}
 
+	if m.LoadError != nil {
+		// Replace the title line so the screen keeps its height
+		_, rest, _ := strings.Cut(baseView, "\n")
+		baseView = screens.RenderErrorBanner(m.Width, m.LoadError, m.LoadRetry != nil) + "\n" + rest
+	}
+
 	return baseView
 }
 
 func overlayModal(base, modal string, width, height int) string {
 	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("#282A36")))
+		lipgloss.WithWhitespaceForeground(utils.Theme.Background))
 }
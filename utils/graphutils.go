@@ -91,27 +91,27 @@ func RenderLegend(width, height int, selectedDotStyle, mergeDotStyle, branchCoun
 
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#BD93F9")).
+		BorderForeground(Theme.Accent).
 		Padding(1, 2)
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F8F8F2")).
+		Foreground(Theme.Text).
 		MarginBottom(1)
 
 	sectionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#BD93F9")).
+		Foreground(Theme.Accent).
 		Bold(true).
 		MarginTop(1)
 
 	itemStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F8F8F2"))
+		Foreground(Theme.Text)
 
 	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4"))
+		Foreground(Theme.Muted)
 
 	hintStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(Theme.Muted).
 		MarginTop(1)
 
 	var content strings.Builder
@@ -132,6 +132,9 @@ func RenderLegend(width, height int, selectedDotStyle, mergeDotStyle, branchCoun
 	content.WriteString("\n" + sectionStyle.Render("INDICATORS") + "\n")
 	content.WriteString(branchCountStyle.Render("  ⚑2") + descStyle.Render("     2 branches at this commit") + "\n")
 	content.WriteString(mainBranchStyle.Render("  ★") + descStyle.Render("      main/master branch") + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(Theme.Added).Bold(true).Render("  ✓") + descStyle.Render("      Valid signature") + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(Theme.Removed).Bold(true).Render("  ✗") + descStyle.Render("      Invalid signature") + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(Theme.Changed).Bold(true).Render("  ?") + descStyle.Render("      Signed with an unknown key") + "\n")
 
 	// Branches section
 	content.WriteString("\n" + sectionStyle.Render("BRANCHES") + "\n")
@@ -153,5 +156,5 @@ func RenderLegend(width, height int, selectedDotStyle, mergeDotStyle, branchCoun
 
 	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, modal,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("#282A36")))
+		lipgloss.WithWhitespaceForeground(Theme.Background))
 }
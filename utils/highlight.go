@@ -10,17 +10,18 @@ import (
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/theme"
 	"github.com/tomiwa-a/git-radar/internal/types"
 )
 
-func HighlightCode(code string, filename string) string {
+func HighlightCode(code string, filename string, styleName string) string {
 	lexer := lexers.Match(filename)
 	if lexer == nil {
 		lexer = lexers.Fallback
 	}
 	lexer = chroma.Coalesce(lexer)
 
-	style := styles.Get("dracula")
+	style := styles.Get(styleName)
 	if style == nil {
 		style = styles.Fallback
 	}
@@ -44,18 +45,20 @@ func HighlightCode(code string, filename string) string {
 	return buf.String()
 }
 
-func RenderCodeWithLineNumbers(code string, filename string, width int) string {
-	highlighted := HighlightCode(code, filename)
+// RenderCodeWithLineNumbers highlights code in the chroma style styleName
+// and numbers its lines in th's colours.
+func RenderCodeWithLineNumbers(code string, filename string, width int, styleName string, th theme.Theme) string {
+	highlighted := HighlightCode(code, filename, styleName)
 	lines := strings.Split(highlighted, "\n")
 
 	lineNumStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(th.Muted).
 		Width(4).
 		Align(lipgloss.Right).
 		MarginRight(1)
 
 	dividerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#44475A"))
+		Foreground(th.Surface)
 
 	var result strings.Builder
 	for i, line := range lines {
@@ -70,29 +73,29 @@ func RenderCodeWithLineNumbers(code string, filename string, width int) string {
 	return result.String()
 }
 
-func RenderDiffLines(diffLines []types.DiffLine, filename string) string {
-	const collapseThreshold = 8
-	const contextLines = 3
-
-	collapsed := collapseDiffLines(diffLines, collapseThreshold, contextLines)
+// RenderDiffLines draws a diff in th's colours, highlighting unchanged lines
+// in the chroma style styleName. Unchanged runs longer than collapse are
+// folded to context lines either side; 0 never folds.
+func RenderDiffLines(diffLines []types.DiffLine, filename string, styleName string, th theme.Theme, collapse, context int) string {
+	collapsed := collapseDiffLines(diffLines, collapse, context)
 
 	addStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#50FA7B"))
+		Foreground(th.Added)
 
 	delStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF5555"))
+		Foreground(th.Removed)
 
 	collapseStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(th.Muted).
 		Italic(true)
 
 	lineNumStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6272A4")).
+		Foreground(th.Muted).
 		Width(4).
 		Align(lipgloss.Right)
 
 	dividerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#44475A"))
+		Foreground(th.Surface)
 
 	var equalCode strings.Builder
 	for _, dl := range collapsed {
@@ -103,7 +106,7 @@ func RenderDiffLines(diffLines []types.DiffLine, filename string) string {
 
 	highlightedEqual := ""
 	if equalCode.Len() > 0 {
-		highlightedEqual = HighlightCode(equalCode.String(), filename)
+		highlightedEqual = HighlightCode(equalCode.String(), filename, styleName)
 	}
 	highlightedEqualLines := strings.Split(highlightedEqual, "\n")
 
@@ -172,7 +175,7 @@ func collapseDiffLines(lines []types.DiffLine, threshold, context int) []types.D
 		runEnd := i
 		runLen := runEnd - runStart
 
-		if runLen > threshold {
+		if threshold > 0 && runLen > threshold {
 			for j := runStart; j < runStart+context && j < runEnd; j++ {
 				result = append(result, lines[j])
 			}
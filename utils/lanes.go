@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"container/heap"
+	"slices"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// GraphEdge joins the commit at row From to its parent at row To, running
+// down Lane in between. To is -1 when the parent is not in the list.
+type GraphEdge struct {
+	From  int
+	To    int
+	Lane  int
+	Merge bool // to a second or later parent
+}
+
+// AssignLanes lays commits out in columns like git log --graph and sets
+// each commit's Lane. Children must come before their parents, as
+// TopoOrder arranges. A commit takes the leftmost lane a child reserved for
+// it, its first parent continues that lane and further parents open the
+// first free one. It returns the edges between rows and the number of lanes
+// used.
+func AssignLanes(commits []types.GraphCommit) ([]GraphEdge, int) {
+	var lanes []string // parent each lane is waiting for, "" when free
+	var edges []GraphEdge
+	waiting := make(map[string][]int) // parent hash to the edges that end there
+
+	free := func() int {
+		if i := slices.Index(lanes, ""); i >= 0 {
+			return i
+		}
+		lanes = append(lanes, "")
+		return len(lanes) - 1
+	}
+
+	width := 0
+	for i := range commits {
+		c := &commits[i]
+		lane := -1
+		for j, hash := range lanes {
+			if hash != c.FullHash {
+				continue
+			}
+			if lane < 0 {
+				lane = j
+			} else {
+				// Another child's line joins here and ends
+				lanes[j] = ""
+			}
+		}
+		if lane < 0 {
+			lane = free()
+		}
+		c.Lane = lane
+		lanes[lane] = ""
+		for _, e := range waiting[c.FullHash] {
+			edges[e].To = i
+		}
+		delete(waiting, c.FullHash)
+
+		for k, parent := range c.Parents {
+			// The first parent carries on in this lane even when another
+			// child is waiting for it too; the parent takes the leftmost one
+			edgeLane := lane
+			if k > 0 {
+				if edgeLane = slices.Index(lanes, parent); edgeLane < 0 {
+					edgeLane = free()
+				}
+			}
+			lanes[edgeLane] = parent
+			waiting[parent] = append(waiting[parent], len(edges))
+			edges = append(edges, GraphEdge{From: i, To: -1, Lane: edgeLane, Merge: k > 0})
+		}
+		width = max(width, len(lanes))
+	}
+	return edges, width
+}
+
+// TopoOrder returns commits reordered so that every child comes before its
+// parents, keeping the given order wherever it already does. Commits made
+// in the same second, as by a rebase, can otherwise be listed out of order.
+func TopoOrder(commits []types.GraphCommit) []types.GraphCommit {
+	index := make(map[string]int, len(commits))
+	for i, c := range commits {
+		index[c.FullHash] = i
+	}
+	children := make([]int, len(commits)) // children not yet emitted
+	for _, c := range commits {
+		for _, p := range c.Parents {
+			if i, ok := index[p]; ok {
+				children[i]++
+			}
+		}
+	}
+
+	// Always emit the earliest listed commit whose children are all out
+	ready := &intHeap{}
+	for i, n := range children {
+		if n == 0 {
+			heap.Push(ready, i)
+		}
+	}
+	ordered := make([]types.GraphCommit, 0, len(commits))
+	for ready.Len() > 0 {
+		c := commits[heap.Pop(ready).(int)]
+		ordered = append(ordered, c)
+		for _, p := range c.Parents {
+			if i, ok := index[p]; ok {
+				if children[i]--; children[i] == 0 {
+					heap.Push(ready, i)
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+type intHeap []int
+
+func (h intHeap) Len() int           { return len(h) }
+func (h intHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h intHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *intHeap) Push(x any)        { *h = append(*h, x.(int)) }
+func (h *intHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tomiwa-a/git-radar/internal/types"
+)
+
+// graph builds commits from "hash:parent,parent" specs, newest first.
+func graph(specs ...string) []types.GraphCommit {
+	commits := make([]types.GraphCommit, len(specs))
+	for i, spec := range specs {
+		hash, parents, _ := strings.Cut(spec, ":")
+		commits[i].FullHash = hash
+		if parents != "" {
+			commits[i].Parents = strings.Split(parents, ",")
+		}
+	}
+	return commits
+}
+
+func TestAssignLanes(t *testing.T) {
+	tests := []struct {
+		name      string
+		commits   []types.GraphCommit
+		wantLanes []int
+		wantEdges []GraphEdge
+		wantWidth int
+	}{
+		{
+			name:      "linear history",
+			commits:   graph("c:b", "b:a", "a"),
+			wantLanes: []int{0, 0, 0},
+			wantEdges: []GraphEdge{{From: 0, To: 1}, {From: 1, To: 2}},
+			wantWidth: 1,
+		},
+		{
+			name:      "merge of a side branch",
+			commits:   graph("m:a,b", "a:base", "b:base", "base"),
+			wantLanes: []int{0, 0, 1, 0},
+			wantEdges: []GraphEdge{
+				{From: 0, To: 1, Lane: 0},
+				{From: 0, To: 2, Lane: 1, Merge: true},
+				{From: 1, To: 3, Lane: 0},
+				{From: 2, To: 3, Lane: 1},
+			},
+			wantWidth: 2,
+		},
+		{
+			name:      "two tips forking from one commit",
+			commits:   graph("feature:base", "main:base", "base"),
+			wantLanes: []int{0, 1, 0},
+			wantEdges: []GraphEdge{{From: 0, To: 2, Lane: 0}, {From: 1, To: 2, Lane: 1}},
+			wantWidth: 2,
+		},
+		{
+			name:      "parent outside the list",
+			commits:   graph("b:a", "a:older"),
+			wantLanes: []int{0, 0},
+			wantEdges: []GraphEdge{{From: 0, To: 1}, {From: 1, To: -1}},
+			wantWidth: 1,
+		},
+		{
+			name:      "freed lane is reused",
+			commits:   graph("x:p", "y", "z", "p"),
+			wantLanes: []int{0, 1, 1, 0},
+			wantEdges: []GraphEdge{{From: 0, To: 3, Lane: 0}},
+			wantWidth: 2,
+		},
+		{
+			name:      "merge parent already waited for",
+			commits:   graph("t:b", "m:a,b", "a:b", "b"),
+			wantLanes: []int{0, 1, 1, 0},
+			wantEdges: []GraphEdge{
+				{From: 0, To: 3, Lane: 0},
+				{From: 1, To: 2, Lane: 1},
+				{From: 1, To: 3, Lane: 0, Merge: true},
+				{From: 2, To: 3, Lane: 1},
+			},
+			wantWidth: 2,
+		},
+		{
+			name:      "octopus merge",
+			commits:   graph("m:a,b,c", "a", "b", "c"),
+			wantLanes: []int{0, 0, 1, 2},
+			wantEdges: []GraphEdge{
+				{From: 0, To: 1, Lane: 0},
+				{From: 0, To: 2, Lane: 1, Merge: true},
+				{From: 0, To: 3, Lane: 2, Merge: true},
+			},
+			wantWidth: 3,
+		},
+		{
+			name:      "empty",
+			wantWidth: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edges, width := AssignLanes(tt.commits)
+			var lanes []int
+			for _, c := range tt.commits {
+				lanes = append(lanes, c.Lane)
+			}
+			if !slices.Equal(lanes, tt.wantLanes) {
+				t.Errorf("lanes = %v, want %v", lanes, tt.wantLanes)
+			}
+			if !slices.Equal(edges, tt.wantEdges) {
+				t.Errorf("edges = %+v, want %+v", edges, tt.wantEdges)
+			}
+			if width != tt.wantWidth {
+				t.Errorf("width = %d, want %d", width, tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestTopoOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []types.GraphCommit
+		want    []string
+	}{
+		{
+			name:    "already in order",
+			commits: graph("c:b", "b:a", "a"),
+			want:    []string{"c", "b", "a"},
+		},
+		{
+			name:    "parent listed before its child",
+			commits: graph("a", "b:a"),
+			want:    []string{"b", "a"},
+		},
+		{
+			name:    "unrelated commits keep their place",
+			commits: graph("x", "y", "c:p", "p"),
+			want:    []string{"x", "y", "c", "p"},
+		},
+		{
+			name:    "parent waits for a later child",
+			commits: graph("p", "x", "c:p"),
+			want:    []string{"x", "c", "p"},
+		},
+		{
+			name:    "merge listed between its parents",
+			commits: graph("a", "m:a,b", "b"),
+			want:    []string{"m", "a", "b"},
+		},
+		{
+			name:    "parents outside the list are ignored",
+			commits: graph("b:a", "c:outside"),
+			want:    []string{"b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range TopoOrder(tt.commits) {
+				got = append(got, c.FullHash)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("TopoOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,85 +1,127 @@
 package utils
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomiwa-a/git-radar/internal/theme"
+)
+
+// Theme is the palette the TUI is drawn in. Change it with SetTheme so the
+// styles below follow.
+var Theme theme.Theme
 
 var (
-	PrimaryColor   = lipgloss.Color("#FF5FAF")
-	SecondaryColor = lipgloss.Color("#585858")
-	IncomingColor  = lipgloss.Color("#00FF87")
-	OutgoingColor  = lipgloss.Color("#87CEFA")
-	CursorColor    = lipgloss.Color("#FF87D7")
-	DimColor       = lipgloss.Color("#626262")
+	PrimaryColor   lipgloss.Color
+	SecondaryColor lipgloss.Color
+	IncomingColor  lipgloss.Color
+	OutgoingColor  lipgloss.Color
+	CursorColor    lipgloss.Color
+	DimColor       lipgloss.Color
+
+	TitleStyle        lipgloss.Style
+	BranchStyle       lipgloss.Style
+	PaneStyle         lipgloss.Style
+	ActivePaneStyle   lipgloss.Style
+	PaneTitleIncoming lipgloss.Style
+	PaneTitleOutgoing lipgloss.Style
+	SelectedItemStyle lipgloss.Style
+	NormalItemStyle   lipgloss.Style
+	HashStyle         lipgloss.Style
+	DetailsStyle      lipgloss.Style
+	DetailsTitleStyle lipgloss.Style
+	DetailsLabelStyle lipgloss.Style
+	FileNameStyle     lipgloss.Style
+	DetailsValueStyle lipgloss.Style
+	FileAddedStyle    lipgloss.Style
+	FileModifiedStyle lipgloss.Style
+	FileDeletedStyle  lipgloss.Style
+	HelpStyle         lipgloss.Style
+)
+
+func init() {
+	SetTheme(theme.Dark)
+}
+
+// SetTheme makes t the active theme and rebuilds the shared styles from it.
+func SetTheme(t theme.Theme) {
+	Theme = t
+
+	PrimaryColor = t.Secondary
+	SecondaryColor = t.Surface
+	IncomingColor = t.Added
+	OutgoingColor = t.Info
+	CursorColor = t.Secondary
+	DimColor = t.Muted
 
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(PrimaryColor).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(t.Background).
+		Background(PrimaryColor).
+		Padding(0, 1)
 
 	BranchStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#5F5FFF")).
-			Padding(0, 1)
+		Foreground(t.Background).
+		Background(t.Accent).
+		Padding(0, 1)
 
 	PaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(SecondaryColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(SecondaryColor).
+		Padding(0, 1)
 
 	ActivePaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(PrimaryColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(PrimaryColor).
+		Padding(0, 1)
 
 	PaneTitleIncoming = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(IncomingColor)
+		Bold(true).
+		Foreground(IncomingColor)
 
 	PaneTitleOutgoing = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(OutgoingColor)
+		Bold(true).
+		Foreground(OutgoingColor)
 
 	SelectedItemStyle = lipgloss.NewStyle().
-				Foreground(CursorColor).
-				Bold(true)
+		Foreground(CursorColor).
+		Bold(true)
 
 	NormalItemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#D0D0D0"))
+		Foreground(t.Text)
 
 	HashStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFAF00"))
+		Foreground(t.Changed)
 
 	DetailsStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(SecondaryColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(SecondaryColor).
+		Padding(0, 1)
 
 	DetailsTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(PrimaryColor)
+		Bold(true).
+		Foreground(PrimaryColor)
 
 	DetailsLabelStyle = lipgloss.NewStyle().
-				Foreground(DimColor)
+		Foreground(DimColor)
 
 	FileNameStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#1E1E1E")).
-			Background(lipgloss.Color("#87CEFA")).
-			Padding(0, 1).Margin(0, 2)
+		Bold(true).
+		Foreground(t.Background).
+		Background(OutgoingColor).
+		Padding(0, 1).Margin(0, 2)
 
 	DetailsValueStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#D0D0D0"))
+		Foreground(t.Text)
 
 	FileAddedStyle = lipgloss.NewStyle().
-			Foreground(IncomingColor)
+		Foreground(IncomingColor)
 
 	FileModifiedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFAF00"))
+		Foreground(t.Changed)
 
 	FileDeletedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FF0000"))
+		Foreground(t.Removed)
 
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(DimColor).
-			Padding(0, 1)
-)
+		Foreground(DimColor).
+		Padding(0, 1)
+}
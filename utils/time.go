@@ -50,3 +50,74 @@ func FormatRelativeTime(t time.Time) string {
 		return fmt.Sprintf("%d years ago", years)
 	}
 }
+
+// DateFormat selects how dates are shown across the UI
+type DateFormat int
+
+const (
+	RelativeDates DateFormat = iota // "3 hours ago"
+	AbsoluteDates                   // like git log: "Mon Jan 2 15:04:05 2006 -0700"
+	ISODates                        // ISO 8601: "2006-01-02T15:04:05-07:00"
+)
+
+// Next cycles relative → absolute → ISO 8601.
+func (f DateFormat) Next() DateFormat {
+	return (f + 1) % 3
+}
+
+func (f DateFormat) String() string {
+	switch f {
+	case AbsoluteDates:
+		return "absolute"
+	case ISODates:
+		return "ISO 8601"
+	default:
+		return "relative"
+	}
+}
+
+// FormatDate renders t in format f, in the timezone it was recorded in.
+func FormatDate(t time.Time, f DateFormat) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch f {
+	case AbsoluteDates:
+		return t.Format("Mon Jan 2 15:04:05 2006 -0700")
+	case ISODates:
+		return t.Format(time.RFC3339)
+	default:
+		return FormatRelativeTime(t)
+	}
+}
+
+// CompactDateWidth is the widest CompactDate output in format f.
+func CompactDateWidth(f DateFormat) int {
+	switch f {
+	case AbsoluteDates:
+		return len("Jan 02 15:04")
+	case ISODates:
+		return len("2006-01-02")
+	default:
+		return len("59mo")
+	}
+}
+
+// CompactDate renders t in a few characters for list columns, e.g. "19m"
+// or "2024-03-01".
+func CompactDate(t time.Time, f DateFormat) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch f {
+	case AbsoluteDates:
+		if t.Year() == time.Now().Year() {
+			return t.Format("Jan 2 15:04")
+		}
+		return t.Format("Jan 2 2006")
+	case ISODates:
+		return t.Format("2006-01-02")
+	default:
+		return CompactTime(FormatRelativeTime(t))
+	}
+}